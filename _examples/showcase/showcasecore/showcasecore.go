@@ -303,24 +303,15 @@ func buildCheckBoxDemo(event gwu.Event) gwu.Comp {
 
 	p.Add(gwu.NewLabel("Check the days you want to work on:"))
 
-	cbs := []gwu.CheckBox{gwu.NewCheckBox("Monday"), gwu.NewCheckBox("Tuesday"), gwu.NewCheckBox("Wednesday"),
-		gwu.NewCheckBox("Thursday"), gwu.NewCheckBox("Friday"), gwu.NewCheckBox("Saturday"), gwu.NewCheckBox("Sunday")}
-	cbs[5].SetEnabled(false)
-	cbs[6].SetEnabled(false)
-
-	for _, cb := range cbs {
-		p.Add(cb)
-		cb.AddEHandlerFunc(func(e gwu.Event) {
-			sum := 0
-			for _, cb2 := range cbs {
-				if cb2.State() {
-					sum++
-				}
-			}
-			suml.SetText(fmt.Sprintf("%d day%s is a total of %d hours a week.", sum, plural(sum), sum*8))
-			e.MarkDirty(suml)
-		}, gwu.ETypeClick)
-	}
+	cbl := gwu.NewCheckBoxList([]string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"})
+	cbl.CheckBoxAt(5).SetEnabled(false)
+	cbl.CheckBoxAt(6).SetEnabled(false)
+	cbl.AddEHandlerFunc(func(e gwu.Event) {
+		sum := len(cbl.SelectedValues())
+		suml.SetText(fmt.Sprintf("%d day%s is a total of %d hours a week.", sum, plural(sum), sum*8))
+		e.MarkDirty(suml)
+	}, gwu.ETypeChange)
+	p.Add(cbl)
 
 	p.Add(suml)
 
@@ -549,7 +540,7 @@ func buildLabelDemo(event gwu.Event) gwu.Comp {
 	b := gwu.NewButton("Change!")
 	b.AddEHandlerFunc(func(e gwu.Event) {
 		for i := 0; i < p.CompsCount(); i++ {
-			if l, ok := p.CompAt(i).(gwu.Label); ok && l != b {
+			if l, ok := p.CompAt(i).(gwu.Label); ok {
 				reversed := []rune(l.Text())
 				for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
 					reversed[i], reversed[j] = reversed[j], reversed[i]
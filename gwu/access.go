@@ -0,0 +1,98 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Access filtering and client address helpers.
+
+package gwu
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// AccessFilterFunc is a function which tells if a request is allowed
+// to access the GUI server.
+type AccessFilterFunc func(r *http.Request) bool
+
+// parseProxies parses trusted proxy addresses, which may be IP addresses
+// or CIDR notation networks.
+func parseProxies(proxies []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, p := range proxies {
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy address: %q", p)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy network: %q", p)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// isTrustedProxy tells if the specified address is one of the trusted proxies.
+func (s *serverImpl) isTrustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range s.trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *serverImpl) ClientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	// Walk the X-Forwarded-For chain backward while the address is a trusted proxy:
+	// the first untrusted address is the client (addresses before it can be spoofed).
+	if len(s.trustedProxies) == 0 || !s.isTrustedProxy(ip) {
+		return ip
+	}
+	var addrs []string
+	for _, h := range r.Header["X-Forwarded-For"] {
+		addrs = append(addrs, strings.Split(h, ",")...)
+	}
+	for i := len(addrs) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(addrs[i])
+		if addr == "" {
+			continue
+		}
+		ip = addr
+		if !s.isTrustedProxy(ip) {
+			break
+		}
+	}
+	return ip
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	s := NewServer("", "")
+	if err := s.SetTrustedProxies("10.0.0.0/8", "192.168.1.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetTrustedProxies("10.0.0.0/99"); err == nil {
+		t.Error("Expected error for invalid network")
+	}
+	s.SetTrustedProxies("10.0.0.0/8", "192.168.1.1")
+
+	cases := []struct {
+		remote, xff, ip string
+	}{
+		{"1.2.3.4:5678", "", "1.2.3.4"},
+		{"1.2.3.4:5678", "5.6.7.8", "1.2.3.4"},               // Not a trusted proxy
+		{"10.1.1.1:5678", "5.6.7.8", "5.6.7.8"},              // Trusted proxy
+		{"10.1.1.1:5678", "9.9.9.9, 5.6.7.8", "5.6.7.8"},     // Spoofed first entry
+		{"10.1.1.1:5678", "5.6.7.8, 192.168.1.1", "5.6.7.8"}, // Chain of trusted proxies
+		{"10.1.1.1:5678", "", "10.1.1.1"},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = c.remote
+		if c.xff != "" {
+			r.Header.Set("X-Forwarded-For", c.xff)
+		}
+		if ip := s.ClientIP(r); ip != c.ip {
+			t.Errorf("[remote: %s, xff: %s] Got: %s, want: %s", c.remote, c.xff, ip, c.ip)
+		}
+	}
+
+	s.SetAccessFilter(func(r *http.Request) bool { return s.ClientIP(r) == "5.6.7.8" })
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Got status: %d, want: %d", rec.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu_test
+
+import (
+	"io/ioutil"
+	"strconv"
+	"testing"
+
+	"github.com/icza/gowut/gwu"
+	"github.com/icza/gowut/gwu/gwutest"
+)
+
+// newBenchWin creates a window with a synthetic component tree of about n components:
+// rows of panels holding labels, buttons, text boxes and check boxes.
+// The returned button marks the returned label dirty when clicked.
+func newBenchWin(n int) (win gwu.Window, b gwu.Button, l gwu.Label) {
+	win = gwu.NewWindow("bench", "Benchmark")
+	for i := 0; i < n/11; i++ {
+		p := gwu.NewHorizontalPanel()
+		p.Style().SetBorder2(1, gwu.BrdStyleSolid, gwu.ClrGray)
+		for j := 0; j < 10; j++ {
+			switch j % 4 {
+			case 0:
+				p.Add(gwu.NewLabel("Label " + strconv.Itoa(i)))
+			case 1:
+				btn := gwu.NewButton("Button")
+				btn.AddEHandlerFunc(func(e gwu.Event) {}, gwu.ETypeClick)
+				p.Add(btn)
+			case 2:
+				p.Add(gwu.NewTextBox("text"))
+			case 3:
+				p.Add(gwu.NewCheckBox("check"))
+			}
+		}
+		win.Add(p)
+	}
+
+	l = gwu.NewLabel("0")
+	b = gwu.NewButton("Increment")
+	b.AddEHandlerFunc(func(e gwu.Event) {
+		n, _ := strconv.Atoi(l.Text())
+		l.SetText(strconv.Itoa(n + 1))
+		e.MarkDirty(l)
+	}, gwu.ETypeClick)
+	win.Add(b)
+	win.Add(l)
+	return
+}
+
+func benchmarkRenderWin(bm *testing.B, n int) {
+	win, _, _ := newBenchWin(n)
+	s := gwu.NewServer("", "localhost:0")
+	w := gwu.NewWriter(ioutil.Discard)
+	bm.ReportAllocs()
+	bm.ResetTimer()
+	for i := 0; i < bm.N; i++ {
+		win.RenderWin(w, s)
+	}
+}
+
+func BenchmarkRenderWin1k(bm *testing.B)  { benchmarkRenderWin(bm, 1000) }
+func BenchmarkRenderWin10k(bm *testing.B) { benchmarkRenderWin(bm, 10000) }
+
+// BenchmarkRenderAttrs benchmarks rendering a component with
+// explicitly set attributes and style attributes.
+func BenchmarkRenderAttrs(bm *testing.B) {
+	b := gwu.NewButton("Button")
+	for _, name := range []string{"title", "lang", "accesskey", "tabindex"} {
+		b.SetAttr(name, "x")
+	}
+	b.Style().SetColor(gwu.ClrRed).SetWidth("100px").SetPadding("2px").SetFontWeight(gwu.FontWeightBold)
+	w := gwu.NewWriter(ioutil.Discard)
+	bm.ReportAllocs()
+	bm.ResetTimer()
+	for i := 0; i < bm.N; i++ {
+		b.Render(w)
+	}
+}
+
+// BenchmarkEventDirtyRender benchmarks dispatching an event,
+// and rendering the components marked dirty by it.
+func BenchmarkEventDirtyRender(bm *testing.B) {
+	win, b, _ := newBenchWin(1000)
+	tr := gwutest.New(bm, win)
+	w := gwu.NewWriter(ioutil.Discard)
+	bm.ReportAllocs()
+	bm.ResetTimer()
+	for i := 0; i < bm.N; i++ {
+		resp := tr.Click(b)
+		for _, id := range resp.Dirty {
+			win.ByID(id).Render(w)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Backend binding helpers.
+
+package gwu
+
+import (
+	"time"
+)
+
+// Binding interface defines a component which keeps a bound component up-to-date
+// with data fetched from a backend (e.g. a REST or gRPC service), standardizing
+// the fetch-render-refresh loop of dashboards.
+//
+// A Binding is a PanelView which holds the bound component, a label
+// displaying the error of the last failed fetch, and a Timer which
+// triggers the refreshes periodically; add the binding to the component tree
+// instead of the bound component. Use BindList(), BindLabel() and BindTable()
+// to create bindings.
+//
+// The data is fetched right away when the binding is created, and then
+// in a scheduled task of the window after each refresh (see Window.Schedule()),
+// so the bound component is masked with a spinner while the data is being fetched
+// (see Event.Mask()). If a fetch fails, the bound component keeps its previous data,
+// and the error is displayed. Fetch functions are called while the session is locked,
+// so they should not block for long.
+//
+// Bindings can't be cloned (see Clone()).
+//
+// Default style classes: "gwu-Binding", "gwu-Binding-Error"
+type Binding interface {
+	// Binding is a PanelView.
+	PanelView
+
+	// Bound returns the bound component.
+	Bound() Comp
+
+	// Err returns the error of the last fetch, nil if it succeeded.
+	Err() error
+
+	// Timer returns the timer which triggers the refreshes,
+	// e.g. to change the refresh interval or to pause refreshing.
+	Timer() Timer
+
+	// Refresh fetches the data after processing the current event
+	// (masking the bound component while fetching), and updates the bound component.
+	// If the binding is not part of a window, the data is fetched right away.
+	Refresh(e Event)
+}
+
+// Binding implementation.
+type bindingImpl struct {
+	panelImpl // panel implementation: Binding is a Panel, but only PanelView's methods are exported.
+
+	bound    Comp         // The bound component
+	errLabel Label        // Label displaying the error of the last fetch
+	timer    Timer        // Timer triggering the refreshes
+	fetch    func() error // Fetches the data and updates the bound component
+	err      error        // Error of the last fetch
+}
+
+// BindList creates a new Binding which sets the values of the list box
+// to the ones returned by fetch, every refreshEvery (0 disables periodic refreshes).
+// Selected values which are still present after a refresh remain selected.
+func BindList(lb ListBox, fetch func() ([]string, error), refreshEvery time.Duration) Binding {
+	return newBinding(lb, func() error {
+		values, err := fetch()
+		if err != nil {
+			return err
+		}
+		selected := make(map[string]bool)
+		for _, v := range lb.SelectedValues() {
+			selected[v] = true
+		}
+		lb.SetValues(values)
+		for i, v := range values {
+			if selected[v] {
+				lb.SetSelected(i, true)
+			}
+		}
+		return nil
+	}, refreshEvery)
+}
+
+// BindLabel creates a new Binding which sets the text of the label
+// to the one returned by fetch, every refreshEvery (0 disables periodic refreshes).
+func BindLabel(l Label, fetch func() (string, error), refreshEvery time.Duration) Binding {
+	return newBinding(l, func() error {
+		text, err := fetch()
+		if err != nil {
+			return err
+		}
+		l.SetText(text)
+		return nil
+	}, refreshEvery)
+}
+
+// BindTable creates a new Binding which replaces the rows of the table after
+// the header rows (see Table.HeaderRows()) with labels holding the rows returned by fetch,
+// every refreshEvery (0 disables periodic refreshes).
+// The table should not have footer rows.
+func BindTable(t Table, fetch func() ([][]string, error), refreshEvery time.Duration) Binding {
+	return newBinding(t, func() error {
+		rows, err := fetch()
+		if err != nil {
+			return err
+		}
+		headers := t.HeaderRows()
+		t.(*tableImpl).truncateRows(headers)
+		for i, row := range rows {
+			for col, text := range row {
+				t.Add(NewLabel(text), headers+i, col)
+			}
+		}
+		return nil
+	}, refreshEvery)
+}
+
+// newBinding creates a new bindingImpl, and fetches the data.
+func newBinding(bound Comp, fetch func() error, refreshEvery time.Duration) *bindingImpl {
+	c := &bindingImpl{panelImpl: newPanelImpl(), bound: bound, fetch: fetch}
+	c.outer = c
+	c.Style().AddClass("gwu-Binding")
+
+	c.errLabel = NewLabel("")
+	c.errLabel.Style().AddClass("gwu-Binding-Error")
+
+	c.timer = NewTimer(refreshEvery)
+	c.timer.SetRepeat(true)
+	c.timer.SetSkipIfPending(true)
+	c.timer.SetKeepAlive(false)
+	c.timer.SetActive(refreshEvery > 0)
+	c.timer.AddEHandler(internalHandler{c.base(), c.Refresh}, ETypeStateChange)
+
+	c.panelImpl.Add(bound)
+	c.panelImpl.Add(c.errLabel)
+	c.panelImpl.Add(c.timer)
+
+	c.load()
+	return c
+}
+
+func (c *bindingImpl) Bound() Comp {
+	return c.bound
+}
+
+func (c *bindingImpl) Err() error {
+	return c.err
+}
+
+func (c *bindingImpl) Timer() Timer {
+	return c.timer
+}
+
+func (c *bindingImpl) Refresh(e Event) {
+	win := windowOf(c)
+	if win == nil {
+		c.load()
+		return
+	}
+
+	e.Mask(c.bound, "")
+	win.Schedule(0, func(e Event) {
+		c.load()
+		// Re-rendering the bound component also removes its mask
+		e.MarkDirty(c.bound, c.errLabel)
+	})
+}
+
+// load fetches the data and updates the error label.
+func (c *bindingImpl) load() {
+	c.err = c.fetch()
+	if c.err != nil {
+		c.errLabel.SetText(c.err.Error())
+	}
+	c.errLabel.SetVisible(c.err != nil)
+}
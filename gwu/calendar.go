@@ -0,0 +1,316 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Calendar component interface and implementation.
+
+package gwu
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CalendarDay describes how a day is displayed in a Calendar.
+type CalendarDay struct {
+	Disabled    bool   // Tells if the day is disabled (can't be selected)
+	Highlighted bool   // Tells if the day is highlighted (e.g. it has events)
+	Class       string // Optional additional style class of the day
+	ToolTip     string // Optional tool tip of the day
+}
+
+// CalendarDayFunc is a function which tells how a day is to be displayed in a Calendar.
+// It is called for each displayed day when the calendar is rendered,
+// and for the day the user clicks on (disabled days can't be selected).
+type CalendarDayFunc func(day time.Time) CalendarDay
+
+// Calendar interface defines a component which displays the days of a month
+// in a grid, allowing the user to select a day and to navigate between months.
+//
+// Days are represented with time.Time values at midnight UTC.
+//
+// You can register ETypeChange event handlers which will be called when the user
+// selects a day, and ETypeStateChange event handlers which will be called when the user
+// navigates to another month. The event source will be the calendar.
+// The calendar marks itself dirty in both cases.
+//
+// Default style classes: "gwu-Calendar", "gwu-Calendar-Nav", "gwu-Calendar-Title",
+// "gwu-Calendar-Weekday", "gwu-Calendar-Day", "gwu-Calendar-Other" (days of the
+// previous and next months), "gwu-Calendar-Today", "gwu-Calendar-Selected",
+// "gwu-Calendar-Highlighted", "gwu-Calendar-Disabled"
+type Calendar interface {
+	// Calendar is a component.
+	Comp
+
+	// Month returns the first day of the displayed month.
+	Month() time.Time
+
+	// SetMonth sets the displayed month.
+	SetMonth(year int, month time.Month)
+
+	// Selected returns the selected day, the zero time if no day is selected.
+	Selected() time.Time
+
+	// SetSelected sets the selected day (only the date part of t is used),
+	// and also displays its month. Pass the zero time to clear the selection.
+	SetSelected(t time.Time)
+
+	// FirstWeekday returns the first day of the week (first column).
+	FirstWeekday() time.Weekday
+
+	// SetFirstWeekday sets the first day of the week (first column).
+	// Default is time.Monday.
+	SetFirstWeekday(weekday time.Weekday)
+
+	// SetDayFunc sets the function which tells how days are to be displayed,
+	// e.g. which days are disabled or highlighted. Pass nil to clear it.
+	SetDayFunc(f CalendarDayFunc)
+
+	// SetNames sets the names of the weekdays (starting with Sunday) and
+	// the months (starting with January) displayed by the calendar, e.g. to localize them.
+	// Passing nil for either leaves the corresponding names unchanged.
+	// Default names are the English 2-letter weekday names and English month names.
+	SetNames(weekdays, months []string)
+}
+
+// Calendar implementation.
+type calendarImpl struct {
+	compImpl // Component implementation
+
+	month    time.Time       // First day of the displayed month
+	selected time.Time       // Selected day, zero if none
+	firstWd  time.Weekday    // First day of the week
+	dayFunc  CalendarDayFunc // Function telling how days are to be displayed
+	weekdays []string        // Names of the weekdays
+	months   []string        // Names of the months
+
+	changed   bool // Tells if the selected day was changed by the last event
+	navigated bool // Tells if the displayed month was changed by the last event
+}
+
+var strCalValJs = []byte("calVal(event,this)") // "calVal(event,this)"
+
+// Values of calendar cells sent to the server when clicked.
+const (
+	calValPrev = "p" // Navigate to the previous month
+	calValNext = "n" // Navigate to the next month
+)
+
+// calDateLayout is the layout of the days sent to the server when clicked.
+const calDateLayout = "2006-01-02"
+
+// NewCalendar creates a new Calendar displaying the current month,
+// no day is selected initially.
+func NewCalendar() Calendar {
+	c := &calendarImpl{compImpl: newCompImpl(strCalValJs), firstWd: time.Monday,
+		weekdays: []string{"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"}}
+	for m := time.January; m <= time.December; m++ {
+		c.months = append(c.months, m.String())
+	}
+	now := time.Now()
+	c.SetMonth(now.Year(), now.Month())
+	c.AddSyncOnETypes(ETypeClick)
+	c.Style().AddClass("gwu-Calendar")
+
+	c.AddEHandler(internalHandler{c.base(), func(e Event) {
+		if c.navigated {
+			c.navigated = false
+			e.MarkDirty(c)
+			if c.handlers[ETypeStateChange] != nil {
+				c.dispatchEvent(e.forkEvent(ETypeStateChange, c))
+			}
+		}
+		if c.changed {
+			c.changed = false
+			e.MarkDirty(c)
+			if c.handlers[ETypeChange] != nil {
+				c.dispatchEvent(e.forkEvent(ETypeChange, c))
+			}
+		}
+	}}, ETypeClick)
+	return c
+}
+
+func (c *calendarImpl) Month() time.Time {
+	return c.month
+}
+
+func (c *calendarImpl) SetMonth(year int, month time.Month) {
+	c.month = time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (c *calendarImpl) Selected() time.Time {
+	return c.selected
+}
+
+func (c *calendarImpl) SetSelected(t time.Time) {
+	if t.IsZero() {
+		c.selected = time.Time{}
+		return
+	}
+	y, m, d := t.Date()
+	c.selected = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	c.SetMonth(y, m)
+}
+
+func (c *calendarImpl) FirstWeekday() time.Weekday {
+	return c.firstWd
+}
+
+func (c *calendarImpl) SetFirstWeekday(weekday time.Weekday) {
+	c.firstWd = weekday
+}
+
+func (c *calendarImpl) SetDayFunc(f CalendarDayFunc) {
+	c.dayFunc = f
+}
+
+func (c *calendarImpl) SetNames(weekdays, months []string) {
+	if weekdays != nil {
+		c.weekdays = weekdays
+	}
+	if months != nil {
+		c.months = months
+	}
+}
+
+// day returns how the specified day is to be displayed.
+func (c *calendarImpl) day(day time.Time) CalendarDay {
+	if c.dayFunc == nil {
+		return CalendarDay{}
+	}
+	return c.dayFunc(day)
+}
+
+func (c *calendarImpl) preprocessEvent(event Event, r *http.Request) {
+	switch value := r.FormValue(paramCompValue); value {
+	case "":
+	case calValPrev:
+		c.month = c.month.AddDate(0, -1, 0)
+		c.navigated = true
+	case calValNext:
+		c.month = c.month.AddDate(0, 1, 0)
+		c.navigated = true
+	default:
+		// Values come from the client, the day must be validated
+		day, err := time.Parse(calDateLayout, value)
+		if err != nil || c.day(day).Disabled {
+			return
+		}
+		if !day.Equal(c.selected) {
+			c.changed = true
+			if y, m, _ := day.Date(); y != c.month.Year() || m != c.month.Month() {
+				c.navigated = true
+			}
+			c.SetSelected(day)
+		}
+	}
+}
+
+var (
+	strCalNavOp   = []byte(`<tr class="gwu-Calendar-Nav"><td data-gwu-cv="p">&lsaquo;</td><td colspan="5" class="gwu-Calendar-Title">`) // `<tr class="gwu-Calendar-Nav"><td data-gwu-cv="p">&lsaquo;</td><td colspan="5" class="gwu-Calendar-Title">`
+	strCalNavCl   = []byte(`</td><td data-gwu-cv="n">&rsaquo;</td></tr>`)                                                               // `</td><td data-gwu-cv="n">&rsaquo;</td></tr>`
+	strCalWeekday = []byte(`<th class="gwu-Calendar-Weekday">`)                                                                         // `<th class="gwu-Calendar-Weekday">`
+	strTitle      = []byte(` title="`)                                                                                                  // ` title="`
+	strTDCl       = []byte("</td>")                                                                                                     // "</td>"
+	strTHCl       = []byte("</th>")                                                                                                     // "</th>"
+	strTRCl       = []byte("</tr>")                                                                                                     // "</tr>"
+)
+
+func (c *calendarImpl) Render(w Writer) {
+	w.Write(strTableOp)
+	c.renderAttrsAndStyle(w)
+	c.renderEHandlers(w)
+	w.Write(strGT)
+
+	// Navigation row with the month title
+	w.Write(strCalNavOp)
+	title := c.month.Month().String()
+	if m := int(c.month.Month()) - 1; m < len(c.months) {
+		title = c.months[m]
+	}
+	w.Writees(title)
+	w.Writes(" ")
+	w.Writev(c.month.Year())
+	w.Write(strCalNavCl)
+
+	// Weekday names
+	w.Write(strTR)
+	for i := 0; i < 7; i++ {
+		wd := (int(c.firstWd) + i) % 7
+		w.Write(strCalWeekday)
+		if wd < len(c.weekdays) {
+			w.Writees(c.weekdays[wd])
+		}
+		w.Write(strTHCl)
+	}
+	w.Write(strTRCl)
+
+	// Always 6 weeks so the height of the calendar does not change
+	y, m, d := time.Now().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	day := c.month.AddDate(0, 0, -((int(c.month.Weekday()) - int(c.firstWd) + 7) % 7))
+	for week := 0; week < 6; week++ {
+		w.Write(strTR)
+		for i := 0; i < 7; i++ {
+			c.renderDay(w, day, today)
+			day = day.AddDate(0, 0, 1)
+		}
+		w.Write(strTRCl)
+	}
+
+	w.Write(strTableCl)
+}
+
+// renderDay renders the cell of a day.
+func (c *calendarImpl) renderDay(w Writer, day, today time.Time) {
+	cd := c.day(day)
+
+	classes := []string{"gwu-Calendar-Day"}
+	if day.Month() != c.month.Month() {
+		classes = append(classes, "gwu-Calendar-Other")
+	}
+	if day.Equal(today) {
+		classes = append(classes, "gwu-Calendar-Today")
+	}
+	if day.Equal(c.selected) {
+		classes = append(classes, "gwu-Calendar-Selected")
+	}
+	if cd.Highlighted {
+		classes = append(classes, "gwu-Calendar-Highlighted")
+	}
+	if cd.Disabled {
+		classes = append(classes, "gwu-Calendar-Disabled")
+	}
+	if cd.Class != "" {
+		classes = append(classes, cd.Class)
+	}
+
+	w.Write(strTDOp)
+	w.Write(strClass)
+	w.Writees(strings.Join(classes, " "))
+	w.Write(strQuote)
+	if !cd.Disabled {
+		w.WriteAttr(attrCalValue, day.Format(calDateLayout))
+	}
+	if cd.ToolTip != "" {
+		w.Write(strTitle)
+		w.Writees(cd.ToolTip)
+		w.Write(strQuote)
+	}
+	w.Write(strGT)
+	w.Writev(day.Day())
+	w.Write(strTDCl)
+}
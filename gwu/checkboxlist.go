@@ -0,0 +1,156 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// CheckBoxList component interface and implementation.
+
+package gwu
+
+import (
+	"strconv"
+)
+
+// CheckBoxList interface defines a PanelView which holds a set of check boxes,
+// one for each value of a string slice.
+// The check boxes are created by the check box list.
+//
+// You can register ETypeChange event handlers which will be called when the user
+// clicks on a check box. The event source will be the check box list.
+// The event will have a parent event whose source will be the clicked check box
+// and will contain the mouse coordinates.
+//
+// Default style class: "gwu-CheckBoxList"
+type CheckBoxList interface {
+	// CheckBoxList is a PanelView.
+	PanelView
+
+	// Values returns the values of the check boxes.
+	Values() []string
+
+	// CheckBoxAt returns the check box at the specified index.
+	// Returns nil if idx<0 or idx>=CompsCount().
+	CheckBoxAt(idx int) CheckBox
+
+	// SelectedValues returns the values of the checked check boxes.
+	SelectedValues() []string
+
+	// SetSelectedValues checks the check boxes of the specified values,
+	// and unchecks the others.
+	SetSelectedValues(values []string)
+
+	// Columns returns the number of columns the check boxes are laid out in.
+	Columns() int
+
+	// SetColumns sets the number of columns the check boxes are laid out in
+	// (filled row by row). cols=1 lays out the check boxes vertically.
+	// Note: this changes the layout of the panel: if cols>1, LayoutNatural
+	// is used with a CSS grid, else LayoutVertical.
+	SetColumns(cols int)
+}
+
+// CheckBoxList implementation.
+type checkBoxListImpl struct {
+	panelImpl // panel implementation: CheckBoxList is a Panel, but only PanelView's methods are exported.
+
+	values []string // Values of the check boxes
+	cols   int      // Number of columns
+}
+
+// NewCheckBoxList creates a new CheckBoxList with check boxes
+// having the specified values as their labels.
+// Default layout strategy is LayoutVertical,
+// no check box is checked initially.
+func NewCheckBoxList(values []string) CheckBoxList {
+	return NewLabeledCheckBoxList(values, values)
+}
+
+// NewLabeledCheckBoxList creates a new CheckBoxList with check boxes
+// having the specified values and labels (labels[i] is the label of values[i]).
+// If there are less labels than values, the values are used as the missing labels.
+// Default layout strategy is LayoutVertical,
+// no check box is checked initially.
+func NewLabeledCheckBoxList(values, labels []string) CheckBoxList {
+	c := &checkBoxListImpl{panelImpl: newPanelImpl(), values: values, cols: 1}
+	c.outer = c
+	c.Style().AddClass("gwu-CheckBoxList")
+
+	for i, value := range values {
+		label := value
+		if i < len(labels) {
+			label = labels[i]
+		}
+		cb := NewCheckBox(label)
+		c.panelImpl.Add(cb)
+
+		cb.AddEHandler(internalHandler{c.base(), func(e Event) {
+			if c.handlers[ETypeChange] != nil {
+				c.dispatchEvent(e.forkEvent(ETypeChange, c))
+			}
+		}}, ETypeClick)
+	}
+
+	return c
+}
+
+func (c *checkBoxListImpl) Values() []string {
+	return c.values
+}
+
+func (c *checkBoxListImpl) CheckBoxAt(idx int) CheckBox {
+	if cb, ok := c.CompAt(idx).(CheckBox); ok {
+		return cb
+	}
+	return nil
+}
+
+func (c *checkBoxListImpl) SelectedValues() (sv []string) {
+	for i, value := range c.values {
+		if cb := c.CheckBoxAt(i); cb != nil && cb.State() {
+			sv = append(sv, value)
+		}
+	}
+	return
+}
+
+func (c *checkBoxListImpl) SetSelectedValues(values []string) {
+	m := make(map[string]bool, len(values))
+	for _, v := range values {
+		m[v] = true
+	}
+	for i, value := range c.values {
+		if cb := c.CheckBoxAt(i); cb != nil {
+			cb.SetState(m[value])
+		}
+	}
+}
+
+func (c *checkBoxListImpl) Columns() int {
+	return c.cols
+}
+
+func (c *checkBoxListImpl) SetColumns(cols int) {
+	if cols < 1 {
+		cols = 1
+	}
+	c.cols = cols
+	if cols > 1 {
+		c.SetLayout(LayoutNatural)
+		c.Style().SetDisplay("inline-grid")
+		c.Style().Set("grid-template-columns", "repeat("+strconv.Itoa(cols)+", auto)")
+	} else {
+		c.SetLayout(LayoutVertical)
+		c.Style().SetDisplay("")
+		c.Style().Set("grid-template-columns", "")
+	}
+}
@@ -0,0 +1,546 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Deep copying (cloning) of component trees.
+
+package gwu
+
+import (
+	"bytes"
+)
+
+// Clone returns a deep copy of the specified component and its descendants.
+// The cloned components get new ids, and their properties, styles and
+// HTML attributes are copied. If handlers is true, event handlers are also
+// copied (by reference, see Window.Clone() for details).
+// The clone has no parent, it can be added to any container.
+//
+// Cloning is supported for the built-in components only; nil is returned
+// if the component tree contains a custom component (implemented outside of the gwu package).
+// Clone must be called while the session is locked (e.g. from event handlers)
+// if the component is part of a window.
+func Clone(c Comp, handlers bool) Comp {
+	return newCloner(handlers).cloneTree(c)
+}
+
+// cloner clones component trees.
+//
+// Clones are created with the constructors of the components (so they get new ids
+// and register their own internal event handlers), and then the state of
+// the original components is copied over.
+type cloner struct {
+	handlers bool                      // Tells if (non-internal) event handlers are to be copied
+	groups   map[RadioGroup]RadioGroup // Cloned radio groups mapped from the original groups
+	comps    map[Comp]Comp             // Cloned components mapped from the original components
+	refs     []func()                  // Functions resolving references between cloned components
+}
+
+// newCloner creates a new cloner.
+func newCloner(handlers bool) *cloner {
+	return &cloner{handlers: handlers, groups: make(map[RadioGroup]RadioGroup),
+		comps: make(map[Comp]Comp)}
+}
+
+// cloneTree returns a deep copy of the specified component tree,
+// with references between components of the tree (e.g. the submit button
+// of a panel) pointing to the cloned components. See clone().
+func (cl *cloner) cloneTree(c Comp) Comp {
+	c2 := cl.clone(c)
+	if c2 != nil {
+		for _, resolve := range cl.refs {
+			resolve()
+		}
+	}
+	return c2
+}
+
+// clone returns a deep copy of the specified component.
+// nil is returned if the component or any of its descendants
+// is of a type that does not support cloning (e.g. custom components
+// implemented outside of the gwu package).
+func (cl *cloner) clone(c Comp) Comp {
+	c2 := cl.cloneComp(c)
+	if c2 != nil {
+		cl.comps[c] = c2
+	}
+	return c2
+}
+
+// cloneComp returns a deep copy of the specified component, see clone().
+func (cl *cloner) cloneComp(c Comp) Comp {
+	switch src := c.(type) {
+	case *windowImpl:
+		dst := NewWindow(src.name, src.text).(*windowImpl)
+		if !cl.clonePanel(&dst.panelImpl, &src.panelImpl) {
+			return nil
+		}
+		dst.heads = append([]string(nil), src.heads...)
+		dst.theme = src.theme
+		dst.unlisted = src.unlisted
+		dst.noDispose = src.noDispose
+		if cl.handlers {
+			dst.fallback = src.fallback
+		}
+		dst.pageCache = src.pageCache
+		dst.SetHeaders(src.headers)
+		return dst
+	case *panelImpl:
+		dst := NewPanel().(*panelImpl)
+		if !cl.clonePanel(dst, src) {
+			return nil
+		}
+		return dst
+	case *tableImpl:
+		return cl.cloneTable(src)
+	case *tabPanelImpl:
+		return cl.cloneTabPanel(src)
+	case *radioPanelImpl:
+		return cl.cloneRadioPanel(src)
+	case *checkBoxListImpl:
+		return cl.cloneCheckBoxList(src)
+	case *transferListImpl:
+		return cl.cloneTransferList(src)
+	case *repeaterImpl:
+		return cl.cloneRepeater(src)
+	case *virtualListImpl:
+		// Rows are not cloned, they are created by the row function
+		dst := NewVirtualList(src.count, src.rowFunc).(*virtualListImpl)
+		dst.rowHeight, dst.visibleRows = src.rowHeight, src.visibleRows
+		dst.Refresh()
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *expanderImpl:
+		return cl.cloneExpander(src)
+	case *linkImpl:
+		var dst *linkImpl
+		if src.downloadFunc != nil {
+			dst = NewDownloadLink(src.text, src.Download(), src.mimeType, src.downloadFunc).(*linkImpl)
+		} else {
+			dst = NewLink(src.text, src.url).(*linkImpl)
+		}
+		if src.comp != nil {
+			comp := cl.clone(src.comp)
+			if comp == nil {
+				return nil
+			}
+			dst.SetComp(comp)
+		}
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *labelImpl:
+		dst := NewLabel(src.text).(*labelImpl)
+		dst.markup, dst.multiline = src.markup, src.multiline
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *htmlImpl:
+		dst := NewHTML(src.html).(*htmlImpl)
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *imageImpl:
+		dst := NewImage(src.text, src.url).(*imageImpl)
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *buttonImpl:
+		dst := NewButton(src.text).(*buttonImpl)
+		dst.hasEnabledImpl = src.hasEnabledImpl
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *stateButtonImpl:
+		return cl.cloneStateButton(src)
+	case *switchButtonImpl:
+		dst := NewSwitchButton().(*switchButtonImpl)
+		dst.SetOnOff(src.On(), src.Off())
+		dst.SetToggleOnly(src.toggleOnly)
+		dst.SetState(src.state)
+		dst.SetEnabled(src.Enabled())
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *textBoxImpl:
+		var dst *textBoxImpl
+		if src.isPassw {
+			dst = NewPasswBox(src.text).(*textBoxImpl)
+		} else {
+			dst = NewTextBox(src.text).(*textBoxImpl)
+		}
+		dst.hasEnabledImpl = src.hasEnabledImpl
+		dst.rows, dst.cols = src.rows, src.cols
+		dst.counter, dst.counterFmt = src.counter, src.counterFmt
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *calendarImpl:
+		dst := NewCalendar().(*calendarImpl)
+		dst.month, dst.selected, dst.firstWd = src.month, src.selected, src.firstWd
+		dst.dayFunc = src.dayFunc
+		dst.weekdays, dst.months = src.weekdays, src.months
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *timeBoxImpl:
+		dst := NewTimeBox().(*timeBoxImpl)
+		dst.text, dst.hasEnabledImpl = src.text, src.hasEnabledImpl
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *durationBoxImpl:
+		dst := NewDurationBox(0).(*durationBoxImpl)
+		dst.text, dst.valid, dst.hasEnabledImpl = src.text, src.valid, src.hasEnabledImpl
+		dst.rows, dst.cols = src.rows, src.cols
+		dst.counter, dst.counterFmt = src.counter, src.counterFmt
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *filterBoxImpl:
+		dst := NewFilterBox(nil).(*filterBoxImpl)
+		dst.text, dst.hasEnabledImpl = src.text, src.hasEnabledImpl
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		if cl.handlers && src.filterFunc != nil {
+			dst.SetFilterFunc(src.filterFunc)
+		}
+		dst.lastFilter = src.lastFilter
+		cl.refs = append(cl.refs, func() {
+			target := src.target
+			if t2, ok := cl.comps[target]; ok {
+				target = t2
+			}
+			dst.SetTarget(target)
+		})
+		return dst
+	case *idleMonitorImpl:
+		dst := NewIdleMonitor(src.timeout).(*idleMonitorImpl)
+		dst.warning, dst.warnText, dst.active = src.warning, src.warnText, src.active
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *twoFactorBoxImpl:
+		dst := NewTwoFactorBox().(*twoFactorBoxImpl)
+		dst.code, dst.hasEnabledImpl = src.code, src.hasEnabledImpl
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *listBoxImpl:
+		dst := NewListBox(append([]string(nil), src.values...)).(*listBoxImpl)
+		dst.hasEnabledImpl = src.hasEnabledImpl
+		dst.multi, dst.rows = src.multi, src.rows
+		dst.selected = append([]bool(nil), src.selected...)
+		if src.disabled != nil {
+			dst.disabled = append([]bool(nil), src.disabled...)
+		}
+		dst.groups = append([]lbGrp(nil), src.groups...)
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *sessMonitorImpl:
+		dst := NewSessMonitor().(*sessMonitorImpl)
+		copyTimer(&dst.timerImpl, &src.timerImpl)
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *timerImpl:
+		dst := NewTimer(src.timeout).(*timerImpl)
+		copyTimer(dst, src)
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	case *valueCompImpl:
+		dst := &valueCompImpl{compImpl: newCompImpl(src.valueProviderJs), value: src.value, onValue: src.onValue}
+		cl.copyComp(&dst.compImpl, &src.compImpl)
+		return dst
+	}
+
+	return nil
+}
+
+// copyComp copies the general component properties (HTML attributes, style,
+// visibility, value synchronization and event handlers) from src to dst.
+// dst keeps its own id and internal event handlers.
+func (cl *cloner) copyComp(dst, src *compImpl) {
+	id := dst.attrs["id"]
+	dst.attrs = make(map[string]string, len(src.attrs))
+	for name, value := range src.attrs {
+		dst.attrs[name] = value
+	}
+	dst.attrs["id"] = id
+
+	dst.styleImpl = src.styleImpl.clone()
+	dst.hidden, dst.visibleIf = src.hidden, src.visibleIf
+
+	if cl.handlers {
+		for etype, handlers := range src.handlers {
+			for _, handler := range handlers {
+				switch handler.(type) {
+				case internalHandler, emptyEventHandler:
+					// Internal handlers are registered by the clone itself,
+					// empty handlers are added by AddSyncOnETypes() below.
+				default:
+					dst.AddEHandler(handler, etype)
+				}
+			}
+		}
+	}
+	for etype := range src.syncOnETypes {
+		dst.AddSyncOnETypes(etype)
+	}
+
+	for etype, window := range src.singleFires {
+		dst.SetSingleFire(etype, window)
+	}
+	dst.longPress, dst.dblClick = src.longPress, src.dblClick
+	dst.rateLimitN, dst.rateLimitPer = src.rateLimitN, src.rateLimitPer
+	dst.jsURLs = append([]string(nil), src.jsURLs...)
+	dst.inlineJS = append([]string(nil), src.inlineJS...)
+}
+
+// clonePanel clones the child components of src, adds them to dst
+// and copies the properties of src to dst.
+// Returns false if a child component could not be cloned.
+func (cl *cloner) clonePanel(dst, src *panelImpl) bool {
+	for _, c := range src.comps {
+		c2 := cl.clone(c)
+		if c2 == nil {
+			return false
+		}
+		dst.Add(c2)
+	}
+
+	cl.copyPanel(dst, src)
+	return true
+}
+
+// cloneRef returns the clone of a button referred to by a component.
+// If the button is not part of the cloned tree, the button itself is returned.
+func (cl *cloner) cloneRef(b Button) Button {
+	if b == nil {
+		return nil
+	}
+	if b2, ok := cl.comps[b].(Button); ok {
+		return b2
+	}
+	return b
+}
+
+// copyPanel copies the properties of src to dst, including the
+// cell formatters. Child components must already be added to dst.
+func (cl *cloner) copyPanel(dst, src *panelImpl) {
+	dst.layout = src.layout
+	dst.uniformCellWidth = src.uniformCellWidth
+	dst.masked, dst.maskLabel = src.masked, src.maskLabel
+	dst.focusTrap = src.focusTrap
+	dst.resizable, dst.collapsible = src.resizable, src.collapsible
+	dst.collapsed, dst.title = src.collapsed, src.title
+	cl.refs = append(cl.refs, func() {
+		dst.submitBtn, dst.cancelBtn = cl.cloneRef(src.submitBtn), cl.cloneRef(src.cancelBtn)
+	})
+	dst.hasHVAlignImpl = src.hasHVAlignImpl
+
+	dst.cellFmts = nil
+	for i, c := range src.comps {
+		if cf := src.cellFmts[c.ID()]; cf != nil {
+			if dst.cellFmts == nil {
+				dst.cellFmts = make(map[ID]*cellFmtImpl)
+			}
+			dst.cellFmts[dst.comps[i].ID()] = cf.clone()
+		}
+	}
+
+	cl.copyComp(&dst.compImpl, &src.compImpl)
+}
+
+// cloneTable clones a Table.
+func (cl *cloner) cloneTable(src *tableImpl) Comp {
+	dst := NewTable().(*tableImpl)
+
+	for row, rowComps := range src.comps {
+		dst.EnsureCols(row, len(rowComps))
+		for col, c := range rowComps {
+			if c == nil {
+				continue
+			}
+			c2 := cl.clone(c)
+			if c2 == nil {
+				return nil
+			}
+			dst.Add(c2, row, col)
+		}
+	}
+
+	dst.headerRows, dst.footerRows = src.headerRows, src.footerRows
+	dst.rowFmts = cloneCellFmts(src.rowFmts)
+	dst.colFmts = cloneCellFmts(src.colFmts)
+	if src.cellFmts != nil {
+		dst.cellFmts = make(map[cellIdx]*cellFmtImpl, len(src.cellFmts))
+		for idx, cf := range src.cellFmts {
+			dst.cellFmts[idx] = cf.clone()
+		}
+	}
+
+	dst.hasHVAlignImpl = src.hasHVAlignImpl
+	cl.copyComp(&dst.compImpl, &src.compImpl)
+	return dst
+}
+
+// cloneTabPanel clones a TabPanel.
+func (cl *cloner) cloneTabPanel(src *tabPanelImpl) Comp {
+	dst := NewTabPanel().(*tabPanelImpl)
+
+	for i, content := range src.comps {
+		tab2, content2 := cl.clone(src.tabBarImpl.comps[i]), cl.clone(content)
+		if tab2 == nil || content2 == nil {
+			return nil
+		}
+		dst.Add(tab2, content2)
+	}
+
+	dst.SetTabBarPlacement(src.tabBarPlacement)
+	dst.tabBarFmt = src.tabBarFmt.clone()
+	dst.selected, dst.prevSelected = src.selected, src.prevSelected
+
+	cl.copyPanel(&dst.tabBarImpl.panelImpl, &src.tabBarImpl.panelImpl)
+	cl.copyPanel(&dst.panelImpl, &src.panelImpl)
+	return dst
+}
+
+// cloneRadioPanel clones a RadioPanel.
+func (cl *cloner) cloneRadioPanel(src *radioPanelImpl) Comp {
+	labels := make([]string, len(src.comps))
+	for i := range labels {
+		labels[i] = src.ButtonAt(i).Text()
+	}
+
+	dst := NewRadioPanel(src.group.Name(), labels).(*radioPanelImpl)
+
+	for i, c := range src.comps {
+		srcBtn, dstBtn := c.(*stateButtonImpl), dst.comps[i].(*stateButtonImpl)
+		dstBtn.hasEnabledImpl = srcBtn.hasEnabledImpl
+		cl.copyComp(&dstBtn.compImpl, &srcBtn.compImpl)
+	}
+	dst.SetSelectedIdx(src.SelectedIdx())
+	dst.lastIdx = src.lastIdx
+
+	cl.copyPanel(&dst.panelImpl, &src.panelImpl)
+	return dst
+}
+
+// cloneCheckBoxList clones a CheckBoxList.
+func (cl *cloner) cloneCheckBoxList(src *checkBoxListImpl) Comp {
+	labels := make([]string, len(src.comps))
+	for i := range labels {
+		labels[i] = src.CheckBoxAt(i).Text()
+	}
+
+	dst := NewLabeledCheckBoxList(append([]string(nil), src.values...), labels).(*checkBoxListImpl)
+
+	for i, c := range src.comps {
+		srcCb, dstCb := c.(*stateButtonImpl), dst.comps[i].(*stateButtonImpl)
+		dstCb.hasEnabledImpl = srcCb.hasEnabledImpl
+		dstCb.SetState(srcCb.state)
+		cl.copyComp(&dstCb.compImpl, &srcCb.compImpl)
+	}
+	dst.cols = src.cols
+
+	cl.copyPanel(&dst.panelImpl, &src.panelImpl)
+	return dst
+}
+
+// cloneRepeater clones a Repeater.
+func (cl *cloner) cloneRepeater(src *repeaterImpl) Comp {
+	proto := cl.clone(src.proto)
+	if proto == nil {
+		return nil
+	}
+	dst := NewRepeater(proto, src.bind).(*repeaterImpl)
+	if !cl.clonePanel(&dst.panelImpl, &src.panelImpl) {
+		return nil
+	}
+	return dst
+}
+
+// cloneTransferList clones a TransferList.
+func (cl *cloner) cloneTransferList(src *transferListImpl) Comp {
+	dst := NewTransferList(append([]string(nil), src.values...)).(*transferListImpl)
+	copy(dst.chosen, src.chosen)
+	dst.refresh()
+
+	for i, srcLb := range []ListBox{src.availBox, src.chosenBox} {
+		s, d := srcLb.(*listBoxImpl), []ListBox{dst.availBox, dst.chosenBox}[i].(*listBoxImpl)
+		d.hasEnabledImpl = s.hasEnabledImpl
+		d.rows = s.rows
+		copy(d.selected, s.selected)
+		cl.copyComp(&d.compImpl, &s.compImpl)
+	}
+
+	cl.copyPanel(&dst.panelImpl, &src.panelImpl)
+	return dst
+}
+
+// cloneExpander clones an Expander.
+func (cl *cloner) cloneExpander(src *expanderImpl) Comp {
+	dst := NewExpander().(*expanderImpl)
+
+	if src.header != nil {
+		header := cl.clone(src.header)
+		if header == nil {
+			return nil
+		}
+		dst.SetHeader(header)
+	}
+	if src.content != nil {
+		content := cl.clone(src.content)
+		if content == nil {
+			return nil
+		}
+		dst.SetContent(content)
+	}
+
+	dst.expanded = src.expanded
+	dst.headerFmt, dst.contentFmt = src.headerFmt.clone(), src.contentFmt.clone()
+
+	dst.hasHVAlignImpl = src.hasHVAlignImpl
+	cl.copyComp(&dst.compImpl, &src.compImpl)
+	return dst
+}
+
+// cloneStateButton clones a CheckBox or a RadioButton.
+// Cloned radio buttons are added to the clone of their original group.
+func (cl *cloner) cloneStateButton(src *stateButtonImpl) Comp {
+	var dst *stateButtonImpl
+	if bytes.Equal(src.inputType, strRadio) {
+		var group RadioGroup
+		if src.group != nil {
+			if group = cl.groups[src.group]; group == nil {
+				group = NewRadioGroup(src.group.Name())
+				cl.groups[src.group] = group
+			}
+		}
+		dst = NewRadioButton(src.text, group).(*stateButtonImpl)
+	} else {
+		dst = NewCheckBox(src.text).(*stateButtonImpl)
+	}
+
+	dst.hasEnabledImpl = src.hasEnabledImpl
+	dst.SetState(src.state)
+	cl.copyComp(&dst.compImpl, &src.compImpl)
+	return dst
+}
+
+// copyTimer copies the timer properties from src to dst.
+func copyTimer(dst, src *timerImpl) {
+	dst.timeout, dst.repeat, dst.active, dst.reset = src.timeout, src.repeat, src.active, src.reset
+	dst.skipIfPending, dst.maxTicks, dst.noKeepAlive = src.skipIfPending, src.maxTicks, src.noKeepAlive
+}
+
+// cloneCellFmts returns a deep copy of the specified cell formatter map.
+func cloneCellFmts(fmts map[int]*cellFmtImpl) map[int]*cellFmtImpl {
+	if fmts == nil {
+		return nil
+	}
+
+	fmts2 := make(map[int]*cellFmtImpl, len(fmts))
+	for i, cf := range fmts {
+		fmts2[i] = cf.clone()
+	}
+	return fmts2
+}
@@ -20,7 +20,10 @@ package gwu
 import (
 	"html"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Container interface defines a component that can contain other components.
@@ -45,14 +48,25 @@ type Container interface {
 
 	// Clear clears the container, removes all child components.
 	Clear()
+
+	// SetEnabledRecursive sets the enabled property of all descendant components
+	// implementing HasEnabled, e.g. to disable a form while it is being submitted,
+	// or to display a panel in read-only mode.
+	// Previous enabled states are not remembered: enabling enables all descendants.
+	SetEnabledRecursive(enabled bool)
 }
 
 // Comp interface: the base of all UI components.
 type Comp interface {
-	// ID returns the unique id of the component
+	// ID returns the id of the component.
+	// IDs are unique within a window: they are allocated by the window
+	// when the component is added to it (directly or by adding it to a container
+	// of the window), so the ID of a component changes when it is added to a window,
+	// or moved to another window or to a container not in a window.
+	// Components not in a window have unique, negative provisional IDs.
 	ID() ID
 
-	// Equals tells if this component is equal to the specified another component.
+	// Equals tells if this component is the same as the specified another component.
 	Equals(c2 Comp) bool
 
 	// Parent returns the component's parent container.
@@ -67,6 +81,15 @@ type Comp interface {
 	// and was removed successfully.
 	makeOrphan() bool
 
+	// onRemoved is called by the parent container when the component is removed from it.
+	// It clears the parent, and removes the internal event handlers registered by the parent.
+	onRemoved(parent Container)
+
+	// dispose clears the references of the component to other components
+	// and event handlers (see Window.Dispose()).
+	// Containers also clear their child components (but do not dispose them).
+	dispose()
+
 	// Attr returns the explicitly set value of the specified HTML attribute.
 	Attr(name string) string
 
@@ -88,18 +111,126 @@ type Comp interface {
 	// SetToolTip sets the tool tip of the component.
 	SetToolTip(toolTip string)
 
+	// Data returns the value of the custom data attribute
+	// with the specified key (set by SetData()).
+	Data(key string) string
+
+	// SetData sets the value of the custom data attribute with the specified key,
+	// rendered as the "data-key" HTML attribute. Keys are case-insensitive.
+	// Pass an empty string value to delete the data attribute.
+	//
+	// Data attributes are sent back to the server with the events originating
+	// from the component, and are available via Event.Data(). This can be used
+	// for example to attach record ids to components rendered in loops.
+	// Keys starting with "gwu-" are reserved for internal use.
+	SetData(key, value string)
+
+	// AddJS adds an external JavaScript file (identified by its URL)
+	// required by the component. The script is loaded into the head of
+	// the window when the component is first rendered. Scripts are loaded
+	// only once per page even if multiple components require them.
+	AddJS(url string)
+
+	// AddInlineJS adds an inline JavaScript initialization code to the component.
+	// The code is executed each time the component is rendered (including partial
+	// re-renders), after all the scripts added by AddJS() are loaded.
+	// Inside the code this refers to the HTML element of the component.
+	AddInlineJS(code string)
+
 	// Style returns the Style builder of the component.
 	Style() Style
 
+	// Visible tells if the component is visible.
+	// Components are visible by default.
+	Visible() bool
+
+	// SetVisible sets the visibility of the component.
+	// An invisible component remains part of the component tree,
+	// it is rendered with "display:none" so it can be shown again
+	// by calling SetVisible(true) (and marking it dirty).
+	SetVisible(visible bool)
+
+	// SetVisibleIf sets a predicate which is evaluated each time the component
+	// is rendered, with the session of the client it is rendered for.
+	// If the predicate returns false, the component is rendered hidden
+	// (just like an invisible component), and events of the component
+	// and its descendants are refused for that session.
+	// The session may be nil if the component is rendered outside of a client request
+	// (e.g. by Window.RenderToString()).
+	// Pass nil to remove the predicate.
+	//
+	// Example: only show an admin button to administrators:
+	//     b.SetVisibleIf(func(sess gwu.Session) bool {
+	//         return sess != nil && sess.Attr("admin") == true
+	//     })
+	SetVisibleIf(f func(sess Session) bool)
+
 	// DescendantOf tells if this component is a descendant of the specified another component.
 	DescendantOf(c2 Comp) bool
 
+	// Name returns the name of the component set by SetName().
+	Name() string
+
+	// SetName sets the name of the component, rendered as the "data-gwu-name"
+	// HTML attribute. Unlike IDs, names do not change between runs, so they
+	// can be used as stable selectors in end-to-end tests, e.g. [data-gwu-name="login"].
+	// Components can be looked up by name with Window.ByName().
+	// Pass an empty string to clear the name.
+	//
+	// The name of a window is its window name (which is not rendered).
+	SetName(name string)
+
+	// Path returns the path of the component in the component tree, e.g.
+	// "main/panel[2]/button[0]": the name of the window, followed by the type
+	// names of the components and their indices in their parents.
+	// Unlike IDs, paths do not depend on the order components are created in,
+	// so they can be used to correlate log entries, or as selectors in
+	// end-to-end tests (see Server.SetRenderPaths()).
+	//
+	// The path of a component not added to a window starts at its topmost parent.
+	// An empty string is returned if the component has no parent.
+	Path() string
+
 	// AddEHandler adds a new event handler.
 	AddEHandler(handler EventHandler, etypes ...EventType)
 
 	// AddEHandlerFunc adds a new event handler generated from a handler function.
 	AddEHandlerFunc(hf func(e Event), etypes ...EventType)
 
+	// AddEHandlerFuncIf adds a new event handler generated from a handler function,
+	// guarded by a client-side predicate: a JavaScript expression which is evaluated
+	// in the browser when the event occurs, and the event is only sent to the server
+	// if it evaluates to true. This can drastically reduce the number of
+	// high-frequency events sent (e.g. ETypeKeyUp, ETypeMouseMove).
+	// The predicate may refer to the event as event and to the HTML element
+	// as this, for example:
+	//
+	//     tb.AddEHandlerFuncIf("event.key=='Enter'", search, gwu.ETypeKeyUp)
+	//
+	// The predicates of handlers of the same event type are combined: the event
+	// is sent if any of them evaluates to true, and it is always sent if a handler
+	// without predicate is also added for the event type. All handlers of the
+	// event type are called when the event is sent.
+	AddEHandlerFuncIf(js string, hf func(e Event), etypes ...EventType)
+
+	// AddKeyHandler adds a new event handler generated from a handler function
+	// which is only called when the specified key is pressed with exactly the
+	// specified modifier keys (0 means no modifier keys), e.g. for keyboard shortcuts:
+	//
+	//     panel.AddKeyHandler(gwu.KeyEnter, gwu.ModKeyCtrl, submit)
+	//
+	// The key combination is checked at the client side (see AddEHandlerFuncIf()),
+	// so other keys do not produce server round trips. The handler is registered
+	// for ETypeKeyDown events, auto-repeated key presses are not sent.
+	AddKeyHandler(key Key, mods ModKey, hf func(e Event))
+
+	// AddEHandlerErr adds a new event handler generated from a handler function
+	// which may return an error.
+	// A non-nil error returned by the handler function is logged, and presented
+	// to the user by the error presenter of the server (see Server.SetErrorPresenter()),
+	// which by default shows the error message in an error toast (see Event.ShowToast()).
+	AddEHandlerErr(hf func(e Event) error, etypes ...EventType)
+
 	// HandlersCount returns the number of added handlers.
 	HandlersCount(etype EventType) int
 
@@ -111,6 +242,76 @@ type Comp interface {
 	// component value from browser to the server.
 	AddSyncOnETypes(etypes ...EventType)
 
+	// SingleFire returns the single fire window of the specified event type.
+	// 0 is returned if single fire is not set for the event type.
+	SingleFire(etype EventType) time.Duration
+
+	// SetSingleFire sets single fire mode for the specified event type
+	// to protect against double submits (e.g. fast double-clicks on a Button).
+	//
+	// In single fire mode the component does not send new events of the specified type
+	// at the client side until the response of the previous one arrives,
+	// and events of the specified type arriving within the window duration
+	// after the last dispatched one are discarded at the server side.
+	//
+	// Pass 0 to turn off single fire mode for the event type.
+	SetSingleFire(etype EventType, window time.Duration)
+
+	// EventRateLimit returns the event rate limit of the component
+	// set by SetEventRateLimit(). n is 0 if no limit is set.
+	EventRateLimit() (n int, per time.Duration)
+
+	// SetEventRateLimit sets a rate limit for the events of the component:
+	// at most n events are dispatched per the specified duration, per session
+	// and per client IP. This overrides the rate limit of the server
+	// (see Server.SetEventRateLimit()) for events of the component,
+	// and is useful to protect expensive event handlers (e.g. the login button
+	// of a password form).
+	//
+	// Pass 0 for n to remove the limit.
+	SetEventRateLimit(n int, per time.Duration)
+
+	// LongPressDuration returns the hold duration after which
+	// an ETypeLongPress event is generated.
+	LongPressDuration() time.Duration
+
+	// SetLongPressDuration sets the hold duration after which
+	// an ETypeLongPress event is generated (at the client side)
+	// if the mouse button or the finger (touch) is held down on the component
+	// without moving. The default is 500 ms.
+	// Long press events are only generated if the component has
+	// an ETypeLongPress handler. The click event following a long press is suppressed.
+	SetLongPressDuration(d time.Duration)
+
+	// DblClickInterval returns the maximum interval between 2 clicks
+	// to be detected as a double click. 0 means the browser's native detection is used.
+	DblClickInterval() time.Duration
+
+	// SetDblClickInterval sets the maximum interval between 2 clicks
+	// to be detected as a double click (ETypeDblClick), which is then detected
+	// at the client side instead of relying on the browser's native detection
+	// (which uses the system settings).
+	// Pass 0 to use the browser's native detection. This is the default.
+	SetDblClickInterval(d time.Duration)
+
+	// PreserveState tells if client side state of the component is preserved
+	// when the component is re-rendered.
+	PreserveState() bool
+
+	// SetPreserveState sets whether client side state of the component
+	// and its descendants should be preserved when the component is re-rendered
+	// (e.g. because it or one of its ancestors is marked dirty).
+	//
+	// Preserved state includes input values not yet synchronized to the server,
+	// text selections, checked and selected states and scroll positions.
+	// Input values and checked / selected states are only restored if the
+	// server did not change them (if the re-rendered value equals the previously
+	// rendered one).
+	//
+	// Note: state is preserved for elements having an ID (e.g. components),
+	// and only if the component is re-rendered as part of the re-rendered component.
+	SetPreserveState(preserve bool)
+
 	// PreprocessEvent preprocesses an incoming event before it is dispatched.
 	// This gives the opportunity for components to update their new value
 	// before event handlers are called for example.
@@ -119,21 +320,41 @@ type Comp interface {
 	// DispatchEvent dispatches the event to all registered event handlers.
 	dispatchEvent(e Event)
 
+	// renderAttrsAndStyle renders the explicitly set attributes and styles.
+	renderAttrsAndStyle(w Writer)
+
+	// renderEHandlers renders the event handlers as attributes.
+	renderEHandlers(w Writer)
+
 	// Render renders the component (as HTML code).
 	Render(w Writer)
 }
 
 // Comp implementation.
 type compImpl struct {
-	id     ID        // The component id
-	parent Container // Parent container
+	id     ID          // The component id
+	idWin  *windowImpl // Window which allocated the id, nil if the id is provisional
+	parent Container   // Parent container
 
-	attrs     map[string]string // Explicitly set HTML attributes for the component's wrapper tag.
-	styleImpl *styleImpl        // Style builder.
+	attrs     map[string]string       // Explicitly set HTML attributes for the component's wrapper tag.
+	styleImpl *styleImpl              // Style builder.
+	hidden    bool                    // Tells if the component is hidden (not visible).
+	visibleIf func(sess Session) bool // Optional visibility predicate evaluated at render time
 
 	handlers        map[EventType][]EventHandler // Event handlers mapped from event type. Lazily initialized.
 	valueProviderJs []byte                       // If the HTML representation of the component has a value, this JavaScript code code must provide it. It will be automatically sent as the paramCompId parameter.
 	syncOnETypes    map[EventType]bool           // Tells on which event types should comp value sync happen.
+
+	singleFires map[EventType]time.Duration // Single fire windows of event types. Lazily initialized.
+	longPress   time.Duration               // Long press hold duration, 0 means default
+	dblClick    time.Duration               // Double click interval, 0 means native detection
+	lastFired   map[EventType]time.Time     // Last dispatch times of single fire event types. Lazily initialized.
+
+	rateLimitN   int           // Max number of events per rateLimitPer, 0 if there is no limit
+	rateLimitPer time.Duration // Time window of the event rate limit
+
+	jsURLs   []string // URLs of external JavaScript files required by the component
+	inlineJS []string // Inline JavaScript initialization codes of the component
 }
 
 // newCompImpl creates a new compImpl.
@@ -141,7 +362,7 @@ type compImpl struct {
 // JavaScript code which when evaluated provides the component's
 // value. Pass an empty string if the component does not have a value.
 func newCompImpl(valueProviderJs []byte) compImpl {
-	id := nextCompID()
+	id := nextFreeID()
 	return compImpl{id: id, attrs: map[string]string{"id": id.String()}, styleImpl: newStyleImpl(), valueProviderJs: valueProviderJs}
 }
 
@@ -149,8 +370,21 @@ func (c *compImpl) ID() ID {
 	return c.id
 }
 
+// base returns the component implementation.
+func (c *compImpl) base() *compImpl {
+	return c
+}
+
+// baseOf returns the component implementation of a component, nil if it has none.
+func baseOf(c Comp) *compImpl {
+	if b, ok := c.(interface{ base() *compImpl }); ok {
+		return b.base()
+	}
+	return nil
+}
+
 func (c *compImpl) Equals(c2 Comp) bool {
-	return c.id == c2.ID()
+	return c2 != nil && baseOf(c2) == c
 }
 
 func (c *compImpl) Parent() Container {
@@ -169,6 +403,48 @@ func (c *compImpl) makeOrphan() bool {
 	return c.parent.Remove(c)
 }
 
+func (c *compImpl) onRemoved(parent Container) {
+	c.parent = nil
+	c.removeInternalHandlers(baseOf(parent))
+}
+
+func (c *compImpl) dispose() {
+	c.parent = nil
+	c.handlers = nil
+}
+
+// hasHandlers tells if the component has event handlers for the specified
+// event type, not counting internal and empty handlers.
+func (c *compImpl) hasHandlers(etype EventType) bool {
+	for _, h := range c.handlers[etype] {
+		if _, ok := h.(internalHandler); !ok && h != EventHandler(EmptyEHandler) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeInternalHandlers removes the internal event handlers registered by the specified owner component.
+func (c *compImpl) removeInternalHandlers(owner *compImpl) {
+	for etype, handlers := range c.handlers {
+		kept := handlers[:0]
+		for _, h := range handlers {
+			if ih, ok := h.(internalHandler); !ok || ih.owner != owner {
+				kept = append(kept, h)
+			}
+		}
+		// Clear references to removed handlers
+		for i := len(kept); i < len(handlers); i++ {
+			handlers[i] = nil
+		}
+		if len(kept) == 0 {
+			delete(c.handlers, etype)
+		} else {
+			c.handlers[etype] = kept
+		}
+	}
+}
+
 func (c *compImpl) Attr(name string) string {
 	return c.attrs[name]
 }
@@ -200,14 +476,43 @@ func (c *compImpl) SetToolTip(toolTip string) {
 	c.SetAttr("title", html.EscapeString(toolTip))
 }
 
+func (c *compImpl) Data(key string) string {
+	return html.UnescapeString(c.Attr("data-" + strings.ToLower(key)))
+}
+
+func (c *compImpl) SetData(key, value string) {
+	c.SetAttr("data-"+strings.ToLower(key), html.EscapeString(value))
+}
+
+func (c *compImpl) Name() string {
+	return html.UnescapeString(c.Attr(attrName))
+}
+
+func (c *compImpl) SetName(name string) {
+	c.SetAttr(attrName, html.EscapeString(name))
+}
+
+func (c *compImpl) AddJS(url string) {
+	for _, u := range c.jsURLs {
+		if u == url {
+			return
+		}
+	}
+	c.jsURLs = append(c.jsURLs, url)
+}
+
+func (c *compImpl) AddInlineJS(code string) {
+	c.inlineJS = append(c.inlineJS, code)
+}
+
 func (c *compImpl) Style() Style {
 	return c.styleImpl
 }
 
 func (c *compImpl) DescendantOf(c2 Comp) bool {
 	for parent := c.parent; parent != nil; parent = parent.Parent() {
-		// Always compare components by id, because Comp.Parent()
-		// only returns Parent and not the components real type (e.g. windowImpl)!
+		// Always compare components by id, c2 might be a different
+		// interface value (or embedded implementation) of the same component.
 		if parent.Equals(c2) {
 			return true
 		}
@@ -216,13 +521,139 @@ func (c *compImpl) DescendantOf(c2 Comp) bool {
 	return false
 }
 
+// pathContainer is implemented by containers to provide the path segments of their child components.
+type pathContainer interface {
+	// childPath returns the path segment of the child component with the specified id,
+	// or an empty string if there is no such child component.
+	childPath(id ID) string
+}
+
+// childrenContainer is implemented by containers to enumerate their child components.
+type childrenContainer interface {
+	// childComps returns the child components of the container.
+	childComps() []Comp
+}
+
+// Walk walks the component tree rooted at the specified component:
+// it calls fn for the component and its descendants (depth-first),
+// until fn returns false. Returns false if walking was stopped by fn.
+//
+// Children of all built-in containers are visited (e.g. the tab bar and the
+// tabs of a TabPanel, the header and the content of an Expander).
+// Walk must be called while the session is locked (e.g. from event handlers).
+func Walk(root Comp, fn func(c Comp) bool) bool {
+	if !fn(root) {
+		return false
+	}
+	if cc, ok := root.(childrenContainer); ok {
+		for _, c2 := range cc.childComps() {
+			if !Walk(c2, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// stats returns the number of event handlers of the component,
+// and the total size of its HTML attributes (names and values).
+func (c *compImpl) stats() (handlers, attrBytes int) {
+	for _, hs := range c.handlers {
+		handlers += len(hs)
+	}
+	for name, value := range c.attrs {
+		attrBytes += len(name) + len(value)
+	}
+	return
+}
+
+// setEnabledRecursive sets the enabled property of the descendants
+// of a container implementing HasEnabled.
+func setEnabledRecursive(c Container, enabled bool) {
+	Walk(c, func(c2 Comp) bool {
+		if he, ok := c2.(HasEnabled); ok {
+			he.SetEnabled(enabled)
+		}
+		return true
+	})
+}
+
+func (c *compImpl) Path() string {
+	if c.parent == nil {
+		return ""
+	}
+
+	seg := ""
+	if pc, ok := c.parent.(pathContainer); ok {
+		seg = pc.childPath(c.id)
+	}
+	if seg == "" {
+		seg = "comp"
+	}
+
+	if parentPath := c.parent.Path(); parentPath != "" {
+		return parentPath + "/" + seg
+	}
+	return seg
+}
+
+func (c *compImpl) Visible() bool {
+	return !c.hidden
+}
+
+func (c *compImpl) SetVisible(visible bool) {
+	c.hidden = !visible
+}
+
+func (c *compImpl) SetVisibleIf(f func(sess Session) bool) {
+	c.visibleIf = f
+}
+
+// visibleTo tells if the component is visible to the specified session
+// according to its visibility predicate.
+func (c *compImpl) visibleTo(sess Session) bool {
+	return c.visibleIf == nil || c.visibleIf(sess)
+}
+
+var strDisplayNone = []byte("display:none !important;") // "display:none !important;"
+
 // renderAttrs renders the explicitly set attributes and styles.
 func (c *compImpl) renderAttrsAndStyle(w Writer) {
-	for name, value := range c.attrs {
-		w.WriteAttr(name, value)
+	rangeAttrs(w, c.attrs, func(name, value string) {
+		if name == "id" {
+			writeIDAttr(w, value)
+		} else {
+			w.WriteAttr(name, value)
+		}
+	})
+
+	if pcss := c.styleImpl.pseudoCSS(); pcss != "" {
+		w.WriteAttr(attrPseudoCSS, html.EscapeString(pcss))
+	}
+
+	if len(c.jsURLs) > 0 && !reportMode(w) {
+		w.WriteAttr(attrJsURLs, html.EscapeString(strings.Join(c.jsURLs, " ")))
+	}
+	if len(c.inlineJS) > 0 && !reportMode(w) {
+		w.WriteAttr(attrInlineJS, html.EscapeString(strings.Join(c.inlineJS, ";\n")))
+	}
+
+	if wi, ok := w.(writerImpl); ok && wi.paths {
+		if path := c.Path(); path != "" {
+			w.WriteAttr(attrPath, html.EscapeString(path))
+		}
 	}
 
-	c.styleImpl.render(w)
+	if c.hidden || !c.visibleTo(writerSession(w)) {
+		// Explicit display style must not make a hidden component visible
+		c.styleImpl.renderClasses(w)
+		w.Write(strStyle)
+		c.styleImpl.renderAttrs(w)
+		w.Write(strDisplayNone)
+		w.Write(strQuote)
+	} else {
+		c.styleImpl.render(w)
+	}
 }
 
 func (c *compImpl) AddEHandler(handler EventHandler, etypes ...EventType) {
@@ -238,6 +669,49 @@ func (c *compImpl) AddEHandlerFunc(hf func(e Event), etypes ...EventType) {
 	c.AddEHandler(handlerFuncWrapper{hf}, etypes...)
 }
 
+func (c *compImpl) AddEHandlerFuncIf(js string, hf func(e Event), etypes ...EventType) {
+	c.AddEHandler(guardedHandler{handlerFuncWrapper{hf}, js}, etypes...)
+}
+
+func (c *compImpl) AddKeyHandler(key Key, mods ModKey, hf func(e Event)) {
+	c.AddEHandlerFuncIf(keyGuard(key, mods), hf, ETypeKeyDown)
+}
+
+// keyGuard returns a client-side predicate which tells if the specified key
+// is pressed with exactly the specified modifier keys.
+func keyGuard(key Key, mods ModKey) string {
+	js := "event.keyCode==" + strconv.Itoa(int(key))
+	for _, m := range []struct {
+		mod  ModKey
+		prop string
+	}{{ModKeyAlt, "altKey"}, {ModKeyCtrl, "ctrlKey"}, {ModKeyMeta, "metaKey"}, {ModKeyShift, "shiftKey"}} {
+		if mods&m.mod == 0 {
+			js += "&&!event." + m.prop
+		} else {
+			js += "&&event." + m.prop
+		}
+	}
+	return js + "&&!event.repeat"
+}
+
+// guard returns the client-side predicate deciding if events of the specified
+// type are to be sent, an empty string if they are always sent.
+func (c *compImpl) guard(etype EventType) string {
+	var guards []string
+	for _, h := range c.handlers[etype] {
+		gh, ok := h.(guardedHandler)
+		if !ok || gh.guard == "" {
+			return ""
+		}
+		guards = append(guards, "("+gh.guard+")")
+	}
+	return strings.Join(guards, "||")
+}
+
+func (c *compImpl) AddEHandlerErr(hf func(e Event) error, etypes ...EventType) {
+	c.AddEHandler(handlerErrFuncWrapper{hf}, etypes...)
+}
+
 func (c *compImpl) HandlersCount(etype EventType) int {
 	return len(c.handlers[etype])
 }
@@ -268,33 +742,213 @@ func (c *compImpl) AddSyncOnETypes(etypes ...EventType) {
 	}
 }
 
+func (c *compImpl) LongPressDuration() time.Duration {
+	if c.longPress <= 0 {
+		return defaultLongPress
+	}
+	return c.longPress
+}
+
+func (c *compImpl) SetLongPressDuration(d time.Duration) {
+	c.longPress = d
+}
+
+func (c *compImpl) DblClickInterval() time.Duration {
+	return c.dblClick
+}
+
+func (c *compImpl) SetDblClickInterval(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	c.dblClick = d
+}
+
+func (c *compImpl) SingleFire(etype EventType) time.Duration {
+	return c.singleFires[etype]
+}
+
+func (c *compImpl) SetSingleFire(etype EventType, window time.Duration) {
+	if window <= 0 {
+		delete(c.singleFires, etype)
+		delete(c.lastFired, etype)
+		return
+	}
+
+	if c.singleFires == nil {
+		c.singleFires = make(map[EventType]time.Duration, 1)
+	}
+	c.singleFires[etype] = window
+}
+
+func (c *compImpl) EventRateLimit() (n int, per time.Duration) {
+	return c.rateLimitN, c.rateLimitPer
+}
+
+func (c *compImpl) SetEventRateLimit(n int, per time.Duration) {
+	if n <= 0 || per <= 0 {
+		n, per = 0, 0
+	}
+	c.rateLimitN, c.rateLimitPer = n, per
+}
+
+// fireAllowed tells if an event of the specified type is allowed to be dispatched
+// according to the single fire settings, and registers the dispatch if so.
+func (c *compImpl) fireAllowed(etype EventType) bool {
+	window := c.singleFires[etype]
+	if window <= 0 {
+		return true
+	}
+
+	now := time.Now()
+	if last, ok := c.lastFired[etype]; ok && now.Sub(last) < window {
+		return false
+	}
+
+	if c.lastFired == nil {
+		c.lastFired = make(map[EventType]time.Time, 1)
+	}
+	c.lastFired[etype] = now
+	return true
+}
+
+// Default hold duration of long presses.
+const defaultLongPress = 500 * time.Millisecond
+
+// Names of HTML attributes used by the client side.
+const (
+	attrPreserveState = "data-gwu-ps"   // Marks components whose client side state is to be preserved
+	attrPseudoCSS     = "data-gwu-pcs"  // Pseudo-class style attributes of components
+	attrSwipe         = "data-gwu-sw"   // Swipe gesture directions components have event handlers for
+	attrLongPress     = "data-gwu-lp"   // Long press hold duration of components in ms
+	attrDblClick      = "data-gwu-dc"   // Double click interval of components in ms
+	attrJsURLs        = "data-gwu-js"   // URLs of external JavaScript files required by components
+	attrInlineJS      = "data-gwu-ijs"  // Inline JavaScript initialization codes of components
+	attrPath          = "data-gwu-path" // Paths of components (see Server.SetRenderPaths())
+	attrName          = "data-gwu-name" // Names of components
+	attrMask          = "data-gwu-mask" // Labels of the masks of masked panels
+	attrSubmit        = "data-gwu-sb"   // IDs of the submit buttons of panels
+	attrCancel        = "data-gwu-cb"   // IDs of the cancel buttons of panels
+	attrSelection     = "data-gwu-sel"  // Selections to be applied to text boxes
+	attrCounter       = "data-gwu-cnt"  // Formats of the character counters of text boxes
+	attrCalValue      = "data-gwu-cv"   // Values of the clickable cells of calendars
+	attrVirtualList   = "data-gwu-vl"   // Marks virtual lists (whose scroll events are sent debounced)
+	attrFilter        = "data-gwu-flt"  // IDs of the target components of filter boxes
+	attrFocusTrap     = "data-gwu-ft"   // Marks panels the focus is trapped inside
+	attrPopupAnchor   = "data-gwu-pa"   // ID of the anchor component of the shown popup
+	attrPopupPlace    = "data-gwu-pp"   // Placement of the shown popup
+	attrResizable     = "data-gwu-rs"   // Marks resizable panels (whose size is synced to the server)
+	attrTwoFactor     = "data-gwu-tfa"  // Marks two-factor boxes
+	attrIdle          = "data-gwu-idle" // Idle timeouts of idle monitors in seconds
+	attrIdleWarn      = "data-gwu-iw"   // Countdown dialog periods of idle monitors in seconds
+	attrIdleText      = "data-gwu-it"   // Countdown dialog texts of idle monitors
+)
+
+func (c *compImpl) PreserveState() bool {
+	return c.attrs[attrPreserveState] != ""
+}
+
+func (c *compImpl) SetPreserveState(preserve bool) {
+	if preserve {
+		c.SetAttr(attrPreserveState, "1")
+	} else {
+		c.SetAttr(attrPreserveState, "")
+	}
+}
+
 var (
-	strSePrefix = []byte(`="se(event,`) // `="se(event,`
-	strSeSuffix = []byte(`)"`)          // `)"`
+	strSePrefix   = []byte(`="se(event,`)   // `="se(event,`
+	strSesfPrefix = []byte(`="sesf(event,`) // `="sesf(event,`
+	strSeSuffix   = []byte(`)"`)            // `)"`
+	strGuardOp    = []byte(`="if(`)         // `="if(`
+	strGuardSe    = []byte(`)se(event,`)    // `)se(event,`
+	strGuardSesf  = []byte(`)sesf(event,`)  // `)sesf(event,`
+
+	strSwipeAttrOp     = []byte(" " + attrSwipe + `="`)     // ` data-gwu-sw="`
+	strLongPressAttrOp = []byte(" " + attrLongPress + `="`) // ` data-gwu-lp="`
+	strDblClickAttrOp  = []byte(" " + attrDblClick + `="`)  // ` data-gwu-dc="`
 )
 
+// sortedETypes returns the event types of the handlers in increasing order,
+// used to render event handlers in a deterministic order.
+func sortedETypes(handlers map[EventType][]EventHandler) []EventType {
+	etypes := make([]EventType, 0, len(handlers))
+	for etype := range handlers {
+		etypes = append(etypes, etype)
+	}
+	sort.Slice(etypes, func(i, j int) bool { return etypes[i] < etypes[j] })
+	return etypes
+}
+
 // rendrenderEventHandlers renders the event handlers as attributes.
 func (c *compImpl) renderEHandlers(w Writer) {
-	for etype := range c.handlers {
+	if reportMode(w) {
+		return // Reports are interaction-free
+	}
+
+	for _, etype := range sortedETypes(c.handlers) {
 		etypeAttr := etypeAttrs[etype]
 		if len(etypeAttr) == 0 { // Only general events are added to the etypeAttrs map
 			continue
 		}
+		if etype == ETypeDblClick && c.dblClick > 0 {
+			continue // Detected at the client side by a global handler
+		}
 
 		// To render                 : ` <etypeAttr>="se(event,etype,compId,value)"`
 		// Example (checkbox onclick): ` onclick="se(event,0,4327,this.checked)"`
+		// In single fire mode sesf() is called instead of se().
+		// With a client-side predicate:  ` <etypeAttr>="if(guard)se(event,etype,compId,value)"`
 		w.Write(strSpace)
 		w.Write(etypeAttr)
-		w.Write(strSePrefix)
+		if guard := c.guard(etype); guard != "" {
+			w.Write(strGuardOp)
+			w.Writes(html.EscapeString(guard))
+			if c.singleFires[etype] > 0 {
+				w.Write(strGuardSesf)
+			} else {
+				w.Write(strGuardSe)
+			}
+		} else if c.singleFires[etype] > 0 {
+			w.Write(strSesfPrefix)
+		} else {
+			w.Write(strSePrefix)
+		}
 		w.Writev(int(etype))
 		w.Write(strComma)
-		w.Writev(int(c.id))
+		writeID(w, c.id)
 		if len(c.valueProviderJs) > 0 && c.syncOnETypes != nil && c.syncOnETypes[etype] {
 			w.Write(strComma)
 			w.Write(c.valueProviderJs)
 		}
 		w.Write(strSeSuffix)
 	}
+
+	// Swipe gestures are synthesized at the client side by a global handler,
+	// only the swipe directions are rendered.
+	left, right := c.handlers[ETypeSwipeLeft] != nil, c.handlers[ETypeSwipeRight] != nil
+	if left || right {
+		w.Write(strSwipeAttrOp)
+		if left {
+			w.Writes("L")
+		}
+		if right {
+			w.Writes("R")
+		}
+		w.Write(strQuote)
+	}
+
+	// Long presses and custom double clicks are also detected by global handlers
+	if c.handlers[ETypeLongPress] != nil {
+		w.Write(strLongPressAttrOp)
+		w.Writev(int(c.LongPressDuration() / time.Millisecond))
+		w.Write(strQuote)
+	}
+	if c.dblClick > 0 && c.handlers[ETypeDblClick] != nil {
+		w.Write(strDblClickAttrOp)
+		w.Writev(int(c.dblClick / time.Millisecond))
+		w.Write(strQuote)
+	}
 }
 
 // THIS IS AN EMPTY IMPLEMENTATION AS NOT ALL COMPONENTS NEED THIS.
@@ -303,6 +957,10 @@ func (c *compImpl) preprocessEvent(event Event, r *http.Request) {
 }
 
 func (c *compImpl) dispatchEvent(e Event) {
+	if !c.fireAllowed(e.Type()) {
+		return
+	}
+
 	for _, handler := range c.handlers[e.Type()] {
 		handler.HandleEvent(e)
 	}
@@ -63,16 +63,25 @@ type HasEnabled interface {
 
 	// SetEnabled sets the enabled property.
 	SetEnabled(enabled bool)
+
+	// SetEnabledIf sets a predicate which is evaluated each time the component
+	// is rendered, with the session of the client it is rendered for.
+	// If the predicate returns false, the component is rendered disabled,
+	// and its events are refused for that session.
+	// The session may be nil if the component is rendered outside of a client request.
+	// Pass nil to remove the predicate.
+	SetEnabledIf(f func(sess Session) bool)
 }
 
 // newHasEnabledImpl returns a new hasEnabledImpl.
 func newHasEnabledImpl() hasEnabledImpl {
-	return hasEnabledImpl{true} // Enabled by default
+	return hasEnabledImpl{enabled: true} // Enabled by default
 }
 
 // HasEnabled implementation.
 type hasEnabledImpl struct {
-	enabled bool // The enabled property
+	enabled   bool                    // The enabled property
+	enabledIf func(sess Session) bool // Optional enabled predicate evaluated at render time
 }
 
 func (c *hasEnabledImpl) Enabled() bool {
@@ -83,11 +92,21 @@ func (c *hasEnabledImpl) SetEnabled(enabled bool) {
 	c.enabled = enabled
 }
 
+func (c *hasEnabledImpl) SetEnabledIf(f func(sess Session) bool) {
+	c.enabledIf = f
+}
+
+// enabledFor tells if the component is enabled for the specified session
+// according to its enabled predicate.
+func (c *hasEnabledImpl) enabledFor(sess Session) bool {
+	return c.enabledIf == nil || c.enabledIf(sess)
+}
+
 var strDisabled = []byte(` disabled="disabled"`) // ` disabled="disabled"`
 
 // renderEnabled renders the enabled attribute.
 func (c *hasEnabledImpl) renderEnabled(w Writer) {
-	if !c.enabled {
+	if !c.enabled || !c.enabledFor(writerSession(w)) {
 		w.Write(strDisabled)
 	}
 }
@@ -250,6 +269,18 @@ func (c *cellFmtImpl) Style() Style {
 	return c.styleImpl
 }
 
+// clone returns a deep copy of the cell formatter.
+func (c *cellFmtImpl) clone() *cellFmtImpl {
+	c2 := &cellFmtImpl{hasHVAlignImpl: c.hasHVAlignImpl, styleImpl: c.styleImpl.clone()}
+	if c.attrs != nil {
+		c2.attrs = make(map[string]string, len(c.attrs))
+		for name, value := range c.attrs {
+			c2.attrs[name] = value
+		}
+	}
+	return c2
+}
+
 func (c *cellFmtImpl) attr(name string) string {
 	return c.attrs[name]
 }
@@ -290,9 +321,9 @@ var strVAlign = []byte("vertical-align:") // "vertical-align:"
 func (c *cellFmtImpl) renderWithAligns(tag []byte, halign HAlign, valign VAlign, w Writer) {
 	w.Write(tag)
 
-	for name, value := range c.attrs {
+	rangeAttrs(w, c.attrs, func(name, value string) {
 		w.WriteAttr(name, value)
-	}
+	})
 
 	if halign != HADefault {
 		w.Write(strAlign)
@@ -0,0 +1,150 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Component type registry and support for custom components.
+
+package gwu
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CompFactory is a function which creates a new component.
+type CompFactory func() Comp
+
+var (
+	compTypesMux sync.RWMutex // Mutex to protect the registered component types
+
+	// compTypes holds the registered component factories mapped from type name.
+	compTypes = map[string]CompFactory{
+		"window":       func() Comp { return NewWindow("", "") },
+		"panel":        func() Comp { return NewPanel() },
+		"hpanel":       func() Comp { return NewHorizontalPanel() },
+		"vpanel":       func() Comp { return NewVerticalPanel() },
+		"naturalpanel": func() Comp { return NewNaturalPanel() },
+		"repeater":     func() Comp { return NewRepeater(NewPanel(), nil) },
+		"table":        func() Comp { return NewTable() },
+		"tabpanel":     func() Comp { return NewTabPanel() },
+		"expander":     func() Comp { return NewExpander() },
+		"label":        func() Comp { return NewLabel("") },
+		"button":       func() Comp { return NewButton("") },
+		"link":         func() Comp { return NewLink("", "") },
+		"image":        func() Comp { return NewImage("", "") },
+		"html":         func() Comp { return NewHTML("") },
+		"textbox":      func() Comp { return NewTextBox("") },
+		"passwbox":     func() Comp { return NewPasswBox("") },
+		"timebox":      func() Comp { return NewTimeBox() },
+		"durationbox":  func() Comp { return NewDurationBox(0) },
+		"filterbox":    func() Comp { return NewFilterBox(nil) },
+		"twofactorbox": func() Comp { return NewTwoFactorBox() },
+		"calendar":     func() Comp { return NewCalendar() },
+		"checkbox":     func() Comp { return NewCheckBox("") },
+		"checkboxlist": func() Comp { return NewCheckBoxList(nil) },
+		"switchbutton": func() Comp { return NewSwitchButton() },
+		"listbox":      func() Comp { return NewListBox(nil) },
+		"transferlist": func() Comp { return NewTransferList(nil) },
+		"virtuallist":  func() Comp { return NewVirtualList(0, nil) },
+		"sessmonitor":  func() Comp { return NewSessMonitor() },
+		"idlemonitor":  func() Comp { return NewIdleMonitor(15 * time.Minute) },
+	}
+)
+
+// RegisterCompType registers a component factory under the specified type name.
+// Registered component types can be instantiated by name with NewCompOfType(),
+// which is used for example by the declarative UI builder (gwu/uibuilder).
+//
+// Built-in component types are pre-registered with their lower-cased names
+// (e.g. "label", "textbox", "hpanel"). Registering an already registered
+// name replaces the previous factory.
+// Passing a nil factory unregisters the type name.
+//
+// RegisterCompType is safe for concurrent use.
+func RegisterCompType(name string, factory CompFactory) {
+	compTypesMux.Lock()
+	if factory == nil {
+		delete(compTypes, name)
+	} else {
+		compTypes[name] = factory
+	}
+	compTypesMux.Unlock()
+}
+
+// NewCompOfType creates a new component of the specified registered type.
+// nil is returned if no component type is registered with the specified name.
+func NewCompOfType(name string) Comp {
+	compTypesMux.RLock()
+	factory := compTypes[name]
+	compTypesMux.RUnlock()
+
+	if factory == nil {
+		return nil
+	}
+	return factory()
+}
+
+// CompTypes returns the names of the registered component types, sorted.
+func CompTypes() []string {
+	compTypesMux.RLock()
+	names := make([]string, 0, len(compTypes))
+	for name := range compTypes {
+		names = append(names, name)
+	}
+	compTypesMux.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// RenderAttrs renders the HTML attributes of the specified component:
+// the explicitly set attributes (including its id), the style
+// and the attributes of the registered event handlers.
+//
+// This is to be used by custom components implemented outside of the gwu package
+// when rendering their wrapper HTML tag, for example:
+//
+//	func (c *myComp) Render(w gwu.Writer) {
+//		w.Writes("<div")
+//		gwu.RenderAttrs(w, c)
+//		w.Writes(">...</div>")
+//	}
+func RenderAttrs(w Writer, c Comp) {
+	c.renderAttrsAndStyle(w)
+	c.renderEHandlers(w)
+}
+
+// compTypeName returns the type name of a component used in component paths:
+// the lower-cased name of its implementation type without the "Impl" suffix, e.g. "button".
+func compTypeName(c Comp) string {
+	t := reflect.TypeOf(c)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return strings.ToLower(strings.TrimSuffix(t.Name(), "Impl"))
+}
+
+// pathSeg returns the path segment of a child component at the specified indices
+// in its parent, e.g. "button[0]" or "label[1,2]".
+func pathSeg(c Comp, idx ...int) string {
+	s := make([]string, len(idx))
+	for i, v := range idx {
+		s[i] = strconv.Itoa(v)
+	}
+	return compTypeName(c) + "[" + strings.Join(s, ",") + "]"
+}
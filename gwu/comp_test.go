@@ -0,0 +1,381 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestRemoveCleanup tests that removing components from containers
+// removes the internal handlers and cell formatters of the containers.
+func TestRemoveCleanup(t *testing.T) {
+	tp := NewTabPanel()
+	tab, content := NewLabel("Tab"), NewLabel("Content")
+	tp.Add(tab, content)
+	if n := tab.HandlersCount(ETypeClick); n != 1 {
+		t.Fatalf("Got: %d handlers, want: 1", n)
+	}
+	tp.Remove(content)
+	if n := tab.HandlersCount(ETypeClick); n != 0 {
+		t.Errorf("Internal handler of removed tab not removed, got: %d handlers", n)
+	}
+
+	e := NewExpander()
+	header := NewLabel("Header")
+	header.AddEHandlerFunc(func(e Event) {}, ETypeClick)
+	e.SetHeader(header)
+	e.SetHeader(NewLabel("Header 2"))
+	if n := header.HandlersCount(ETypeClick); n != 1 {
+		t.Errorf("Got: %d handlers of replaced header, want: 1", n)
+	}
+	if header.Parent() != nil {
+		t.Error("Replaced header still has a parent")
+	}
+
+	tab2 := NewTable()
+	l := NewLabel("")
+	tab2.Add(l, 0, 0)
+	tab2.CellFmt(0, 0).Style().SetColor("red")
+	tab2.Remove(l)
+	if len(tab2.(*tableImpl).cellFmts) != 0 {
+		t.Error("Cell formatter of removed component not removed")
+	}
+}
+
+// TestPanelReplaceSwap tests Panel.ReplaceAt() and Panel.Swap().
+func TestPanelReplaceSwap(t *testing.T) {
+	p := NewPanel()
+	a, b, x := NewLabel("a"), NewLabel("b"), NewLabel("x")
+	p.Add(a)
+	p.Add(b)
+	cf := p.CellFmt(a)
+
+	if old := p.ReplaceAt(0, x); old != a {
+		t.Errorf("Got: %v, want: %v", old, a)
+	}
+	if p.CellFmt(x) != cf || a.Parent() != nil || x.Parent() == nil {
+		t.Error("Cell formatter or parents not updated")
+	}
+	if p.ReplaceAt(0, b) != nil || p.ReplaceAt(2, a) != nil {
+		t.Error("Expected nil for child component or invalid index")
+	}
+
+	if !p.Swap(0, 1) || p.CompAt(0) != b || p.CompAt(1) != x {
+		t.Error("Components not swapped")
+	}
+	if p.CellFmt(b) != cf {
+		t.Error("Cell formatter not kept at its slot")
+	}
+}
+
+// TestWalk tests Walk().
+func TestWalk(t *testing.T) {
+	win := NewWindow("main", "Main")
+	tab := NewTable()
+	tab.Add(NewLabel(""), 0, 1)
+	tab.Add(NewButton(""), 2, 0)
+	win.Add(tab)
+	win.Add(NewTextBox(""))
+
+	if cells := tab.Cells(); len(cells) != 2 || cells[1].Row != 2 || cells[1].Col != 0 {
+		t.Errorf("Unexpected cells: %v", cells)
+	}
+
+	count := 0
+	Walk(win, func(c Comp) bool {
+		count++
+		return true
+	})
+	if count != 5 {
+		t.Errorf("Got: %d components, want: 5", count)
+	}
+
+	count = 0
+	if Walk(win, func(c Comp) bool {
+		count++
+		_, isLabel := c.(Label)
+		return !isLabel
+	}) || count != 3 {
+		t.Errorf("Walk not stopped, visited: %d", count)
+	}
+}
+
+// TestSetEnabledRecursive tests Container.SetEnabledRecursive().
+func TestSetEnabledRecursive(t *testing.T) {
+	p := NewPanel()
+	tab := NewTable()
+	tb := NewTextBox("")
+	cb := NewCheckBox("")
+	tab.Add(tb, 0, 0)
+	p.Add(tab)
+	p.Add(cb)
+
+	p.SetEnabledRecursive(false)
+	if tb.Enabled() || cb.Enabled() {
+		t.Error("Components not disabled")
+	}
+	if !cb.Style().HasClass("gwu-CheckBox-Disabled") {
+		t.Error("Disabled style class not added")
+	}
+	p.SetEnabledRecursive(true)
+	if !tb.Enabled() || !cb.Enabled() {
+		t.Error("Components not enabled")
+	}
+}
+
+// TestPanelMasked tests rendering masked panels.
+func TestPanelMasked(t *testing.T) {
+	p := NewPanel()
+	p.SetMasked(true, "Saving...")
+	buf := &bytes.Buffer{}
+	p.Render(NewWriter(buf))
+	if !strings.Contains(buf.String(), `data-gwu-mask="Saving..."`) {
+		t.Errorf("Mask not rendered: %s", buf)
+	}
+}
+
+// TestKeyboardNavAttrs tests the attributes making tabs and expander headers keyboard accessible.
+func TestKeyboardNavAttrs(t *testing.T) {
+	tp := NewTabPanel()
+	tab1, tab2 := NewLabel("1"), NewLabel("2")
+	tp.Add(tab1, NewLabel("c1"))
+	tp.Add(tab2, NewLabel("c2"))
+	tp.SetSelected(1)
+	if tab1.Attr("tabindex") != "-1" || tab2.Attr("tabindex") != "0" || tab2.Attr("aria-selected") != "true" {
+		t.Errorf("Unexpected tab attributes: %v, %v", tab1.Attr("tabindex"), tab2.Attr("tabindex"))
+	}
+	tp.Remove(tab1)
+	if tab1.Attr("role") != "" || tab1.Attr("tabindex") != "" {
+		t.Errorf("Attributes of removed tab not cleared")
+	}
+
+	e := NewExpander()
+	h := NewLabel("header")
+	e.SetHeader(h)
+	e.SetExpanded(true)
+	if h.Attr("role") != "button" || h.Attr("tabindex") != "0" || h.Attr("aria-expanded") != "true" {
+		t.Errorf("Unexpected header attributes: %v, %v", h.Attr("role"), h.Attr("aria-expanded"))
+	}
+}
+
+// TestChildPos tests Panel.SetChildPos() and Panel.SetChildZ().
+func TestChildPos(t *testing.T) {
+	p := NewNaturalPanel()
+	l1, l2 := NewLabel("a"), NewLabel("b")
+	p.Add(l1)
+	p.Add(l2)
+	p.SetChildPos(l1, 10, 20)
+	p.SetChildZ(l1, 2)
+	buf := &bytes.Buffer{}
+	p.Render(NewWriter(buf))
+	html := buf.String()
+	for _, s := range []string{"position:relative", "position:absolute", "top:20px", "left:10px", "z-index:2"} {
+		if !strings.Contains(html, s) {
+			t.Errorf("%q not rendered: %s", s, html)
+		}
+	}
+	if strings.Count(html, "<span") != 4 { // Panel, wrapper and 2 labels
+		t.Errorf("Unexpected wrappers: %s", html)
+	}
+}
+
+// TestPanelSubmit tests Panel.SetSubmitButton() and Panel.SetCancelButton().
+func TestPanelSubmit(t *testing.T) {
+	w := NewWindow("w", "")
+	p := NewPanel()
+	p.Add(NewTextBox(""))
+	ok, cancel := NewButton("OK"), NewButton("Cancel")
+	p.Add(ok)
+	p.SetSubmitButton(ok)
+	p.SetCancelButton(cancel)
+	w.Add(p)
+	w.Add(cancel)
+
+	buf := &bytes.Buffer{}
+	p.Render(NewWriter(buf))
+	for _, want := range []string{
+		fmt.Sprintf(`data-gwu-sb="%s"`, ok.ID()),
+		fmt.Sprintf(`data-gwu-cb="%s"`, cancel.ID()),
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("%s not rendered: %s", want, buf)
+		}
+	}
+
+	// Clone refers to the cloned buttons
+	w2 := w.Clone()
+	p2 := w2.CompAt(0).(Panel)
+	if p2.SubmitButton() != p2.CompAt(1) {
+		t.Error("Submit button of clone is not the cloned button")
+	}
+	if p2.CancelButton() != w2.CompAt(1) {
+		t.Error("Cancel button of clone is not the cloned button")
+	}
+}
+
+// TestTextBoxAttrs tests the typed attribute setters of TextBox.
+func TestTextBoxAttrs(t *testing.T) {
+	tb := NewTextBox("")
+	tb.SetPlaceholder(`Say "hi"`)
+	tb.SetInputMode("numeric")
+	tb.SetPattern("[0-9]+")
+	tb.SetSpellCheck(false)
+	if p := tb.Placeholder(); p != `Say "hi"` {
+		t.Errorf("Got placeholder: %q", p)
+	}
+	buf := &bytes.Buffer{}
+	tb.Render(NewWriter(buf))
+	for _, want := range []string{`placeholder="Say &#34;hi&#34;"`, `inputmode="numeric"`, `pattern="[0-9]+"`, `spellcheck="false"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("%s not rendered: %s", want, buf)
+		}
+	}
+
+	tb.SetPlaceholder("")
+	if _, ok := tb.(*textBoxImpl).attrs["placeholder"]; ok {
+		t.Error("Placeholder not removed")
+	}
+}
+
+// TestTextBoxSelection tests that the selection of a TextBox is rendered only once.
+func TestTextBoxSelection(t *testing.T) {
+	tb := NewTextBox("hello")
+	tb.SetSelection(2, -1)
+	buf := &bytes.Buffer{}
+	tb.Render(NewWriter(buf))
+	if !strings.Contains(buf.String(), `data-gwu-sel="2,-1"`) {
+		t.Errorf("Selection not rendered: %s", buf)
+	}
+	buf.Reset()
+	tb.Render(NewWriter(buf))
+	if strings.Contains(buf.String(), "data-gwu-sel") {
+		t.Errorf("Selection rendered again: %s", buf)
+	}
+}
+
+// TestListBoxGroups tests ListBox groups and disabled items.
+func TestListBoxGroups(t *testing.T) {
+	values := make([]string, 1, 3)
+	values[0] = "a"
+	lb := NewListBox(values)
+	lb.AddGroup("G", "b", "c")
+	if values[:2][1] == "b" {
+		t.Error("Backing array of the values overwritten")
+	}
+	lb.SetItemEnabled(2, false)
+	lb.SetSelected(1, true)
+
+	buf := &bytes.Buffer{}
+	lb.Render(NewWriter(buf))
+	want := `<option>a</option><optgroup label="G"><option selected="selected">b</option>` +
+		`<option disabled="disabled">c</option></optgroup>`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("Got: %s, want: %s", buf, want)
+	}
+}
+
+// TestClone tests cloning a component tree.
+func TestClone(t *testing.T) {
+	p := NewPanel()
+	b := NewButton("OK")
+	b.Style().SetColor("red")
+	b.AddEHandlerFunc(func(e Event) {}, ETypeClick)
+	p.Add(b)
+
+	for _, handlers := range []bool{false, true} {
+		p2, ok := Clone(p, handlers).(Panel)
+		if !ok || p2.ID() == p.ID() || p2.Parent() != nil {
+			t.Fatalf("Invalid clone: %v", p2)
+		}
+		b2 := p2.CompAt(0).(Button)
+		if b2.ID() == b.ID() || b2.Text() != "OK" || b2.Style().Color() != "red" {
+			t.Errorf("Invalid cloned button: %v", b2)
+		}
+		if got, want := b2.HandlersCount(ETypeClick) > 0, handlers; got != want {
+			t.Errorf("Got handlers cloned: %v, want: %v", got, want)
+		}
+	}
+}
+
+// TestPanelLayout tests rendering and restoring resizable and collapsible panels.
+func TestPanelLayout(t *testing.T) {
+	newWin := func() (Window, Panel) {
+		w := NewWindow("w", "")
+		p := NewPanel()
+		p.Add(NewLabel("content"))
+		p.SetResizable(true)
+		p.SetCollapsible(true, "Box")
+		w.Add(p)
+		return w, p
+	}
+
+	_, p := newWin()
+	buf := &bytes.Buffer{}
+	p.Render(NewWriter(buf))
+	for _, want := range []string{`data-gwu-rs="1"`, "gwu-Panel-Title", "Box", "content"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("%s not rendered: %s", want, buf)
+		}
+	}
+
+	w, p := newWin()
+	sess := newSessionImpl(true)
+	sess.SetAttr(panelLayoutAttrPrefix+p.Path(), panelLayout{width: "300px", height: "200px", collapsed: true})
+	sess.AddWin(w)
+	if !p.Collapsed() || p.Style().Width() != "300px" || p.Style().Height() != "200px" {
+		t.Errorf("Layout not restored, collapsed: %v, size: %s x %s", p.Collapsed(), p.Style().Width(), p.Style().Height())
+	}
+	buf.Reset()
+	p.Render(NewWriter(buf))
+	if strings.Contains(buf.String(), "content") {
+		t.Errorf("Collapsed panel content rendered: %s", buf)
+	}
+}
+
+// TestEHandlerGuard tests Comp.AddEHandlerFuncIf().
+func TestEHandlerGuard(t *testing.T) {
+	b := NewButton("b")
+	b.AddEHandlerFuncIf("event.key=='Enter'", func(e Event) {}, ETypeKeyUp)
+	b.AddEHandlerFuncIf("event.altKey", func(e Event) {}, ETypeKeyUp)
+	buf := &bytes.Buffer{}
+	b.Render(NewWriter(buf))
+	if want := `onkeyup="if((event.key==&#39;Enter&#39;)||(event.altKey))se(event,`; !strings.Contains(buf.String(), want) {
+		t.Errorf("%s not rendered: %s", want, buf)
+	}
+
+	// A handler without predicate: events are always sent
+	b.AddEHandlerFunc(func(e Event) {}, ETypeKeyUp)
+	buf.Reset()
+	b.Render(NewWriter(buf))
+	if want := `onkeyup="se(event,`; !strings.Contains(buf.String(), want) {
+		t.Errorf("%s not rendered: %s", want, buf)
+	}
+}
+
+// TestKeyHandler tests Comp.AddKeyHandler().
+func TestKeyHandler(t *testing.T) {
+	b := NewButton("b")
+	b.AddKeyHandler(KeyEnter, ModKeyCtrl|ModKeyShift, func(e Event) {})
+	buf := &bytes.Buffer{}
+	b.Render(NewWriter(buf))
+	want := `onkeydown="if((event.keyCode==13&amp;&amp;!event.altKey&amp;&amp;event.ctrlKey&amp;&amp;!event.metaKey&amp;&amp;event.shiftKey&amp;&amp;!event.repeat))se(event,`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("%s not rendered: %s", want, buf)
+	}
+}
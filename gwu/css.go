@@ -45,8 +45,13 @@ body {font-family:Arial}
 .gwu-Window {}
 
 .gwu-Panel {}
+.gwu-Panel-Resizable {resize:both; overflow:auto}
+.gwu-Panel-Collapsed {height:auto !important; resize:none}
+.gwu-Panel-Title {padding:2px 4px 2px 19px; cursor:pointer; font-weight:bold; background-color:#e8e8e8}
+.gwu-Panel-Inner {width:100%}
 
 .gwu-Table {}
+.gwu-Table-Sticky > thead th {position:sticky; top:0px; z-index:1; background:white}
 
 .gwu-Label {}
 
@@ -62,12 +67,45 @@ body {font-family:Arial}
 .gwu-RadioButton {}
 .gwu-RadioButton-Disabled {color:#888}
 
+.gwu-RadioPanel {}
+
+.gwu-Repeater {}
+
+.gwu-VirtualList {}
+.gwu-VirtualList-Row {overflow:hidden; white-space:nowrap}
+
+.gwu-CheckBoxList {}
+
 .gwu-ListBox {}
 
+.gwu-Binding {}
+.gwu-Binding-Error {color:#c00}
+
+.gwu-TransferList {}
+.gwu-TransferList-List {min-width:120px}
+.gwu-TransferList-Buttons {padding:0px 5px}
+.gwu-TransferList-Buttons button {width:100%}
+
 .gwu-TextBox {}
 
 .gwu-PasswBox {}
 
+.gwu-TimeBox {}
+
+.gwu-DurationBox {}
+.gwu-DurationBox-Invalid {border-color:#c00; background:#fff0f0}
+
+.gwu-TextBox-Counter {margin-left:4px; font-size:80%; color:#888}
+.gwu-TextBox-Counter-Full {color:#c00}
+
+.gwu-FilterBox {}
+
+.gwu-TwoFactorBox {white-space:nowrap}
+.gwu-TwoFactorBox-Digit {width:1.5em; margin-right:4px; text-align:center; font-size:120%}
+.gwu-Filtered {display:none !important}
+
+.gwu-Popup {position:absolute; z-index:1000; background:#fff; border:1px solid #999; box-shadow:2px 2px 6px rgba(0,0,0,0.3)}
+
 .gwu-HTML {}
 
 .gwu-SwitchButton {}
@@ -77,6 +115,21 @@ body {font-family:Arial}
 .gwu-SwitchButton-On-Inactive:enabled, .gwu-SwitchButton-Off-Inactive:enabled {cursor:pointer}
 .gwu-SwitchButton-On-Active, .gwu-SwitchButton-Off-Active, .gwu-SwitchButton-On-Inactive, .gwu-SwitchButton-Off-Inactive {margin:0px;border: 0px; width:100%}
 .gwu-SwitchButton-On-Active:disabled, .gwu-SwitchButton-Off-Active:disabled, .gwu-SwitchButton-On-Inactive:disabled, .gwu-SwitchButton-Off-Inactive:disabled {color:black}
+.gwu-SwitchButton button:focus {outline:2px solid #8080f8; outline-offset:-2px}
+.gwu-SwitchButton-Toggle .gwu-SwitchButton-On-Active, .gwu-SwitchButton-Toggle .gwu-SwitchButton-Off-Active {border-radius:1em; padding:0px 1em; cursor:pointer; transition:background 0.2s}
+
+.gwu-Calendar {border-collapse:collapse; user-select:none}
+.gwu-Calendar td, .gwu-Calendar th {padding:3px 5px; text-align:center}
+.gwu-Calendar-Nav td {cursor:pointer; font-weight:bold}
+.gwu-Calendar-Nav .gwu-Calendar-Title {cursor:default}
+.gwu-Calendar-Weekday {color:#888; font-weight:normal}
+.gwu-Calendar-Day {cursor:pointer}
+.gwu-Calendar-Day:hover {background:#e0e0ff}
+.gwu-Calendar-Other {color:#aaa}
+.gwu-Calendar-Today {font-weight:bold}
+.gwu-Calendar-Highlighted {background:#ffffc0}
+.gwu-Calendar-Selected, .gwu-Calendar-Selected:hover {background:#8080f8; color:white}
+.gwu-Calendar-Disabled, .gwu-Calendar-Disabled:hover {background:none; color:#ccc; cursor:default}
 
 .gwu-Expander {}
 .gwu-Expander-Header, .gwu-Expander-Header-Expanded {cursor:pointer}
@@ -92,12 +145,31 @@ body {font-family:Arial}
 .gwu-TabPanel {}
 .gwu-TabPanel-Content {border:1px solid #8080f8; width:100%; height:100%}
 
+.gwu-IdleMonitor {}
+.gwu-IdleMonitor-Dialog {position:fixed; top:30%; left:50%; transform:translateX(-50%); max-width:400px; padding:16px 24px; background:#fff; border:1px solid #999; box-shadow:2px 2px 6px rgba(0,0,0,0.3); z-index:10002}
+
 .gwu-SessMonitor {}
 .gwu-SessMonitor-Expired, .gwu-SessMonitor-Error {color:red}
+
+.gwu-Toasts {position:fixed; bottom:20px; left:50%; transform:translateX(-50%); z-index:10000}
+.gwu-Toast {margin-top:6px; padding:8px 16px; border-radius:4px; background:#333; color:white; opacity:0.9; animation:gwu-fade-in 300ms}
+.gwu-Toast-Error {background:#c00}
+.gwu-Masked {position:relative}
+.gwu-Mask {position:absolute; top:0; left:0; right:0; bottom:0; display:flex; flex-direction:column; align-items:center; justify-content:center; background:rgba(255,255,255,0.6); z-index:100; cursor:wait}
+.gwu-Mask-Spinner {width:24px; height:24px; border:3px solid #c0c0ff; border-top-color:#8080f8; border-radius:50%; animation:gwu-spin 800ms linear infinite}
+.gwu-Mask-Label {margin-top:6px}
+.gwu-OfflineBanner {position:fixed; top:0; left:0; right:0; padding:6px; text-align:center; background:#c00; color:white; z-index:10001}
+.gwu-LiveRegion {position:absolute; width:1px; height:1px; margin:-1px; padding:0; border:0; overflow:hidden; clip:rect(0,0,0,0); white-space:nowrap}
+
+@keyframes gwu-spin {to {transform:rotate(360deg)}}
+@keyframes gwu-fade-in {from {opacity:0} to {opacity:1}}
+@keyframes gwu-fade-out {from {opacity:1} to {opacity:0}}
+@keyframes gwu-highlight {from {background-color:#ffff80} to {}}
+@keyframes gwu-slide-down {from {transform:translateY(-1em); opacity:0} to {transform:none; opacity:1}}
 `)
 
 	staticCSS[resNameStaticCSS(ThemeDebug)] = []byte(string(staticCSS[resNameStaticCSS(ThemeDefault)]) +
 		`
-.gwu-Window td, .gwu-Table td, .gwu-Panel td, .gwu-TabPanel td {border:1px solid black}
+.gwu-Window td, .gwu-Table td, .gwu-Table th, .gwu-Panel td, .gwu-TabPanel td {border:1px solid black}
 `)
 }
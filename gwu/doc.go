@@ -206,32 +206,84 @@ definitions of the built-in style classes. For example you can define the
 Buttons will have red background without having to change their style individually.
 
 
+Custom Components
+
+The Comp interface has unexported methods (e.g. the ones handling the
+preprocessing and dispatching of events), so components cannot be implemented
+from scratch outside of the gwu package. Custom components can be created
+by embedding an existing component (which provides the unexported methods),
+and overriding its Render() method:
+
+	type clock struct {
+		gwu.HTML // Provides the Comp implementation
+	}
+
+	func (c *clock) Render(w gwu.Writer) {
+		w.Writes("<span")
+		gwu.RenderAttrs(w, c) // Renders id, attributes, style and event handlers
+		w.Writes(">")
+		w.Writees(time.Now().Format("15:04:05")) // Writes the HTML-escaped text
+		w.Writes("</span>")
+	}
+
+	func newClock() gwu.Comp {
+		return &clock{gwu.NewHTML("")}
+	}
+
+Render() must render a single wrapper HTML tag which has the ID of the component
+(gwu.RenderAttrs() renders it), else re-rendering the component (when marked dirty)
+will not work. The Writer passed to Render() provides convenient (and efficient)
+methods to write strings, HTML-escaped texts, numbers and attributes.
+
+Custom components may be registered with RegisterCompType(), making them
+available by name, e.g. to the declarative UI builder (package gwu/uibuilder):
+
+	gwu.RegisterCompType("clock", newClock)
+
+Custom components having a value which is to be synchronized from the browser
+to the server (like the value of a TextBox) can be created by embedding a ValueComp,
+created by NewValueComp() with a JavaScript expression providing the value
+in the browser.
+
+
 Component Palette
 
 Containers to group and lay out components:
 	Expander  - shows and hides a content comp when clicking on the header comp
 	(Link)    - allows only one optional child
 	Panel     - it has configurable layout
+	Repeater  - it renders clones of a prototype component, one for each element of a data slice
 	Table     - it is dynamic and flexible
 	TabPanel  - for tabbed displaying components (only 1 is visible at a time)
+	VirtualList - it renders only the visible rows of a large list
 	Window    - top of component hierarchy, it is an extension of the Panel
 
 Input components to get data from users:
+	Calendar     (it displays a month, a day can be selected)
 	CheckBox
-	ListBox     (it's either a drop-down list or a multi-line/multi-select list box)
-	TextBox     (it's either a one-line text box or a multi-line text area)
+	CheckBoxList (it holds check boxes of the values of a string slice)
+	DurationBox  (it's a text box for entering durations like "1h30m")
+	FilterBox    (it's a text box filtering the items of a list as you type)
+	ListBox      (it's either a drop-down list or a multi-line/multi-select list box)
+	TextBox      (it's either a one-line text box or a multi-line text area)
 	PasswBox
 	RadioButton
+	RadioPanel   (it holds the radio buttons of a radio group)
 	SwitchButton
+	TimeBox      (it's a text box for entering a time of day)
+	TransferList (dual-list selector, it holds 2 list boxes)
+	TwoFactorBox (it's a segmented input for entering 6-digit two-factor codes)
 
 Other components:
 	Button
 	HTML
+	IdleMonitor (it detects idle users, e.g. to log them out)
 	Image
 	Label
 	Link
 	SessMonitor
 	Timer
+	ValueComp   (base of custom value-synced components)
 
 
 Full App Example
@@ -396,7 +448,7 @@ package gwu
 
 // Gowut version information.
 const (
-	GowutVersion       = "v1.4.0"         // Gowut version: "v"major.minor.maintenance[-dev]
+	GowutVersion       = "v1.5.0-dev"     // Gowut version: "v"major.minor.maintenance[-dev]
 	GowutReleaseDate   = "2018-10-02 CET" // Gowut release date
 	GowutRelDateLayout = "2006-01-02 MST" // Gowut release date layout (for time.Parse())
 )
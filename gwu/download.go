@@ -0,0 +1,155 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Serving server-generated file downloads.
+
+package gwu
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// DownloadFunc is the function type that generates the content of a file download.
+// It should write the content to w.
+// If an error is returned, it is logged (but since the content may already
+// be partially sent, the client may receive an incomplete file).
+type DownloadFunc func(w io.Writer) error
+
+// downloadProvider interface is implemented by components
+// that provide server-generated file downloads.
+type downloadProvider interface {
+	// downloadInfo returns the file name, the MIME type and the content generator
+	// function of the download.
+	// If f is nil, the component does not provide a download (currently).
+	downloadInfo() (fileName, mimeType string, f DownloadFunc)
+}
+
+// serveCompDownload serves the file download of a component.
+func (s *serverImpl) serveCompDownload(win Window, w http.ResponseWriter, r *http.Request) {
+	id, err := AtoID(r.FormValue(paramCompID))
+	if err != nil {
+		http.Error(w, "Invalid component id!", http.StatusBadRequest)
+		return
+	}
+
+	var fileName, mimeType string
+	var f DownloadFunc
+	if dp, ok := win.ByID(id).(downloadProvider); ok {
+		fileName, mimeType, f = dp.downloadInfo()
+	}
+	if f == nil {
+		http.Error(w, fmt.Sprint("Download not found: ", id), http.StatusNotFound)
+		return
+	}
+
+	if s.logger != nil {
+		s.logger.Println("\tServing download of comp:", id)
+	}
+
+	s.serveDownload(w, fileName, mimeType, f)
+}
+
+// serveDownload serves a file download generated by f.
+// If mimeType is empty, "application/octet-stream" is used.
+func (s *serverImpl) serveDownload(w http.ResponseWriter, fileName, mimeType string, f DownloadFunc) {
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", mimeType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+
+	if err := f(w); err != nil {
+		if s.logger != nil {
+			s.logger.Printf("\tDownload of %q err: %v\n", fileName, err)
+		} else {
+			log.Printf("Download of %q err: %v\n", fileName, err)
+		}
+	}
+}
+
+// Pending downloads expire after this duration if not requested by the client.
+const downloadTimeout = 5 * time.Minute
+
+// pendingDownload describes a file download registered by an event handler
+// that has not yet been requested by the client.
+type pendingDownload struct {
+	fileName string    // File name
+	mimeType string    // MIME type
+	r        io.Reader // Reader to read the content from
+	created  time.Time // Registration time
+}
+
+// addDownload registers a pending file download, and returns its one-time token.
+func (s *serverImpl) addDownload(fileName, mimeType string, r io.Reader) string {
+	token := genID()
+
+	s.dlMux.Lock()
+	s.downloads[token] = &pendingDownload{fileName: fileName, mimeType: mimeType, r: r, created: time.Now()}
+	s.dlMux.Unlock()
+
+	return token
+}
+
+// servePendingDownload serves a pending file download identified by its token.
+// The download is removed, a token can only be used once.
+func (s *serverImpl) servePendingDownload(w http.ResponseWriter, r *http.Request) {
+	token := r.FormValue(paramDownloadToken)
+
+	s.dlMux.Lock()
+	pd := s.downloads[token]
+	delete(s.downloads, token)
+	s.dlMux.Unlock()
+
+	if pd == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if s.logger != nil {
+		s.logger.Println("\tServing pending download:", pd.fileName)
+	}
+
+	s.serveDownload(w, pd.fileName, pd.mimeType, func(w io.Writer) error {
+		_, err := io.Copy(w, pd.r)
+		closeDownloadReader(pd.r)
+		return err
+	})
+}
+
+// removeExpiredDownloads removes pending downloads that were not requested in time.
+func (s *serverImpl) removeExpiredDownloads() {
+	now := time.Now()
+
+	s.dlMux.Lock()
+	for token, pd := range s.downloads {
+		if now.Sub(pd.created) > downloadTimeout {
+			delete(s.downloads, token)
+			closeDownloadReader(pd.r)
+		}
+	}
+	s.dlMux.Unlock()
+}
+
+// closeDownloadReader closes the reader of a download if it implements io.Closer.
+func closeDownloadReader(r io.Reader) {
+	if c, ok := r.(io.Closer); ok {
+		c.Close()
+	}
+}
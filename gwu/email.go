@@ -0,0 +1,169 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Email-safe HTML rendering of components.
+
+package gwu
+
+import (
+	"bytes"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RenderEmailHTML renders the component (and its subtree) as HTML
+// which can be mailed verbatim (e.g. status reports shown in the UI).
+//
+// The component is rendered like in Window.RenderReport() (interaction-free),
+// and since email clients ignore style sheets, the styles of the CSS classes
+// of the elements (defined by the CSS theme of the component's window,
+// or the default theme) are inlined into their style attributes.
+// Relative URLs of src and href attributes are resolved against base
+// (e.g. "https://example.com/app/"); they are left as-is if base is empty.
+//
+// Visibility and enabled predicates are evaluated with a nil session.
+func RenderEmailHTML(c Comp, base string) string {
+	buf := &bytes.Buffer{}
+	wi := NewWriter(buf).(writerImpl)
+	wi.report = true
+	c.Render(wi)
+
+	theme := ThemeDefault
+	if win := windowOf(c); win != nil && win.theme != "" {
+		theme = win.theme
+	}
+	styles := classStyles(theme)
+
+	var baseURL *url.URL
+	if base != "" {
+		baseURL, _ = url.Parse(base)
+	}
+
+	return tagRegexp.ReplaceAllStringFunc(buf.String(), func(tag string) string {
+		return emailTag(tag, styles, baseURL)
+	})
+}
+
+var (
+	// tagRegexp matches start tags (attribute values are rendered in quotes).
+	tagRegexp = regexp.MustCompile(`<[a-zA-Z][a-zA-Z0-9]*(?:\s+[^\s=>/]+(?:="[^"]*")?)*\s*/?>`)
+	// attrRegexp matches an attribute of a start tag, capturing its name and value.
+	attrRegexp = regexp.MustCompile(`\s+([^\s=>/]+)(?:="([^"]*)")?`)
+	// cssRuleRegexp matches a CSS rule, capturing its selectors and declarations.
+	cssRuleRegexp = regexp.MustCompile(`([^{}]+)\{([^{}]*)\}`)
+	// cssClassRegexp matches a single class selector.
+	cssClassRegexp = regexp.MustCompile(`^\.[a-zA-Z0-9_-]+$`)
+)
+
+var (
+	classStylesMux   sync.Mutex                       // Mutex to protect classStylesCache
+	classStylesCache = map[string]map[string]string{} // Parsed class styles, mapped from theme
+)
+
+// classStyles returns the declarations of the single class selector rules
+// of the specified CSS theme, mapped from class name.
+// Rules with other selectors (e.g. descendant or pseudo class selectors) are ignored.
+func classStyles(theme string) map[string]string {
+	classStylesMux.Lock()
+	defer classStylesMux.Unlock()
+
+	if styles, ok := classStylesCache[theme]; ok {
+		return styles
+	}
+
+	css := staticCSS[resNameStaticCSS(theme)]
+	if css == nil {
+		css = staticCSS[resNameStaticCSS(ThemeDefault)]
+	}
+	styles := map[string]string{}
+	for _, m := range cssRuleRegexp.FindAllStringSubmatch(string(css), -1) {
+		decls := strings.TrimSuffix(strings.TrimSpace(m[2]), ";")
+		if decls == "" {
+			continue
+		}
+		for _, sel := range strings.Split(m[1], ",") {
+			if sel = strings.TrimSpace(sel); cssClassRegexp.MatchString(sel) {
+				if prev := styles[sel[1:]]; prev != "" {
+					styles[sel[1:]] = prev + ";" + decls
+				} else {
+					styles[sel[1:]] = decls
+				}
+			}
+		}
+	}
+	classStylesCache[theme] = styles
+	return styles
+}
+
+// emailTag returns the start tag with the styles of its classes inlined
+// and its relative URLs resolved against baseURL (if not nil).
+func emailTag(tag string, styles map[string]string, baseURL *url.URL) string {
+	end := strings.IndexAny(tag, " \t\r\n/>")
+	out := make([]byte, 0, len(tag)+64)
+	out = append(out, tag[:end]...)
+
+	var style, classStyle string
+	for _, m := range attrRegexp.FindAllStringSubmatch(tag[end:], -1) {
+		name, value := m[1], html.UnescapeString(m[2])
+		switch name {
+		case "style":
+			style = value
+			continue
+		case "class":
+			var decls []string
+			for _, class := range strings.Fields(value) {
+				if s := styles[class]; s != "" {
+					decls = append(decls, s)
+				}
+			}
+			classStyle = strings.Join(decls, ";")
+		case "src", "href":
+			if baseURL != nil && !strings.HasPrefix(value, "#") {
+				if u, err := url.Parse(value); err == nil && !u.IsAbs() {
+					value = baseURL.ResolveReference(u).String()
+				}
+			}
+		}
+		out = append(out, ' ')
+		out = append(out, name...)
+		if strings.Contains(m[0], "=") {
+			out = append(out, `="`...)
+			out = append(out, html.EscapeString(value)...)
+			out = append(out, '"')
+		}
+	}
+
+	// Explicit styles come last so they override the styles of the classes
+	if classStyle != "" && style != "" {
+		style = classStyle + ";" + style
+	} else if classStyle != "" {
+		style = classStyle
+	}
+	if style != "" {
+		out = append(out, ` style="`...)
+		out = append(out, html.EscapeString(style)...)
+		out = append(out, '"')
+	}
+
+	if strings.HasSuffix(tag, "/>") {
+		out = append(out, " />"...)
+	} else {
+		out = append(out, '>')
+	}
+	return string(out)
+}
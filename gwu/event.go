@@ -18,8 +18,12 @@
 package gwu
 
 import (
+	"io"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // EventType is the event type (kind) type.
@@ -33,23 +37,35 @@ func (etype EventType) String() string {
 // Event types.
 const (
 	// General events for all components
-	ETypeClick     EventType = iota // Mouse click event
-	ETypeDblClick                   // Mouse double click event
-	ETypeMousedown                  // Mouse down event
-	ETypeMouseMove                  // Mouse move event
-	ETypeMouseOver                  // Mouse over event
-	ETypeMouseOut                   // Mouse out event
-	ETypeMouseUp                    // Mouse up event
-	ETypeKeyDown                    // Key down event
-	ETypeKeyPress                   // Key press event
-	ETypeKeyUp                      // Key up event
-	ETypeBlur                       // Blur event (component loses focus)
-	ETypeChange                     // Change event (value change)
-	ETypeFocus                      // Focus event (component gains focus)
+	ETypeClick      EventType = iota // Mouse click event
+	ETypeDblClick                    // Mouse double click event
+	ETypeMousedown                   // Mouse down event
+	ETypeMouseMove                   // Mouse move event
+	ETypeMouseOver                   // Mouse over event
+	ETypeMouseOut                    // Mouse out event
+	ETypeMouseUp                     // Mouse up event
+	ETypeKeyDown                     // Key down event
+	ETypeKeyPress                    // Key press event
+	ETypeKeyUp                       // Key up event
+	ETypeBlur                        // Blur event (component loses focus)
+	ETypeChange                      // Change event (value change)
+	ETypeFocus                       // Focus event (component gains focus)
+	ETypeWheel                       // Mouse wheel event (see Event.WheelDelta())
+	ETypeScroll                      // Scroll event (see Event.Scroll())
+	ETypeTouchStart                  // Touch start event (touch coordinates are available via Event.Mouse())
+	ETypeTouchMove                   // Touch move event (touch coordinates are available via Event.Mouse())
+	ETypeTouchEnd                    // Touch end event (touch coordinates are available via Event.Mouse())
+	ETypeSwipeLeft                   // Swipe left gesture event (synthesized from touch events)
+	ETypeSwipeRight                  // Swipe right gesture event (synthesized from touch events)
+	ETypeLongPress                   // Long press event (synthesized, see Comp.SetLongPressDuration())
+	ETypeCut                         // Cut event (cut text is available via Event.ClipboardText())
+	ETypeCopy                        // Copy event (copied text is available via Event.ClipboardText())
+	ETypePaste                       // Paste event (pasted text is available via Event.ClipboardText())
 
 	// Window events (for Window only)
 	ETypeWinLoad   // Window load event
 	ETypeWinUnload // Window unload event
+	ETypeReconnect // Window reconnect event (connection to the server is restored after it was lost)
 
 	// Internal events, generated and dispatched internally while processing another event
 	ETypeStateChange // State change
@@ -75,9 +91,9 @@ const (
 // Category returns the event type category.
 func (etype EventType) Category() EventCategory {
 	switch {
-	case etype >= ETypeClick && etype <= ETypeFocus:
+	case etype >= ETypeClick && etype <= ETypePaste:
 		return ECatGeneral
-	case etype >= ETypeWinLoad && etype <= ETypeWinUnload:
+	case etype >= ETypeWinLoad && etype <= ETypeReconnect:
 		return ECatWindow
 	case etype >= ETypeStateChange && etype <= ETypeStateChange:
 		return ECatInternal
@@ -88,23 +104,32 @@ func (etype EventType) Category() EventCategory {
 
 // Attribute names for the general event types; only for the general event types.
 var etypeAttrs = map[EventType][]byte{
-	ETypeClick:     []byte("onclick"),
-	ETypeDblClick:  []byte("ondblclick"),
-	ETypeMousedown: []byte("onmousedown"),
-	ETypeMouseMove: []byte("onmousemove"),
-	ETypeMouseOver: []byte("onmouseover"),
-	ETypeMouseOut:  []byte("onmouseout"),
-	ETypeMouseUp:   []byte("onmouseup"),
-	ETypeKeyDown:   []byte("onkeydown"),
-	ETypeKeyPress:  []byte("onkeypress"),
-	ETypeKeyUp:     []byte("onkeyup"),
-	ETypeBlur:      []byte("onblur"),
-	ETypeChange:    []byte("onchange"),
-	ETypeFocus:     []byte("onfocus")}
+	ETypeClick:      []byte("onclick"),
+	ETypeDblClick:   []byte("ondblclick"),
+	ETypeMousedown:  []byte("onmousedown"),
+	ETypeMouseMove:  []byte("onmousemove"),
+	ETypeMouseOver:  []byte("onmouseover"),
+	ETypeMouseOut:   []byte("onmouseout"),
+	ETypeMouseUp:    []byte("onmouseup"),
+	ETypeKeyDown:    []byte("onkeydown"),
+	ETypeKeyPress:   []byte("onkeypress"),
+	ETypeKeyUp:      []byte("onkeyup"),
+	ETypeBlur:       []byte("onblur"),
+	ETypeChange:     []byte("onchange"),
+	ETypeFocus:      []byte("onfocus"),
+	ETypeWheel:      []byte("onwheel"),
+	ETypeScroll:     []byte("onscroll"),
+	ETypeTouchStart: []byte("ontouchstart"),
+	ETypeTouchMove:  []byte("ontouchmove"),
+	ETypeTouchEnd:   []byte("ontouchend"),
+	ETypeCut:        []byte("oncut"),
+	ETypeCopy:       []byte("oncopy"),
+	ETypePaste:      []byte("onpaste")}
 
 // Function names for window event types.
 var etypeFuncs = map[EventType][]byte{
 	ETypeWinLoad:   []byte("onload"),
+	ETypeReconnect: []byte("onreconnect"),
 	ETypeWinUnload: []byte("onbeforeunload")} // Bind it to onbeforeunload (instead of onunload) for several reasons (onunload might cause trouble for AJAX; onunload is not called in IE if page is just refreshed...)
 
 // MouseBtn is the mouse button type.
@@ -219,14 +244,88 @@ type Event interface {
 	// The parent event can be used to identify the original source and event type.
 	Parent() Event
 
+	// SrcButton returns the source of the event as a Button,
+	// or nil if the source is not a Button.
+	// Note that state buttons (e.g. CheckBox) are also Buttons.
+	SrcButton() Button
+
+	// SrcStateButton returns the source of the event as a StateButton
+	// (e.g. CheckBox, RadioButton), or nil if the source is not a StateButton.
+	SrcStateButton() StateButton
+
+	// SrcTextBox returns the source of the event as a TextBox,
+	// or nil if the source is not a TextBox.
+	SrcTextBox() TextBox
+
+	// SrcListBox returns the source of the event as a ListBox,
+	// or nil if the source is not a ListBox.
+	SrcListBox() ListBox
+
+	// Data returns the value of the data attribute with the specified key
+	// (see Comp.SetData()) of the source of the original event,
+	// as it was in the browser when the event was sent.
+	// An empty string is returned if the data attribute is not present.
+	Data(key string) string
+
+	// TabID returns the id of the browser tab the event originates from.
+	// The tab id is generated at the client side, and is kept while
+	// the tab is open (even if the page is reloaded).
+	// An empty string is returned if the browser does not support it.
+	TabID() string
+
+	// ClientIP returns the IP address of the client the event originates from.
+	// X-Forwarded-For headers of trusted proxies are respected, see Server.ClientIP().
+	ClientIP() string
+
 	// Mouse returns the mouse x and y coordinates relative to the component.
+	// For touch events the coordinates of the (first) changed touch point are returned.
 	// If no mouse coordinate info is available, (-1, -1) is returned.
 	Mouse() (x, y int)
 
-	// MouseWin returns the mouse x and y coordinates inside the window.
+	// MouseWin returns the mouse x and y coordinates inside the window (viewport),
+	// which does not depend on how the page is scrolled
+	// (e.g. to position components having fixed position).
+	// For touch events the coordinates of the (first) changed touch point are returned.
 	// If no mouse coordinate info is available, (-1, -1) is returned.
 	MouseWin() (x, y int)
 
+	// MousePage returns the mouse x and y coordinates inside the page (document),
+	// which includes the amount the page is scrolled
+	// (e.g. to position components having absolute position).
+	// For touch events the coordinates of the (first) changed touch point are returned.
+	// If no mouse coordinate info is available, (-1, -1) is returned.
+	MousePage() (x, y int)
+
+	// SrcRect returns the bounding rectangle of the source component
+	// inside the page (document) as rendered by the browser (taking CSS
+	// transforms into account), e.g. to position a popup below the component.
+	// If no rect info is available (e.g. for forked events), (-1, -1, -1, -1) is returned.
+	SrcRect() (x, y, width, height int)
+
+	// WheelDelta returns the scroll amounts of a mouse wheel event (ETypeWheel)
+	// in pixels. Positive values mean scrolling right (dx) and down (dy).
+	// (0, 0) is returned for other events.
+	//
+	// Tip: wheel events are fired rapidly; consider enabling single fire mode
+	// with Comp.SetSingleFire() to limit the number of events sent to the server.
+	WheelDelta() (dx, dy int)
+
+	// Scroll returns the scroll position of the source component of a
+	// scroll event (ETypeScroll) in pixels, and the maximum scroll positions
+	// (the scroll position when the component is scrolled to the right / bottom).
+	// Zeros are returned for other events.
+	//
+	// For example an infinite scrolling list can load more items
+	// when y is close to maxY.
+	//
+	// Scroll events are only fired by scrollable components, e.g. a panel
+	// having a fixed size and styles set like this:
+	//     p.Style().SetDisplay("block").SetOverflow(gwu.OverflowAuto)
+	//
+	// Tip: scroll events are fired rapidly; consider enabling single fire mode
+	// with Comp.SetSingleFire() to limit the number of events sent to the server.
+	Scroll() (x, y, maxX, maxY int)
+
 	// MouseBtn returns the mouse button.
 	// If no mouse button info is available, MouseBtnUnknown is returned.
 	MouseBtn() MouseBtn
@@ -243,6 +342,36 @@ type Event interface {
 	// Key code returns the key code.
 	KeyCode() Key
 
+	// KeyText returns the key value of key events as reported by the browser
+	// (the KeyboardEvent.key property), which is the produced character
+	// for printable keys (e.g. "a", "A", "é"), taking the keyboard layout
+	// and the modifier keys into account, and the name of the key
+	// for other keys (e.g. "Enter", "ArrowLeft", "Shift").
+	// An empty string is returned for non-key events or if not supported by the browser.
+	KeyText() string
+
+	// KeyRepeat tells if the key event is a repeated one,
+	// fired because the key is being held down.
+	KeyRepeat() bool
+
+	// Selection returns the selection in the source component if it is a text box,
+	// as character (rune) indices in its text. start == end is the caret position.
+	// (-1, -1) is returned if the source component has no selection.
+	//
+	// Tip: to track the caret position while typing, add key event handlers
+	// to the text box, e.g. ETypeKeyUp (and ETypeClick for caret moves by mouse).
+	Selection() (start, end int)
+
+	// ClipboardText returns the text of cut, copy and paste events
+	// (ETypeCut, ETypeCopy, ETypePaste): the text being cut or copied
+	// (the selected text), or the text being pasted.
+	// An empty string is returned for other events or if the browser
+	// does not allow access to the clipboard.
+	//
+	// Note that the value of the source text box sent with a paste event
+	// does not yet contain the pasted text.
+	ClipboardText() string
+
 	// Requests the specified window to be reloaded
 	// after processing the current event.
 	// Tip: pass an empty string to reload the current window.
@@ -266,6 +395,131 @@ type Event interface {
 	// the current event.
 	SetFocusedComp(comp Comp)
 
+	// ShowPopup shows the specified content component in a popup: a floating
+	// container positioned relative to the anchor component (which must be
+	// added to a window). The content is removed from its parent and is added
+	// to the popup layer of the window of the anchor, so its event handlers work.
+	// Only 1 popup is shown in a window at a time, showing a popup closes the current one.
+	//
+	// The popup is closed (and its content is removed) when the user clicks
+	// outside of the popup and the anchor or presses Escape,
+	// or by calling ClosePopup().
+	//
+	// Popups can be used to build menus, tooltips, autocomplete lists and pickers.
+	ShowPopup(content, anchor Comp, placement Placement)
+
+	// ClosePopup closes the popup shown in the window of the source component (if any).
+	ClosePopup()
+
+	// FocusFirst sets the first focusable descendant of the specified component
+	// to be focused after processing the current event, and returns it.
+	// Focusable components are the enabled buttons, text boxes and list boxes
+	// (and their derivatives) which are visible (including their ancestors).
+	// nil is returned (and the focused component is not changed) if there is
+	// no focusable descendant.
+	FocusFirst(c Comp) Comp
+
+	// FocusFirstInvalid sets the first focusable descendant of the specified
+	// component which has an invalid value to be focused after processing
+	// the current event, and returns it. Components having a Valid() bool
+	// method are validated (e.g. DurationBox).
+	// nil is returned (and the focused component is not changed) if all values are valid.
+	FocusFirstInvalid(c Comp) Comp
+
+	// SendFile sends a file download to the client after processing the current event,
+	// e.g. a dynamically generated CSV or PDF file.
+	// The content of the file is read from r when the client requests it
+	// (which happens right after the event response is received).
+	// If r implements io.Closer, it is closed after the content is sent.
+	// If mimeType is empty, "application/octet-stream" is used.
+	//
+	// Note: the download is not sent if the window is reloaded as a result
+	// of the event (ReloadWin() is called).
+	SendFile(fileName, mimeType string, r io.Reader)
+
+	// Animate runs a CSS animation on the specified component after
+	// processing the current event (and after re-rendering the dirty components),
+	// e.g. to draw attention to a changed component.
+	// name is the name of a CSS animation (keyframes); the AnimXXX constants
+	// are built-in animations, but animations defined in custom CSS files can
+	// also be used (names must not contain commas and semicolons).
+	//
+	// Note: the end state of the animation is kept until the component
+	// is re-rendered; e.g. a component faded out with AnimFadeOut reappears
+	// when re-rendered, unless it is hidden with SetVisible(false).
+	Animate(comp Comp, name string, duration time.Duration)
+
+	// ShowToast shows a short notification message (toast) in the browser
+	// after processing the current event. The toast disappears automatically
+	// after a few seconds.
+	// If isError is true, the message is displayed as an error message.
+	//
+	// Note: the toast is not shown if the window is reloaded as a result
+	// of the event (ReloadWin() is called).
+	ShowToast(message string, isError bool)
+
+	// Announce announces the specified text to screen reader users after
+	// processing the current event, e.g. to convey the result of an update
+	// ("3 items added"). The text is placed in a visually hidden ARIA live region
+	// of the window. If polite is true, the announcement waits until the
+	// screen reader is idle, else it interrupts the current speech.
+	//
+	// Note: the text is not announced if the window is reloaded as a result
+	// of the event (ReloadWin() is called).
+	Announce(text string, polite bool)
+
+	// Mask covers the specified component with a semi-transparent overlay
+	// with a spinner (and the label if not empty) after processing the current
+	// event, without re-rendering the component, e.g. to indicate a long-running
+	// operation. Contained components can't be clicked while masked.
+	//
+	// The mask is removed by Unmask() or when the component is re-rendered.
+	// Use Panel.SetMasked() for a mask which is kept when re-rendered.
+	Mask(comp Comp, label string)
+
+	// Unmask removes the mask of the specified component
+	// (added by Mask() or Panel.SetMasked()) without re-rendering it.
+	Unmask(comp Comp)
+
+	// SetTabBadge sets a badge with the specified count on the browser tab
+	// of the window after processing the current event: the count is prepended
+	// to the title of the document, and drawn over the favicon.
+	// A count of 0 (or less) removes the badge.
+	//
+	// Useful to notify the user about background changes (e.g. new messages)
+	// in long-running sessions.
+	SetTabBadge(count int)
+
+	// RequestAttention requests the attention of the user after processing
+	// the current event if the browser tab of the window is not focused:
+	// the title of the document is flashed until the tab gets focused.
+	// It is a no-op if the tab is focused.
+	RequestAttention()
+
+	// OpenWindow opens the window with the specified name (of the current session
+	// or the public session) in a new browser window or tab after processing the current event.
+	// features is the window features string of the JavaScript window.open()
+	// function (e.g. "width=300,height=400"); if empty, a new tab is opened
+	// in most browsers. If the window is already open (by OpenWindow()),
+	// it is reloaded in its existing browser window.
+	//
+	// Note: popup blockers might block opening the window if the event
+	// was not triggered by the user (e.g. timer events).
+	OpenWindow(name, features string)
+
+	// BroadcastDirty marks the specified components dirty in the windows
+	// of the session they are added to. Components of the window of the
+	// current event are simply marked dirty (like with MarkDirty()),
+	// components of other windows are re-rendered in the browser windows
+	// (tabs) displaying them: these are notified by the browser window
+	// of the current event (using a BroadcastChannel), and they fetch the changes.
+	//
+	// This enables coordination between windows, e.g. tool palettes and
+	// detail popouts opened with OpenWindow(). Event handlers may change components
+	// of other windows of the session directly, and call BroadcastDirty()
+	// to have them refreshed.
+	BroadcastDirty(comps ...Comp)
+
 	// Session returns the current session.
 	// The Private() method of the session can be used to tell if the session
 	// is a private session or the public shared session.
@@ -287,6 +541,10 @@ type Event interface {
 	// Accessing/changing the session and defining post-event actions in the forked
 	// event works as if they would be done on this event.
 	forkEvent(etype EventType, src Comp) Event
+
+	// handleError handles an error returned by an event handler:
+	// logs it and presents it to the user.
+	handleError(err error)
 }
 
 // HasRequestResponse defines methods to acquire / access
@@ -324,23 +582,84 @@ type eventImpl struct {
 
 	x, y int // Mouse coordinates (relative to component); not part of shared data because they component-relative
 
+	selStart, selEnd int // Selection in the source component (text box)
+	rx, ry, rw, rh   int // Bounding rect of the source component (inside the page)
+
 	shared *sharedEvtData // Shared event data
 }
 
+// Built-in CSS animations, to be used with Event.Animate().
+const (
+	AnimFadeIn    = "gwu-fade-in"    // Fades in the component
+	AnimFadeOut   = "gwu-fade-out"   // Fades out the component
+	AnimHighlight = "gwu-highlight"  // Flashes a highlight background on the component
+	AnimSlideDown = "gwu-slide-down" // Slides the component down into its place
+)
+
+// animation describes a CSS animation to be run on a component.
+type animation struct {
+	comp     Comp          // Component to animate
+	name     string        // Name of the CSS animation
+	duration time.Duration // Duration of the animation
+}
+
+// announce describes a text to be announced after the event processing.
+type announce struct {
+	text   string // Text to be announced
+	polite bool   // Tells if the announcement is polite
+}
+
+// mask describes a mask to be added or removed after the event processing.
+type mask struct {
+	comp   Comp   // Component to mask or unmask
+	masked bool   // Tells if the component is to be masked
+	label  string // Label of the mask
+}
+
+// openWin describes a window to be opened after the event processing.
+type openWin struct {
+	name     string // Name of the window
+	features string // Window features
+}
+
+// toast describes a toast (notification message) to be shown after the event processing.
+type toast struct {
+	message string // Message of the toast
+	isError bool   // Tells if the message is an error message
+}
+
 // Event data shared between an event and its child events (forks).
 type sharedEvtData struct {
 	server *serverImpl // Server implementation
 
-	wx, wy  int      // Mouse coordinates (inside the window)
+	wx, wy  int      // Mouse coordinates (inside the window viewport)
+	px, py  int      // Mouse coordinates (inside the page)
 	mbtn    MouseBtn // Mouse button
 	modKeys int      // State of the modifier keys
 	keyCode Key      // Key code
-
-	reload      bool        // Tells if the window has to be reloaded
-	reloadWin   string      // The name of the window to be reloaded
-	dirtyComps  map[ID]Comp // The dirty components
-	focusedComp Comp        // Component to be focused after the event processing
-	session     Session     // Session
+	keyText string   // Key text (value of the key)
+	keyRep  bool     // Tells if the key event is a repeated one
+	clip    string   // Clipboard text of cut, copy and paste events
+
+	wheelDX, wheelDY int // Mouse wheel deltas
+	scrollX, scrollY int // Scroll position of the source component
+	scrollMaxX       int // Maximum horizontal scroll position of the source component
+	scrollMaxY       int // Maximum vertical scroll position of the source component
+
+	reload      bool          // Tells if the window has to be reloaded
+	reloadWin   string        // The name of the window to be reloaded
+	dirtyComps  map[ID]Comp   // The dirty components
+	focusedComp Comp          // Component to be focused after the event processing
+	downloads   []string      // Tokens of the file downloads to be sent after the event processing
+	animations  []animation   // Animations to be run after the event processing
+	toasts      []toast       // Toasts to be shown after the event processing
+	announces   []announce    // Texts to be announced after the event processing
+	masks       []mask        // Masks to be added or removed after the event processing
+	tabBadge    int           // Tab badge count to be set after the event processing, -1 if not to be changed
+	attention   bool          // Tells if the attention of the user is requested after the event processing
+	openWins    []openWin     // Windows to be opened after the event processing
+	broadcast   []*windowImpl // Windows to be refreshed by a broadcast after the event processing
+	session     Session       // Session
 
 	rw  http.ResponseWriter // ResponseWriter of the HTTP request the event was created from
 	req *http.Request       // Request of the HTTP request the event was created from
@@ -349,8 +668,8 @@ type sharedEvtData struct {
 // newEventImpl creates a new eventImpl
 func newEventImpl(etype EventType, src Comp, server *serverImpl, session Session,
 	rw http.ResponseWriter, req *http.Request) *eventImpl {
-	e := eventImpl{etype: etype, src: src,
-		shared: &sharedEvtData{server: server, dirtyComps: make(map[ID]Comp, 2), session: session, rw: rw, req: req}}
+	e := eventImpl{etype: etype, src: src, selStart: -1, selEnd: -1, rx: -1, ry: -1, rw: -1, rh: -1,
+		shared: &sharedEvtData{server: server, dirtyComps: make(map[ID]Comp, 2), tabBadge: -1, session: session, rw: rw, req: req}}
 	return &e
 }
 
@@ -362,6 +681,47 @@ func (e *eventImpl) Src() Comp {
 	return e.src
 }
 
+func (e *eventImpl) SrcButton() Button {
+	c, _ := e.src.(Button)
+	return c
+}
+
+func (e *eventImpl) SrcStateButton() StateButton {
+	c, _ := e.src.(StateButton)
+	return c
+}
+
+func (e *eventImpl) SrcTextBox() TextBox {
+	c, _ := e.src.(TextBox)
+	return c
+}
+
+func (e *eventImpl) SrcListBox() ListBox {
+	c, _ := e.src.(ListBox)
+	return c
+}
+
+func (e *eventImpl) Data(key string) string {
+	if e.shared.req == nil {
+		return ""
+	}
+	return e.shared.req.FormValue(paramDataPrefix + strings.ToLower(key))
+}
+
+func (e *eventImpl) ClientIP() string {
+	if e.shared.req == nil {
+		return ""
+	}
+	return e.shared.server.ClientIP(e.shared.req)
+}
+
+func (e *eventImpl) TabID() string {
+	if e.shared.req == nil {
+		return ""
+	}
+	return e.shared.req.FormValue(paramTabID)
+}
+
 func (e *eventImpl) Parent() Event {
 	return e.parent
 }
@@ -374,6 +734,22 @@ func (e *eventImpl) MouseWin() (x, y int) {
 	return e.shared.wx, e.shared.wy
 }
 
+func (e *eventImpl) MousePage() (x, y int) {
+	return e.shared.px, e.shared.py
+}
+
+func (e *eventImpl) SrcRect() (x, y, width, height int) {
+	return e.rx, e.ry, e.rw, e.rh
+}
+
+func (e *eventImpl) WheelDelta() (dx, dy int) {
+	return e.shared.wheelDX, e.shared.wheelDY
+}
+
+func (e *eventImpl) Scroll() (x, y, maxX, maxY int) {
+	return e.shared.scrollX, e.shared.scrollY, e.shared.scrollMaxX, e.shared.scrollMaxY
+}
+
 func (e *eventImpl) MouseBtn() MouseBtn {
 	return e.shared.mbtn
 }
@@ -390,6 +766,22 @@ func (e *eventImpl) KeyCode() Key {
 	return e.shared.keyCode
 }
 
+func (e *eventImpl) KeyText() string {
+	return e.shared.keyText
+}
+
+func (e *eventImpl) KeyRepeat() bool {
+	return e.shared.keyRep
+}
+
+func (e *eventImpl) ClipboardText() string {
+	return e.shared.clip
+}
+
+func (e *eventImpl) Selection() (start, end int) {
+	return e.selStart, e.selEnd
+}
+
 func (e *eventImpl) ReloadWin(name string) {
 	e.shared.reload = true
 	e.shared.reloadWin = name
@@ -448,6 +840,160 @@ func (e *eventImpl) SetFocusedComp(comp Comp) {
 	e.shared.focusedComp = comp
 }
 
+func (e *eventImpl) ShowPopup(content, anchor Comp, placement Placement) {
+	if p := popupOf(anchor); p != nil {
+		p.show(content, anchor, placement)
+		e.MarkDirty(p)
+	}
+}
+
+func (e *eventImpl) ClosePopup() {
+	if p := popupOf(e.src); p != nil && p.content != nil {
+		p.close()
+		e.MarkDirty(p)
+	}
+}
+
+func (e *eventImpl) FocusFirst(c Comp) Comp {
+	return e.focusFirst(c, focusable)
+}
+
+func (e *eventImpl) FocusFirstInvalid(c Comp) Comp {
+	return e.focusFirst(c, func(c2 Comp) bool {
+		v, ok := c2.(interface{ Valid() bool })
+		return ok && !v.Valid() && focusable(c2)
+	})
+}
+
+// focusFirst sets the first visible descendant of c accepted by match
+// to be focused after processing the current event, and returns it.
+func (e *eventImpl) focusFirst(c Comp, match func(c Comp) bool) Comp {
+	if c2 := firstVisible(c, match); c2 != nil {
+		e.SetFocusedComp(c2)
+		return c2
+	}
+	return nil
+}
+
+// focusable tells if a component can be focused.
+func focusable(c Comp) bool {
+	he, ok := c.(HasEnabled)
+	return ok && he.Enabled()
+}
+
+// firstVisible returns the first visible component in the tree rooted at c
+// (in depth-first order) accepted by match. Descendants of invisible components,
+// of inactive tabs of tab panels and of collapsed expanders are not visited.
+func firstVisible(c Comp, match func(c Comp) bool) Comp {
+	if !c.Visible() {
+		return nil
+	}
+	if match(c) {
+		return c
+	}
+
+	var children []Comp
+	switch c2 := c.(type) {
+	case *tabPanelImpl:
+		children = []Comp{c2.tabBarImpl}
+		if c2.selected >= 0 && c2.selected < len(c2.comps) {
+			children = append(children, c2.comps[c2.selected])
+		}
+	case *expanderImpl:
+		children = []Comp{c2.header}
+		if c2.expanded {
+			children = append(children, c2.content)
+		}
+	case childrenContainer:
+		children = c2.childComps()
+	}
+	for _, child := range children {
+		if child == nil {
+			continue
+		}
+		if found := firstVisible(child, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func (e *eventImpl) SendFile(fileName, mimeType string, r io.Reader) {
+	e.shared.downloads = append(e.shared.downloads, e.shared.server.addDownload(fileName, mimeType, r))
+}
+
+func (e *eventImpl) Animate(comp Comp, name string, duration time.Duration) {
+	e.shared.animations = append(e.shared.animations, animation{comp: comp, name: name, duration: duration})
+}
+
+func (e *eventImpl) ShowToast(message string, isError bool) {
+	e.shared.toasts = append(e.shared.toasts, toast{message: message, isError: isError})
+}
+
+func (e *eventImpl) Announce(text string, polite bool) {
+	e.shared.announces = append(e.shared.announces, announce{text: text, polite: polite})
+}
+
+func (e *eventImpl) Mask(comp Comp, label string) {
+	e.shared.masks = append(e.shared.masks, mask{comp: comp, masked: true, label: label})
+}
+
+func (e *eventImpl) Unmask(comp Comp) {
+	e.shared.masks = append(e.shared.masks, mask{comp: comp})
+}
+
+func (e *eventImpl) SetTabBadge(count int) {
+	if count < 0 {
+		count = 0
+	}
+	e.shared.tabBadge = count
+}
+
+func (e *eventImpl) RequestAttention() {
+	e.shared.attention = true
+}
+
+func (e *eventImpl) OpenWindow(name, features string) {
+	e.shared.openWins = append(e.shared.openWins, openWin{name: name, features: features})
+}
+
+func (e *eventImpl) BroadcastDirty(comps ...Comp) {
+	own := windowOf(e.src)
+	for _, c := range comps {
+		w := windowOf(c)
+		if w == nil || w == own {
+			e.MarkDirty(c)
+			continue
+		}
+		w.addBroadcast(c)
+		found := false
+		for _, w2 := range e.shared.broadcast {
+			if w2 == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			e.shared.broadcast = append(e.shared.broadcast, w)
+		}
+	}
+}
+
+func (e *eventImpl) handleError(err error) {
+	server := e.shared.server
+	if server.logger != nil {
+		server.logger.Println("Event handler error:", err)
+	} else {
+		log.Println("Event handler error:", err)
+	}
+
+	if server.errorPresenter != nil {
+		server.errorPresenter(e, err)
+	} else {
+		e.ShowToast(err.Error(), true)
+	}
+}
+
 func (e *eventImpl) Session() Session {
 	return e.shared.session
 }
@@ -463,6 +1009,8 @@ func (e *eventImpl) RemoveSess() {
 func (e *eventImpl) forkEvent(etype EventType, src Comp) Event {
 	return &eventImpl{etype: etype, src: src, parent: e,
 		x: -1, y: -1, // Mouse coordinates are unknown in the new source component...
+		selStart: -1, selEnd: -1,
+		rx: -1, ry: -1, rw: -1, rh: -1, // ...and so is its rect
 		shared: e.shared}
 }
 
@@ -484,6 +1032,40 @@ func (hfw handlerFuncWrapper) HandleEvent(e Event) {
 	hfw.hf(e)
 }
 
+// guardedHandler wraps an event handler registered with a client-side predicate
+// (see Comp.AddEHandlerFuncIf()).
+type guardedHandler struct {
+	EventHandler        // The wrapped event handler
+	guard        string // JavaScript predicate deciding if the event is to be sent
+}
+
+// internalHandler wraps an internal event handler function registered by a component
+// (to itself or to its child components) to implement its own behavior.
+// Internal handlers are not copied when a component is cloned, the clones
+// register their own internal handlers.
+type internalHandler struct {
+	owner *compImpl     // The component which registered the handler
+	hf    func(e Event) // The handler function to be called as part of implementing the EventHandler interface
+}
+
+// HandleEvent forwards the call to the handler function.
+func (ih internalHandler) HandleEvent(e Event) {
+	ih.hf(e)
+}
+
+// Handler function wrapper for handler functions that may return an error.
+type handlerErrFuncWrapper struct {
+	hf func(e Event) error // The handler function to be called as part of implementing the EventHandler interface
+}
+
+// HandleEvent forwards the call to the handler function,
+// and handles the returned error if it's non-nil.
+func (hfw handlerErrFuncWrapper) HandleEvent(e Event) {
+	if err := hfw.hf(e); err != nil {
+		e.handleError(err)
+	}
+}
+
 // Empty Event Handler type.
 type emptyEventHandler int
 
@@ -17,6 +17,10 @@
 
 package gwu
 
+import (
+	"strconv"
+)
+
 // Expander interface defines a component which can show and hide
 // another component when clicked on the header.
 //
@@ -82,21 +86,46 @@ func NewExpander() Expander {
 }
 
 func (c *expanderImpl) Remove(c2 Comp) bool {
-	if c.content.Equals(c2) {
-		c2.setParent(nil)
+	if c.content != nil && c.content.Equals(c2) {
+		c2.onRemoved(c)
 		c.content = nil
 		return true
 	}
 
-	if c.header.Equals(c2) {
-		c2.setParent(nil)
-		c.header = nil
+	if c.header != nil && c.header.Equals(c2) {
+		c.removeHeader()
 		return true
 	}
 
 	return false
 }
 
+func (c *expanderImpl) childComps() []Comp {
+	var comps []Comp
+	if c.header != nil {
+		comps = append(comps, c.header)
+	}
+	if c.content != nil {
+		comps = append(comps, c.content)
+	}
+	return comps
+}
+
+func (c *expanderImpl) dispose() {
+	c.compImpl.dispose()
+	c.header, c.content = nil, nil
+}
+
+func (c *expanderImpl) childPath(id ID) string {
+	if c.header != nil && c.header.ID() == id {
+		return pathSeg(c.header, 0)
+	}
+	if c.content != nil && c.content.ID() == id {
+		return pathSeg(c.content, 1)
+	}
+	return ""
+}
+
 func (c *expanderImpl) ByID(id ID) Comp {
 	if c.id == id {
 		return c
@@ -127,13 +156,16 @@ func (c *expanderImpl) ByID(id ID) Comp {
 	return nil
 }
 
+func (c *expanderImpl) SetEnabledRecursive(enabled bool) {
+	setEnabledRecursive(c, enabled)
+}
+
 func (c *expanderImpl) Clear() {
 	if c.header != nil {
-		c.header.setParent(nil)
-		c.header = nil
+		c.removeHeader()
 	}
 	if c.content != nil {
-		c.content.setParent(nil)
+		c.content.onRemoved(c)
 		c.content = nil
 	}
 }
@@ -144,17 +176,33 @@ func (c *expanderImpl) Header() Comp {
 
 func (c *expanderImpl) SetHeader(header Comp) {
 	header.makeOrphan()
+	if c.header != nil {
+		c.removeHeader()
+	}
 	c.header = header
-	header.setParent(c)
-
-	// TODO would be nice to remove this internal handler func when the header is removed!
-	header.AddEHandlerFunc(func(e Event) {
+	setParent(header, c)
+	// Header is focusable, Enter and Space click it (see js.go)
+	header.SetAttr("role", "button")
+	header.SetAttr("tabindex", "0")
+	header.SetAttr("aria-expanded", strconv.FormatBool(c.expanded))
+
+	// This internal handler is removed when the header is removed (see onRemoved())
+	header.AddEHandler(internalHandler{c.base(), func(e Event) {
 		c.SetExpanded(!c.expanded)
 		e.MarkDirty(c)
 		if c.handlers[ETypeStateChange] != nil {
 			c.dispatchEvent(e.forkEvent(ETypeStateChange, c))
 		}
-	}, ETypeClick)
+	}}, ETypeClick)
+}
+
+// removeHeader removes the header, including its internal handler and attributes.
+func (c *expanderImpl) removeHeader() {
+	c.header.onRemoved(c)
+	c.header.SetAttr("role", "")
+	c.header.SetAttr("tabindex", "")
+	c.header.SetAttr("aria-expanded", "")
+	c.header = nil
 }
 
 func (c *expanderImpl) Content() Comp {
@@ -163,8 +211,11 @@ func (c *expanderImpl) Content() Comp {
 
 func (c *expanderImpl) SetContent(content Comp) {
 	content.makeOrphan()
+	if c.content != nil {
+		c.content.onRemoved(c)
+	}
 	c.content = content
-	content.setParent(c)
+	setParent(content, c)
 
 	c.contentFmt.Style().AddClass("gwu-Expander-Content").SetFullSize()
 }
@@ -192,6 +243,9 @@ func (c *expanderImpl) SetExpanded(expanded bool) {
 	}
 
 	c.expanded = expanded
+	if c.header != nil {
+		c.header.SetAttr("aria-expanded", strconv.FormatBool(expanded))
+	}
 }
 
 func (c *expanderImpl) HeaderFmt() CellFmt {
@@ -214,7 +268,8 @@ func (c *expanderImpl) Render(w Writer) {
 		c.header.Render(w)
 	}
 
-	if c.expanded && c.content != nil {
+	// Reports show the content of collapsed expanders too
+	if (c.expanded || reportMode(w)) && c.content != nil {
 		c.renderTr(w)
 		c.contentFmt.render(strTDOp, w)
 		c.content.Render(w)
@@ -0,0 +1,110 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// FilterBox component interface and implementation.
+
+package gwu
+
+// FilterFunc is a function which filters the items of a list at the server side
+// (e.g. by setting the values of a ListBox or the count of a VirtualList).
+// Components changed by the function have to be marked dirty.
+type FilterFunc func(e Event, filter string)
+
+// FilterBox interface defines a text box which filters the items of a target
+// component as the user types.
+//
+// By default filtering is done at the client side (without server round-trips),
+// by hiding the items whose text does not contain the filter text (case insensitive).
+// Items are the options of a ListBox, the rows of a (vertical) Panel or Table,
+// the cells of a horizontal Panel, and the child elements of other components.
+//
+// For large lists (which are not rendered as a whole, e.g. VirtualList) filtering
+// can be done at the server side by setting a filter function with SetFilterFunc().
+//
+// Default style class: "gwu-FilterBox"
+type FilterBox interface {
+	// FilterBox is a TextBox.
+	TextBox
+
+	// Target returns the target component whose items are filtered at the client side.
+	Target() Comp
+
+	// SetTarget sets the target component whose items are filtered at the client side.
+	// Pass nil to clear it.
+	SetTarget(target Comp)
+
+	// SetFilterFunc sets a function to filter at the server side:
+	// the function is called when the text of the filter box changes
+	// (the text is synchronized on each key stroke).
+	// If a filter function is set, client side filtering of the target is turned off.
+	SetFilterFunc(f FilterFunc)
+}
+
+// FilterBox implementation.
+type filterBoxImpl struct {
+	textBoxImpl // TextBox implementation
+
+	target     Comp       // Target component whose items are filtered at the client side
+	filterFunc FilterFunc // Function to filter at the server side
+	lastFilter string     // Filter text the filter function was last called with
+}
+
+var strSearch = []byte("search") // "search"
+
+// NewFilterBox creates a new FilterBox which filters the items
+// of the specified target component at the client side.
+func NewFilterBox(target Comp) FilterBox {
+	c := &filterBoxImpl{textBoxImpl: newTextBoxImpl(strEncURIThisV, "", false)}
+	c.Style().AddClass("gwu-FilterBox")
+	c.inputType = strSearch
+	c.SetTarget(target)
+	return c
+}
+
+func (c *filterBoxImpl) Target() Comp {
+	return c.target
+}
+
+func (c *filterBoxImpl) SetTarget(target Comp) {
+	c.target = target
+}
+
+func (c *filterBoxImpl) SetFilterFunc(f FilterFunc) {
+	if f != nil && c.filterFunc == nil {
+		c.AddSyncOnETypes(ETypeKeyUp)
+		handler := internalHandler{c.base(), func(e Event) {
+			if c.filterFunc != nil && c.text != c.lastFilter {
+				c.lastFilter = c.text
+				c.filterFunc(e, c.text)
+			}
+		}}
+		c.AddEHandler(handler, ETypeKeyUp)
+		c.AddEHandler(handler, ETypeChange)
+	}
+	c.filterFunc = f
+}
+
+var strFilterAttrOp = []byte(" " + attrFilter + `="`) // ` data-gwu-flt="`
+
+func (c *filterBoxImpl) Render(w Writer) {
+	c.renderInputOp(w)
+	// The ID of the target is written at render time, so the stable ID mapping of the writer applies to it
+	if c.target != nil && c.filterFunc == nil {
+		w.Write(strFilterAttrOp)
+		w.Writev(c.target.ID())
+		w.Write(strQuote)
+	}
+	c.renderInputCl(w)
+}
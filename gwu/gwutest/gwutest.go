@@ -0,0 +1,387 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package gwutest provides a test harness for Gowut UIs.
+
+A Tester serves a Gowut server in memory (without opening network connections),
+and simulates browser events against components, exactly the way the browser
+sends them. Dirty components and other client actions of the event responses
+are captured, and rendered HTML can be asserted on. Example:
+
+	func TestGreeting(t *testing.T) {
+		win := gwu.NewWindow("main", "Main")
+		tb := gwu.NewTextBox("")
+		btn := gwu.NewButton("Greet")
+		l := gwu.NewLabel("")
+		btn.AddEHandlerFunc(func(e gwu.Event) {
+			l.SetText("Hello " + tb.Text())
+			e.MarkDirty(l)
+		}, gwu.ETypeClick)
+		win.Add(tb)
+		win.Add(btn)
+		win.Add(l)
+
+		tr := gwutest.New(t, win)
+		tr.Type(tb, "Bob")
+		resp := tr.Click(btn)
+		if !resp.IsDirty(l) {
+			t.Error("Label not refreshed")
+		}
+		if html := gwutest.Render(l); !strings.Contains(html, "Hello Bob") {
+			t.Errorf("Unexpected label: %s", html)
+		}
+	}
+
+Windows of private sessions (created by session handlers) can be tested
+by creating the Tester with NewWithServer(), and calling Tester.Get()
+with the session creator window name first. The Tester keeps the session
+cookie just like a browser.
+*/
+package gwutest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/icza/gowut/gwu"
+)
+
+// Event response actions, must be kept in sync with the ones in package gwu.
+const (
+	eraNoAction = iota
+	eraReloadWin
+	eraDirtyComps
+	eraFocusComp
+	eraDownload
+	eraSwitchWin
+	eraAnimate
+	eraToast
+	eraAnnounce
+	eraSchedule
+	eraWinExpired
+	eraMask
+	eraTabBadge
+	eraAttention
+	eraOpenWin
+	eraBroadcast
+)
+
+// Tester is a test harness which serves a Gowut server in memory.
+type Tester struct {
+	tb      testing.TB     // Test to report errors to
+	server  gwu.Server     // The server under test
+	cookies []*http.Cookie // Cookies received from the server
+	wins    []gwu.Window   // Windows added to the Tester
+}
+
+// New creates a new Tester with a new server,
+// and adds the specified windows to the public session of the server.
+func New(tb testing.TB, wins ...gwu.Window) *Tester {
+	tb.Helper()
+
+	server := gwu.NewServer("", "localhost:0")
+	for _, win := range wins {
+		if err := server.AddWin(win); err != nil {
+			tb.Fatalf("Failed to add window %q: %v", win.Name(), err)
+		}
+	}
+	t := NewWithServer(tb, server)
+	t.wins = wins
+	return t
+}
+
+// NewWithServer creates a new Tester for the specified server.
+// The server does not need to be (and should not be) started.
+//
+// Events can be simulated on components of the listed windows of the
+// public session and the session of the Tester (see Window.SetListed()).
+func NewWithServer(tb testing.TB, server gwu.Server) *Tester {
+	return &Tester{tb: tb, server: server}
+}
+
+// Server returns the server under test.
+func (t *Tester) Server() gwu.Server {
+	return t.server
+}
+
+// Session returns the session of the Tester: the private session
+// if the server created one, else the public session.
+func (t *Tester) Session() gwu.Session {
+	return t.server.SessionFromRequest(t.newRequest("GET", t.server.AppPath(), nil))
+}
+
+// newRequest creates a new request, with the cookies of the Tester.
+func (t *Tester) newRequest(method, path string, form url.Values) *http.Request {
+	var r *http.Request
+	if form != nil {
+		r = httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		r = httptest.NewRequest(method, path, nil)
+	}
+	for _, c := range t.cookies {
+		r.AddCookie(c)
+	}
+	return r
+}
+
+// serve serves a request, and stores the cookies of the response.
+func (t *Tester) serve(r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	t.server.ServeHTTP(rec, r)
+
+	for _, c := range rec.Result().Cookies() {
+		for i, c2 := range t.cookies {
+			if c2.Name == c.Name {
+				t.cookies = append(t.cookies[:i], t.cookies[i+1:]...)
+				break
+			}
+		}
+		if c.MaxAge >= 0 {
+			t.cookies = append(t.cookies, c)
+		}
+	}
+	return rec
+}
+
+// Get gets the page of the window with the specified name (the full HTML document),
+// as the browser would when navigating to it. Pass an empty string to get the window list.
+// Getting a session creator window name creates a new private session.
+func (t *Tester) Get(winName string) string {
+	t.tb.Helper()
+
+	rec := t.serve(t.newRequest("GET", t.server.AppPath()+winName, nil))
+	if rec.Code != http.StatusOK {
+		t.tb.Fatalf("Getting window %q failed with status %d: %s", winName, rec.Code, rec.Body.String())
+	}
+	return rec.Body.String()
+}
+
+// Response is a parsed event response.
+type Response struct {
+	Raw       string   // Raw response body
+	Dirty     []gwu.ID // IDs of the dirty components to be re-rendered
+	Focused   gwu.ID   // ID of the component to be focused, 0 if none
+	Reload    bool     // Tells if a window is to be reloaded (or switched to)
+	ReloadWin string   // Name of the window to be reloaded, empty for the current window
+	Toasts    []string // Messages of the toasts to be shown
+	TabBadge  int      // Count of the tab badge to be set, -1 if not to be changed
+	Attention bool     // Tells if the attention of the user is requested
+	Broadcast []string // Names of the windows to be refreshed in other browser tabs
+	Expired   bool     // Tells if the window has expired
+
+	win gwu.Window // Window of the event
+}
+
+// IsDirty tells if the specified component is to be re-rendered
+// (because it or one of its ancestors was marked dirty).
+// Components of other windows are never dirty (component IDs are only unique within a window).
+func (r *Response) IsDirty(c gwu.Comp) bool {
+	if r.win != nil && !r.win.Equals(rootOf(c)) {
+		return false
+	}
+	return r.isDirty(c)
+}
+
+// isDirty tells if the specified component or one of its ancestors was marked dirty.
+func (r *Response) isDirty(c gwu.Comp) bool {
+	for _, id := range r.Dirty {
+		if c.ID() == id {
+			return true
+		}
+	}
+	if parent := c.Parent(); parent != nil {
+		return r.isDirty(parent)
+	}
+	return false
+}
+
+// parseResponse parses an event response.
+func parseResponse(raw string) *Response {
+	resp := &Response{Raw: raw, TabBadge: -1}
+	for _, action := range strings.Split(raw, ";") {
+		n := strings.Split(action, ",")
+		era, err := strconv.Atoi(n[0])
+		if err != nil {
+			continue
+		}
+		switch era {
+		case eraDirtyComps:
+			for _, s := range n[1:] {
+				if id, err := gwu.AtoID(s); err == nil {
+					resp.Dirty = append(resp.Dirty, id)
+				}
+			}
+		case eraFocusComp:
+			if len(n) > 1 {
+				resp.Focused, _ = gwu.AtoID(n[1])
+			}
+		case eraReloadWin, eraSwitchWin:
+			resp.Reload = true
+			if len(n) > 1 {
+				resp.ReloadWin = n[1]
+			}
+		case eraToast:
+			if len(n) > 2 {
+				msg, _ := url.PathUnescape(n[2])
+				resp.Toasts = append(resp.Toasts, msg)
+			}
+		case eraTabBadge:
+			if len(n) > 1 {
+				resp.TabBadge, _ = strconv.Atoi(n[1])
+			}
+		case eraAttention:
+			resp.Attention = true
+		case eraBroadcast:
+			// Entries are "id:name" pairs
+			for _, s := range n[1:] {
+				if i := strings.IndexByte(s, ':'); i >= 0 {
+					name, _ := url.PathUnescape(s[i+1:])
+					resp.Broadcast = append(resp.Broadcast, name)
+				}
+			}
+		case eraWinExpired:
+			resp.Expired = true
+		}
+	}
+	return resp
+}
+
+// rootOf returns the root of the component tree the component is in.
+func rootOf(c gwu.Comp) gwu.Comp {
+	for parent := c.Parent(); parent != nil; parent = parent.Parent() {
+		c = parent
+	}
+	return c
+}
+
+// windowOf returns the window the component is added to (the component itself if it is a window).
+func (t *Tester) windowOf(c gwu.Comp) gwu.Window {
+	win, _ := rootOf(c).(gwu.Window)
+	return win
+}
+
+// Fire simulates an event of the specified type originating from the specified component.
+// value is the value of the component as sent by the browser (e.g. the text of a TextBox);
+// pass an empty string if the component has no value.
+func (t *Tester) Fire(c gwu.Comp, etype gwu.EventType, value string) *Response {
+	t.tb.Helper()
+	return t.fire(c, etype, value, value != "")
+}
+
+// fire simulates an event. hasValue tells if the component value is to be sent
+// (an empty string might be a valid value, e.g. the text of a TextBox).
+func (t *Tester) fire(c gwu.Comp, etype gwu.EventType, value string, hasValue bool) *Response {
+	t.tb.Helper()
+
+	form := url.Values{}
+	if hasValue {
+		form.Set("cval", value)
+	}
+	return t.fireForm(c, etype, form)
+}
+
+// fireForm fires an event with the specified additional form parameters.
+func (t *Tester) fireForm(c gwu.Comp, etype gwu.EventType, form url.Values) *Response {
+	t.tb.Helper()
+
+	win := t.windowOf(c)
+	if win == nil {
+		t.tb.Fatalf("Component %v is not added to a window of the Tester", c.ID())
+	}
+
+	form.Set("et", strconv.Itoa(int(etype)))
+	form.Set("cid", c.ID().String())
+
+	rec := t.serve(t.newRequest("POST", t.server.AppPath()+win.Name()+"/e", form))
+	if rec.Code != http.StatusOK {
+		t.tb.Fatalf("Event failed with status %d: %s", rec.Code, rec.Body.String())
+	}
+	resp := parseResponse(rec.Body.String())
+	resp.win = win
+	return resp
+}
+
+// Scroll simulates the user scrolling the specified component
+// to the specified position, and the ETypeScroll event being fired.
+func (t *Tester) Scroll(c gwu.Comp, x, y int) *Response {
+	t.tb.Helper()
+
+	form := url.Values{}
+	form.Set("sx", strconv.Itoa(x))
+	form.Set("sy", strconv.Itoa(y))
+	return t.fireForm(c, gwu.ETypeScroll, form)
+}
+
+// Paste simulates the user pasting the specified text into the specified
+// component, and the ETypePaste event being fired.
+// Like in browsers, the text of the component is not changed.
+func (t *Tester) Paste(c gwu.Comp, text string) *Response {
+	t.tb.Helper()
+
+	form := url.Values{}
+	form.Set("cb", text)
+	return t.fireForm(c, gwu.ETypePaste, form)
+}
+
+// Click simulates a click on the specified component.
+// The state of state buttons (e.g. CheckBox) and switch buttons is toggled
+// the way the browser does it.
+func (t *Tester) Click(c gwu.Comp) *Response {
+	t.tb.Helper()
+
+	value := ""
+	switch b := c.(type) {
+	case gwu.RadioButton:
+		value = "true"
+	case gwu.StateButton:
+		value = strconv.FormatBool(!b.State())
+	case gwu.SwitchButton:
+		value = strconv.FormatBool(!b.State())
+	}
+	return t.Fire(c, gwu.ETypeClick, value)
+}
+
+// Type simulates the user typing the specified text into a text box
+// (replacing its current text), and the change event being fired.
+func (t *Tester) Type(tb gwu.TextBox, text string) *Response {
+	t.tb.Helper()
+	return t.fire(tb, gwu.ETypeChange, text, true)
+}
+
+// Select simulates the user selecting the specified indices in a list box
+// (replacing the current selection), and the change event being fired.
+func (t *Tester) Select(lb gwu.ListBox, indices ...int) *Response {
+	t.tb.Helper()
+
+	s := make([]string, len(indices))
+	for i, idx := range indices {
+		s[i] = strconv.Itoa(idx)
+	}
+	return t.fire(lb, gwu.ETypeChange, strings.Join(s, ","), true)
+}
+
+// Render renders the specified component, and returns its HTML.
+func Render(c gwu.Comp) string {
+	buf := &bytes.Buffer{}
+	c.Render(gwu.NewWriter(buf))
+	return buf.String()
+}
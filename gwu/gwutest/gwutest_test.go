@@ -0,0 +1,788 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwutest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/icza/gowut/gwu"
+)
+
+func TestTester(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	tb := gwu.NewTextBox("")
+	cb := gwu.NewCheckBox("Loud")
+	btn := gwu.NewButton("Greet")
+	l := gwu.NewLabel("")
+	btn.AddEHandlerFunc(func(e gwu.Event) {
+		greeting := "Hello " + tb.Text()
+		if cb.State() {
+			greeting += "!"
+		}
+		l.SetText(greeting)
+		e.MarkDirty(l)
+	}, gwu.ETypeClick)
+	win.Add(tb)
+	win.Add(cb)
+	win.Add(btn)
+	win.Add(l)
+
+	tr := New(t, win)
+	if page := tr.Get("main"); !strings.Contains(page, "<title>Main</title>") {
+		t.Errorf("Unexpected page: %s", page)
+	}
+
+	tr.Type(tb, "Bob")
+	tr.Click(cb)
+	resp := tr.Click(btn)
+	if !resp.IsDirty(l) {
+		t.Errorf("Label not dirty, response: %q", resp.Raw)
+	}
+	if resp.IsDirty(tb) {
+		t.Errorf("TextBox is dirty, response: %q", resp.Raw)
+	}
+	if html := Render(l); !strings.Contains(html, "Hello Bob!") {
+		t.Errorf("Unexpected label: %s", html)
+	}
+
+	tr.Type(tb, "")
+	if tb.Text() != "" {
+		t.Errorf("Expected empty text, got: %q", tb.Text())
+	}
+}
+
+// TestEraSync tests that the event response action constants
+// are in sync with the ones of package gwu.
+func TestEraSync(t *testing.T) {
+	tr := New(t)
+	rec := tr.serve(tr.newRequest("GET", tr.server.AppPath()+"_gwu_static/gowut-"+gwu.GowutVersion+".js", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Failed to get static JS: %d", rec.Code)
+	}
+	js := rec.Body.String()
+
+	eras := map[string]int{
+		"NoAction": eraNoAction, "ReloadWin": eraReloadWin, "DirtyComps": eraDirtyComps,
+		"FocusComp": eraFocusComp, "Download": eraDownload, "SwitchWin": eraSwitchWin,
+		"Animate": eraAnimate, "Toast": eraToast, "Announce": eraAnnounce,
+		"Schedule": eraSchedule, "WinExpired": eraWinExpired,
+	}
+	for name, value := range eras {
+		if s := fmt.Sprintf("_era%s=%d", name, value); !strings.Contains(js, s) {
+			t.Errorf("Event response action out of sync: %s", s)
+		}
+	}
+}
+
+func TestEventRateLimit(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	btn := gwu.NewButton("Login")
+	clicks := 0
+	btn.AddEHandlerFunc(func(e gwu.Event) { clicks++ }, gwu.ETypeClick)
+	win.Add(btn)
+
+	tr := New(t, win)
+	violations := 0
+	tr.Server().SetEventRateLimit(2, time.Hour, func(e gwu.Event) {
+		violations++
+		e.ShowToast("Too many attempts", true)
+	})
+
+	var resp *Response
+	for i := 0; i < 3; i++ {
+		resp = tr.Click(btn)
+	}
+	if clicks != 2 || violations != 1 {
+		t.Errorf("Got clicks: %d, violations: %d, want: 2, 1", clicks, violations)
+	}
+	if len(resp.Toasts) != 1 {
+		t.Errorf("Violation toast not sent, response: %q", resp.Raw)
+	}
+
+	// Component override
+	btn.SetEventRateLimit(5, time.Hour)
+	tr.Click(btn)
+	if clicks != 3 {
+		t.Errorf("Got clicks: %d, want: 3", clicks)
+	}
+}
+
+func TestPageCache(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	win.SetPageCache(true)
+	btn := gwu.NewButton("Count")
+	l := gwu.NewLabel("0")
+	btn.AddEHandlerFunc(func(e gwu.Event) {
+		l.SetText("1")
+		e.MarkDirty(l)
+	}, gwu.ETypeClick)
+	win.Add(btn)
+	win.Add(l)
+
+	tr := New(t, win)
+	tr.Get("main")
+	l.SetText("changed") // Not detected without an event
+	if page := tr.Get("main"); strings.Contains(page, "changed") {
+		t.Error("Page not served from the cache")
+	}
+	tr.Click(btn)
+	if page := tr.Get("main"); !strings.Contains(page, ">1<") {
+		t.Errorf("Cache not invalidated: %s", page)
+	}
+}
+
+func TestMaxSessionComps(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	btn := gwu.NewButton("Add")
+	btn.AddEHandlerFunc(func(e gwu.Event) {
+		win.Add(gwu.NewLabel("x"))
+		e.MarkDirty(win)
+	}, gwu.ETypeClick)
+	win.Add(btn)
+
+	tr := New(t, win)
+	var got gwu.SessionStats
+	tr.Server().SetMaxSessionComps(3, func(sess gwu.Session, stats gwu.SessionStats) {
+		got = stats
+	})
+	tr.Click(btn)
+	if got.Comps != 0 {
+		t.Errorf("Limit exceeded too early: %+v", got)
+	}
+	tr.Click(btn)
+	if want := (gwu.SessionStats{Wins: 1, Comps: 4, Handlers: 1}); got.Wins != want.Wins || got.Comps != want.Comps || got.Handlers != want.Handlers {
+		t.Errorf("Got: %+v, want: %+v", got, want)
+	}
+}
+
+func TestTextBoxMaxLength(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	tb := gwu.NewTextBox("")
+	tb.SetMaxLength(3)
+	tb.EnableCounter("")
+	win.Add(tb)
+
+	tr := New(t, win)
+	if html := Render(tb); !strings.Contains(html, `data-gwu-cnt=""`) {
+		t.Errorf("Counter not rendered: %s", html)
+	}
+	tr.Type(tb, "héllo")
+	if text := tb.Text(); text != "hél" {
+		t.Errorf("Got: %q, want: %q", text, "hél")
+	}
+}
+
+func TestTransferList(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	tl := gwu.NewTransferList([]string{"a", "b", "c"})
+	changes := 0
+	tl.AddEHandlerFunc(func(e gwu.Event) { changes++ }, gwu.ETypeChange)
+	win.Add(tl)
+	tr := New(t, win)
+
+	btns := tl.CompAt(1).(gwu.Panel)
+	tr.Select(tl.AvailableBox(), 2, 0)
+	if resp := tr.Click(btns.CompAt(0)); !resp.IsDirty(tl) {
+		t.Errorf("TransferList not dirty, response: %q", resp.Raw)
+	}
+	if got := strings.Join(tl.Chosen(), ","); got != "a,c" {
+		t.Errorf("Got chosen: %s, want: a,c", got)
+	}
+	tr.Click(btns.CompAt(3)) // Remove all
+	tr.Click(btns.CompAt(3)) // No change
+	if got := len(tl.Chosen()); got != 0 {
+		t.Errorf("Got %d chosen values, want: 0", got)
+	}
+	if changes != 2 {
+		t.Errorf("Got %d change events, want: 2", changes)
+	}
+}
+
+func TestCheckBoxList(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	cbl := gwu.NewCheckBoxList([]string{"a", "b", "c"})
+	changes := 0
+	cbl.AddEHandlerFunc(func(e gwu.Event) { changes++ }, gwu.ETypeChange)
+	win.Add(cbl)
+	tr := New(t, win)
+
+	tr.Click(cbl.CheckBoxAt(0))
+	tr.Click(cbl.CheckBoxAt(2))
+	if got := strings.Join(cbl.SelectedValues(), ","); got != "a,c" {
+		t.Errorf("Got selected: %s, want: a,c", got)
+	}
+	if changes != 2 {
+		t.Errorf("Got %d change events, want: 2", changes)
+	}
+
+	cbl.SetSelectedValues([]string{"b"})
+	if got := strings.Join(cbl.SelectedValues(), ","); got != "b" {
+		t.Errorf("Got selected: %s, want: b", got)
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	cal := gwu.NewCalendar()
+	cal.SetMonth(2024, time.February)
+	cal.SetDayFunc(func(day time.Time) gwu.CalendarDay {
+		return gwu.CalendarDay{Disabled: day.Weekday() == time.Sunday}
+	})
+	changes, navs := 0, 0
+	cal.AddEHandlerFunc(func(e gwu.Event) { changes++ }, gwu.ETypeChange)
+	cal.AddEHandlerFunc(func(e gwu.Event) { navs++ }, gwu.ETypeStateChange)
+	win.Add(cal)
+	tr := New(t, win)
+
+	html := Render(cal)
+	// February 2024 starts on Thursday, first displayed day is Monday, January 29
+	if !strings.Contains(html, `data-gwu-cv="2024-01-29"`) || strings.Contains(html, `data-gwu-cv="2024-02-04"`) {
+		t.Errorf("Unexpected days: %s", html)
+	}
+
+	tr.Fire(cal, gwu.ETypeClick, "2024-02-04") // Sunday, disabled
+	if !cal.Selected().IsZero() || changes != 0 {
+		t.Error("Disabled day selected")
+	}
+	if resp := tr.Fire(cal, gwu.ETypeClick, "2024-02-14"); !resp.IsDirty(cal) {
+		t.Errorf("Calendar not dirty, response: %q", resp.Raw)
+	}
+	if got := cal.Selected().Format("2006-01-02"); got != "2024-02-14" || changes != 1 {
+		t.Errorf("Got selected: %s, changes: %d", got, changes)
+	}
+
+	tr.Fire(cal, gwu.ETypeClick, "n")
+	if m := cal.Month(); m.Month() != time.March || navs != 1 {
+		t.Errorf("Got month: %v, navigations: %d", m, navs)
+	}
+}
+
+func TestTimeAndDurationBox(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	tb := gwu.NewTimeBox()
+	db := gwu.NewDurationBox(90 * time.Minute)
+	win.Add(tb)
+	win.Add(db)
+	tr := New(t, win)
+
+	if html := Render(tb); !strings.Contains(html, `type="time"`) {
+		t.Errorf("Unexpected time box: %s", html)
+	}
+	tr.Fire(tb, gwu.ETypeChange, "13:45")
+	if h, m := tb.HourMin(); h != 13 || m != 45 {
+		t.Errorf("Got: %d:%d, want: 13:45", h, m)
+	}
+	tr.Fire(tb, gwu.ETypeChange, "bad")
+	if !tb.Time().IsZero() {
+		t.Errorf("Got time: %v, want zero", tb.Time())
+	}
+
+	if db.Text() != "1h30m" {
+		t.Errorf("Got: %q, want: %q", db.Text(), "1h30m")
+	}
+	if resp := tr.Type(db, "2x"); !resp.IsDirty(db) || db.Valid() || db.Duration() != 0 {
+		t.Errorf("Invalid duration accepted, response: %q", resp.Raw)
+	}
+	if resp := tr.Type(db, "45s"); !resp.IsDirty(db) || !db.Valid() || db.Duration() != 45*time.Second {
+		t.Errorf("Valid duration not accepted, response: %q", resp.Raw)
+	}
+}
+
+func TestRepeater(t *testing.T) {
+	names := []string{"a", "b", "c"}
+
+	win := gwu.NewWindow("main", "Main")
+	row := gwu.NewHorizontalPanel()
+	row.Add(gwu.NewLabel(""))
+	del := gwu.NewButton("Delete")
+	row.Add(del)
+	var rep gwu.Repeater
+	del.AddEHandlerFunc(func(e gwu.Event) {
+		i := rep.IdxOf(e.Src())
+		names = append(names[:i], names[i+1:]...)
+		rep.SetCount(len(names))
+		e.MarkDirty(rep)
+	}, gwu.ETypeClick)
+	rep = gwu.NewRepeater(row, func(item gwu.Comp, idx int) {
+		item.(gwu.Panel).CompAt(0).(gwu.Label).SetText(names[idx])
+	})
+	rep.SetCount(len(names))
+	win.Add(rep)
+	tr := New(t, win)
+
+	if rep.Count() != 3 {
+		t.Fatalf("Got %d items, want: 3", rep.Count())
+	}
+	tr.Click(rep.ItemAt(1).(gwu.Panel).CompAt(1))
+	if rep.Count() != 2 {
+		t.Fatalf("Got %d items, want: 2", rep.Count())
+	}
+	for i, want := range []string{"a", "c"} {
+		if got := rep.ItemAt(i).(gwu.Panel).CompAt(0).(gwu.Label).Text(); got != want {
+			t.Errorf("Item %d: got: %s, want: %s", i, got, want)
+		}
+	}
+}
+
+func TestVirtualList(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	vl := gwu.NewVirtualList(10000, func(idx int) gwu.Comp {
+		return gwu.NewLabel(fmt.Sprint("Row ", idx))
+	})
+	win.Add(vl)
+	tr := New(t, win)
+
+	if first, end := vl.RenderedRange(); first != 0 || end != 30 {
+		t.Errorf("Got range: %d-%d, want: 0-30", first, end)
+	}
+	if resp := tr.Scroll(vl, 0, 5*24); resp.IsDirty(vl) {
+		t.Errorf("Virtual list dirty though visible rows are rendered, response: %q", resp.Raw)
+	}
+	if resp := tr.Scroll(vl, 0, 5000*24); !resp.IsDirty(vl) {
+		t.Errorf("Virtual list not dirty, response: %q", resp.Raw)
+	}
+	if first, end := vl.RenderedRange(); first != 4990 || end != 5020 {
+		t.Errorf("Got range: %d-%d, want: 4990-5020", first, end)
+	}
+	if html := Render(vl); !strings.Contains(html, "Row 5000") || strings.Contains(html, "Row 0<") {
+		t.Errorf("Unexpected rows rendered")
+	}
+}
+
+func TestFilterBox(t *testing.T) {
+	values := []string{"apple", "banana", "cherry"}
+
+	win := gwu.NewWindow("main", "Main")
+	lb := gwu.NewListBox(values)
+	fb := gwu.NewFilterBox(lb)
+	win.Add(fb)
+	win.Add(lb)
+	tr := New(t, win)
+
+	if html := Render(fb); !strings.Contains(html, `data-gwu-flt="`+lb.ID().String()+`"`) {
+		t.Errorf("Filter target not rendered: %s", html)
+	}
+
+	var filtered []string
+	fb.SetFilterFunc(func(e gwu.Event, filter string) {
+		filtered = filtered[:0]
+		for _, v := range values {
+			if strings.Contains(v, filter) {
+				filtered = append(filtered, v)
+			}
+		}
+		lb.SetValues(filtered)
+		e.MarkDirty(lb)
+	})
+	if html := Render(fb); strings.Contains(html, "data-gwu-flt") {
+		t.Errorf("Client side filtering not turned off: %s", html)
+	}
+	if resp := tr.Fire(fb, gwu.ETypeKeyUp, "an"); !resp.IsDirty(lb) || len(filtered) != 1 || filtered[0] != "banana" {
+		t.Errorf("Got: %v, want: [banana], response: %q", filtered, resp.Raw)
+	}
+}
+
+func TestPaste(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	tb := gwu.NewTextBox("")
+	lb := gwu.NewListBox(nil)
+	tb.AddEHandlerFunc(func(e gwu.Event) {
+		lb.SetValues(strings.Split(e.ClipboardText(), ","))
+		e.MarkDirty(lb)
+	}, gwu.ETypePaste)
+	win.Add(tb)
+	win.Add(lb)
+	tr := New(t, win)
+
+	if html := Render(tb); !strings.Contains(html, "onpaste=") {
+		t.Errorf("Paste handler not rendered: %s", html)
+	}
+	if resp := tr.Paste(tb, "a,b,c"); !resp.IsDirty(lb) || len(lb.Values()) != 3 {
+		t.Errorf("Got values: %v, want: [a b c], response: %q", lb.Values(), resp.Raw)
+	}
+}
+
+func TestFocusFirst(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	form := gwu.NewPanel()
+	form.SetFocusTrap(true)
+	hidden := gwu.NewTextBox("")
+	hidden.SetVisible(false)
+	disabled := gwu.NewTextBox("")
+	disabled.SetEnabled(false)
+	name := gwu.NewTextBox("")
+	dur := gwu.NewDurationBox(0)
+	form.Add(gwu.NewLabel("Name:"))
+	form.Add(hidden)
+	form.Add(disabled)
+	form.Add(name)
+	form.Add(dur)
+	win.Add(form)
+	open := gwu.NewButton("Open")
+	var focused gwu.Comp
+	open.AddEHandlerFunc(func(e gwu.Event) {
+		focused = e.FocusFirst(form)
+	}, gwu.ETypeClick)
+	save := gwu.NewButton("Save")
+	save.AddEHandlerFunc(func(e gwu.Event) {
+		focused = e.FocusFirstInvalid(form)
+	}, gwu.ETypeClick)
+	win.Add(open)
+	win.Add(save)
+	tr := New(t, win)
+
+	if html := Render(form); !strings.Contains(html, `data-gwu-ft="1"`) {
+		t.Errorf("Focus trap not rendered: %s", html)
+	}
+	if resp := tr.Click(open); focused != name || resp.Focused != name.ID() {
+		t.Errorf("Got focused: %v, want: %v", resp.Focused, name.ID())
+	}
+	if resp := tr.Click(save); focused != nil || resp.Focused != 0 {
+		t.Errorf("Got focused: %v, want: none", resp.Focused)
+	}
+	tr.Type(dur, "bad")
+	if resp := tr.Click(save); focused != dur || resp.Focused != dur.ID() {
+		t.Errorf("Got focused: %v, want: %v", resp.Focused, dur.ID())
+	}
+}
+
+func TestEventCoords(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	b := gwu.NewButton("Menu")
+	var e2 gwu.Event
+	b.AddEHandlerFunc(func(e gwu.Event) {
+		e2 = e
+	}, gwu.ETypeClick)
+	win.Add(b)
+	tr := New(t, win)
+
+	form := url.Values{}
+	for name, value := range map[string]string{"mx": "5", "my": "6", "mwx": "15", "mwy": "16",
+		"mpx": "15", "mpy": "216", "rx": "10", "ry": "-10", "rw": "80", "rh": "20"} {
+		form.Set(name, value)
+	}
+	tr.fireForm(b, gwu.ETypeClick, form)
+	if x, y := e2.MousePage(); x != 15 || y != 216 {
+		t.Errorf("Got page coords: %d,%d, want: 15,216", x, y)
+	}
+	if x, y, w, h := e2.SrcRect(); x != 10 || y != -10 || w != 80 || h != 20 {
+		t.Errorf("Got rect: %d,%d %dx%d, want: 10,-10 80x20", x, y, w, h)
+	}
+
+	tr.Click(b)
+	if x, y, w, h := e2.SrcRect(); x != -1 || y != -1 || w != -1 || h != -1 {
+		t.Errorf("Got rect: %d,%d %dx%d, want: unknown", x, y, w, h)
+	}
+}
+
+func TestPopup(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	menuBtn := gwu.NewButton("Menu")
+	menu := gwu.NewPanel()
+	item := gwu.NewButton("Item")
+	menu.Add(item)
+	clicked := false
+	item.AddEHandlerFunc(func(e gwu.Event) {
+		clicked = true
+		e.ClosePopup()
+	}, gwu.ETypeClick)
+	menuBtn.AddEHandlerFunc(func(e gwu.Event) {
+		e.ShowPopup(menu, menuBtn, gwu.PlacementBelow)
+	}, gwu.ETypeClick)
+	win.Add(menuBtn)
+	tr := New(t, win)
+
+	if resp := tr.Click(menuBtn); menu.Parent() == nil || !resp.IsDirty(menu) {
+		t.Fatalf("Popup not shown, response: %q", resp.Raw)
+	}
+	if doc := tr.Get("main"); !strings.Contains(doc, `data-gwu-pa="`+menuBtn.ID().String()+`"`) {
+		t.Errorf("Popup not rendered: %s", doc)
+	}
+	tr.Click(item)
+	if !clicked || menu.Parent() != nil {
+		t.Errorf("Popup item not clicked or popup not closed")
+	}
+
+	// Closing by clicking outside of the popup
+	tr.Click(menuBtn)
+	tr.Fire(menu.Parent(), gwu.ETypeBlur, "")
+	if menu.Parent() != nil {
+		t.Errorf("Popup not closed")
+	}
+}
+
+// TestTabBadge tests Event.SetTabBadge() and Event.RequestAttention().
+func TestTabBadge(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	b := gwu.NewButton("Notify")
+	b.AddEHandlerFunc(func(e gwu.Event) {
+		e.SetTabBadge(3)
+		e.RequestAttention()
+	}, gwu.ETypeClick)
+	win.Add(b)
+	tr := New(t, win)
+
+	if resp := tr.Click(b); resp.TabBadge != 3 || !resp.Attention {
+		t.Errorf("Tab badge or attention not sent, response: %q", resp.Raw)
+	}
+	if resp := tr.Fire(win, gwu.ETypeClick, ""); resp.TabBadge != -1 || resp.Attention {
+		t.Errorf("Unexpected tab badge or attention, response: %q", resp.Raw)
+	}
+}
+
+// TestBroadcastDirty tests Event.OpenWindow() and Event.BroadcastDirty().
+func TestBroadcastDirty(t *testing.T) {
+	win, palette := gwu.NewWindow("main", "Main"), gwu.NewWindow("palette", "Palette")
+	own, other := gwu.NewLabel("own"), gwu.NewLabel("other")
+	win.Add(own)
+	palette.Add(other)
+	b := gwu.NewButton("Update")
+	b.AddEHandlerFunc(func(e gwu.Event) {
+		other.SetText("changed")
+		e.OpenWindow("palette", "width=300")
+		e.BroadcastDirty(own, other)
+	}, gwu.ETypeClick)
+	win.Add(b)
+	tr := New(t, win, palette)
+
+	resp := tr.Click(b)
+	if !resp.IsDirty(own) || resp.IsDirty(other) {
+		t.Errorf("Unexpected dirty components: %v", resp.Dirty)
+	}
+	if len(resp.Broadcast) != 1 || resp.Broadcast[0] != "palette" {
+		t.Errorf("Expected broadcast to %q, got: %q", "palette", resp.Broadcast)
+	}
+	if !strings.Contains(resp.Raw, ",palette,width=300") {
+		t.Errorf("Window not opened, response: %q", resp.Raw)
+	}
+
+	// The browser tab of the palette fetches the changes
+	if resp := tr.Fire(palette, gwu.ETypeStateChange, "b"); !resp.IsDirty(other) {
+		t.Errorf("Broadcast component not dirty, response: %q", resp.Raw)
+	}
+	if resp := tr.Fire(palette, gwu.ETypeStateChange, "b"); resp.IsDirty(other) {
+		t.Errorf("Broadcast component dirty again, response: %q", resp.Raw)
+	}
+}
+
+// TestFallbackEHandler tests Window.SetFallbackEHandler().
+func TestFallbackEHandler(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	tb := gwu.NewTextBox("")
+	b := gwu.NewButton("Save")
+	b.AddEHandlerFunc(func(e gwu.Event) {}, gwu.ETypeClick)
+	win.Add(tb)
+	win.Add(b)
+	var srcs []gwu.Comp
+	win.SetFallbackEHandler(handlerFunc(func(e gwu.Event) {
+		srcs = append(srcs, e.Src())
+	}))
+	tr := New(t, win)
+
+	tr.Type(tb, "changed")
+	tr.Click(b)
+	if len(srcs) != 1 || srcs[0] != tb {
+		t.Errorf("Got fallback handler sources: %v, want: [%v]", srcs, tb)
+	}
+}
+
+// handlerFunc is an event handler function.
+type handlerFunc func(e gwu.Event)
+
+func (hf handlerFunc) HandleEvent(e gwu.Event) {
+	hf(e)
+}
+
+// TestEventAuditor tests Server.SetEventAuditor().
+func TestEventAuditor(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	b := gwu.NewButton("Save")
+	b.AddEHandlerFunc(func(e gwu.Event) {}, gwu.ETypeClick)
+	win.Add(b)
+	tr := New(t, win)
+	var audited []string
+	tr.Server().SetEventAuditor(func(sess gwu.Session, win gwu.Window, comp gwu.Comp, etype gwu.EventType, dur time.Duration) {
+		audited = append(audited, win.Name()+"/"+comp.ID().String()+"/"+etype.String())
+		if dur < 0 {
+			t.Errorf("Negative duration: %v", dur)
+		}
+	})
+
+	tr.Click(b)
+	if want := "main/" + b.ID().String() + "/" + gwu.ETypeClick.String(); len(audited) != 1 || audited[0] != want {
+		t.Errorf("Got audited: %v, want: [%s]", audited, want)
+	}
+}
+
+// TestPermissionPredicates tests Comp.SetVisibleIf() and HasEnabled.SetEnabledIf().
+func TestPermissionPredicates(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	p := gwu.NewPanel()
+	del := gwu.NewButton("Delete")
+	p.Add(del)
+	save := gwu.NewButton("Save")
+	win.Add(p)
+	win.Add(save)
+	var clicked []gwu.Comp
+	hf := func(e gwu.Event) { clicked = append(clicked, e.Src()) }
+	del.AddEHandlerFunc(hf, gwu.ETypeClick)
+	save.AddEHandlerFunc(hf, gwu.ETypeClick)
+	isAdmin := func(sess gwu.Session) bool { return sess != nil && sess.Attr("admin") == true }
+	p.SetVisibleIf(isAdmin)
+	save.SetEnabledIf(isAdmin)
+	tr := New(t, win)
+	hidden := `class="gwu-Panel" style="display:none`
+	disabled := `,` + save.ID().String() + `)" disabled="disabled"`
+
+	page := tr.Get("main")
+	if !strings.Contains(page, hidden) || !strings.Contains(page, disabled) {
+		t.Errorf("Expected hidden panel and disabled button, got: %s", page)
+	}
+	tr.Click(del)
+	tr.Click(save)
+	if len(clicked) != 0 {
+		t.Errorf("Events of refused components were handled: %v", clicked)
+	}
+
+	tr.Session().SetAttr("admin", true)
+	page = tr.Get("main")
+	if strings.Contains(page, hidden) || strings.Contains(page, disabled) {
+		t.Errorf("Expected visible panel and enabled button, got: %s", page)
+	}
+	tr.Click(del)
+	tr.Click(save)
+	if len(clicked) != 2 {
+		t.Errorf("Got handled sources: %v, want: [%v %v]", clicked, del, save)
+	}
+}
+
+// TestRoles tests Session roles and HasAnyRole().
+func TestRoles(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	b := gwu.NewButton("Delete")
+	clicks := 0
+	b.AddEHandlerFunc(func(e gwu.Event) { clicks++ }, gwu.ETypeClick)
+	b.SetEnabledIf(gwu.HasAnyRole("admin", "owner"))
+	win.Add(b)
+	tr := New(t, win)
+	sess := tr.Session()
+
+	roles := []string{"user", "owner"}
+	sess.SetRoles(roles)
+	roles[1] = "guest"
+	if !sess.HasRole("owner") || sess.HasRole("guest") || sess.HasRole("admin") {
+		t.Errorf("Got roles: %v, want: [user owner]", sess.Roles())
+	}
+	tr.Click(b)
+	sess.SetRoles(nil)
+	tr.Click(b)
+	if clicks != 1 {
+		t.Errorf("Got clicks: %d, want: 1", clicks)
+	}
+	if gwu.HasAnyRole("admin")(nil) {
+		t.Errorf("Nil session must not have roles")
+	}
+}
+
+// TestBindLabel tests BindLabel().
+func TestBindLabel(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	l := gwu.NewLabel("")
+	var fetchErr error
+	n := 0
+	b := gwu.BindLabel(l, func() (string, error) {
+		if fetchErr != nil {
+			return "", fetchErr
+		}
+		n++
+		return fmt.Sprint("count: ", n), nil
+	}, time.Minute)
+	win.Add(b)
+	tr := New(t, win)
+	if l.Text() != "count: 1" || b.Err() != nil {
+		t.Errorf("Got text: %q, error: %v, want: %q, nil", l.Text(), b.Err(), "count: 1")
+	}
+
+	// Refresh ticks schedule the fetch as a task of the window
+	tr.Fire(b.Timer(), gwu.ETypeStateChange, "")
+	if l.Text() != "count: 1" {
+		t.Errorf("Fetched before the scheduled task: %q", l.Text())
+	}
+	if r := tr.Fire(win, gwu.ETypeStateChange, "1"); !r.IsDirty(l) || l.Text() != "count: 2" {
+		t.Errorf("Got text: %q, dirty: %v, want: %q, true", l.Text(), r.IsDirty(l), "count: 2")
+	}
+
+	fetchErr = errors.New("backend down")
+	tr.Fire(b.Timer(), gwu.ETypeStateChange, "")
+	tr.Fire(win, gwu.ETypeStateChange, "2")
+	if l.Text() != "count: 2" || b.Err() != fetchErr {
+		t.Errorf("Got text: %q, error: %v, want: %q, %v", l.Text(), b.Err(), "count: 2", fetchErr)
+	}
+}
+
+// TestTwoFactorBox tests TwoFactorBox.
+func TestTwoFactorBox(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	tfb := gwu.NewTwoFactorBox()
+	var codes []string
+	tfb.AddEHandlerFunc(func(e gwu.Event) { codes = append(codes, tfb.Code()) }, gwu.ETypeChange)
+	win.Add(tfb)
+	tr := New(t, win)
+
+	if r := Render(tfb); strings.Count(r, "<input") != 6 || !strings.Contains(r, "tfaCode(this)") {
+		t.Errorf("Unexpected rendering: %s", r)
+	}
+	tr.Fire(tfb, gwu.ETypeChange, "123456")
+	tr.Fire(tfb, gwu.ETypeChange, "12a456")
+	if len(codes) != 2 || codes[0] != "123456" || codes[1] != "" {
+		t.Errorf("Got codes: %q, want: [123456 \"\"]", codes)
+	}
+}
+
+// TestIdleMonitor tests IdleMonitor.
+func TestIdleMonitor(t *testing.T) {
+	win := gwu.NewWindow("main", "Main")
+	im := gwu.NewIdleMonitor(15 * time.Minute)
+	im.SetWarning(time.Minute)
+	im.SetWarningText(`Signing out in {s} "seconds"`)
+	im.AddEHandlerFunc(func(e gwu.Event) {
+		e.RemoveSess()
+		e.ReloadWin("login")
+	}, gwu.ETypeStateChange)
+	win.Add(im)
+	tr := New(t, win)
+
+	r := Render(im)
+	for _, s := range []string{`data-gwu-idle="900"`, `data-gwu-iw="60"`, `data-gwu-it="Signing out in {s} &#34;seconds&#34;"`} {
+		if !strings.Contains(r, s) {
+			t.Errorf("Rendering does not contain %q: %s", s, r)
+		}
+	}
+	im.SetActive(false)
+	if r := Render(im); strings.Contains(r, "data-gwu-idle") {
+		t.Errorf("Inactive monitor is monitored: %s", r)
+	}
+
+	if resp := tr.Fire(im, gwu.ETypeStateChange, ""); !resp.Reload || resp.ReloadWin != "login" {
+		t.Errorf("Got reload: %v, %q, want: true, %q", resp.Reload, resp.ReloadWin, "login")
+	}
+}
@@ -45,12 +45,80 @@ func AtoID(s string) (ID, error) {
 }
 
 // Component ID generation and provider
+//
+// Component IDs are allocated by windows: when a component is added to a window
+// (directly or by adding it to a container of a window), the component and its
+// descendants get IDs from the ID generator of the window. So IDs are unique within
+// a window, and they don't grow with the number of windows, sessions and servers.
+//
+// Components not in a window have provisional IDs. These are negative and unique
+// in the process, so they never collide with IDs allocated by windows.
 
-// Last used value for ID
-var lastID int64
+// idGen is a component ID generator.
+// It is safe for concurrent use.
+type idGen struct {
+	last int64 // Last used value for ID
+}
 
-// nextCompID returns a unique component ID
+// next returns the next unique ID of the generator.
 // First ID given is 1.
-func nextCompID() ID {
-	return ID(atomic.AddInt64(&lastID, 1))
+func (g *idGen) next() ID {
+	return ID(atomic.AddInt64(&g.last, 1))
+}
+
+// freeIDGen is the generator of the provisional IDs of components
+// not added to a window.
+var freeIDGen idGen
+
+// nextFreeID returns a unique provisional component ID.
+// First ID given is -1.
+func nextFreeID() ID {
+	return -freeIDGen.next()
+}
+
+// setParent sets the parent of a component, and allocates IDs to the component
+// and its descendants from the ID space of the parent: IDs of the window of the parent,
+// or provisional IDs if the parent is not in a window.
+func setParent(c Comp, parent Container) {
+	c.setParent(parent)
+	allocIDs(c, windowOf(parent))
+}
+
+// allocIDs allocates IDs of the window w (provisional IDs if w is nil) to the specified
+// component and its descendants not having such IDs yet.
+// Cell formatters of containers in the subtree stored by the previous IDs are updated.
+func allocIDs(c Comp, w *windowImpl) {
+	next := nextFreeID
+	if w != nil {
+		next = w.ids.next
+	}
+
+	var remap map[ID]ID // New IDs mapped from previous IDs
+	Walk(c, func(c2 Comp) bool {
+		b := baseOf(c2)
+		if b == nil || b.idWin == w {
+			return true
+		}
+		if remap == nil {
+			remap = make(map[ID]ID)
+		}
+		id := next()
+		remap[b.id] = id
+		b.id, b.idWin = id, w
+		b.attrs["id"] = id.String()
+		if ea, ok := c2.(interface{ allocExtraIDs(next func() ID) }); ok {
+			ea.allocExtraIDs(next)
+		}
+		return true
+	})
+	if remap == nil {
+		return
+	}
+
+	Walk(c, func(c2 Comp) bool {
+		if r, ok := c2.(interface{ remapIDs(remap map[ID]ID) }); ok {
+			r.remapIDs(remap)
+		}
+		return true
+	})
 }
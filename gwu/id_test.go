@@ -0,0 +1,255 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestIDGenConcurrent tests that an ID generator hands out unique IDs
+// when used concurrently.
+func TestIDGenConcurrent(t *testing.T) {
+	const workers, perWorker = 8, 1000
+
+	var g idGen
+	ids := make(chan ID, workers*perWorker)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				ids <- g.next()
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[ID]bool, workers*perWorker)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("Duplicate ID: %v", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != workers*perWorker {
+		t.Errorf("Expected %d IDs, got: %d", workers*perWorker, len(seen))
+	}
+	for id := ID(1); id <= workers*perWorker; id++ {
+		if !seen[id] {
+			t.Errorf("Missing ID: %v", id)
+		}
+	}
+}
+
+// TestCompCreationConcurrent tests that components created concurrently
+// (e.g. by multiple servers or sessions) get unique provisional IDs.
+func TestCompCreationConcurrent(t *testing.T) {
+	const workers, perWorker = 8, 200
+
+	comps := make(chan Comp, workers*perWorker)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				// SwitchButton allocates multiple IDs internally
+				if j%2 == 0 {
+					comps <- NewLabel("")
+				} else {
+					comps <- NewSwitchButton()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(comps)
+
+	seen := make(map[ID]bool, workers*perWorker)
+	for c := range comps {
+		if seen[c.ID()] {
+			t.Fatalf("Duplicate component ID: %v", c.ID())
+		}
+		seen[c.ID()] = true
+		if c.ID() >= 0 {
+			t.Errorf("Expected negative provisional ID, got: %v", c.ID())
+		}
+		if c.Attr("id") != c.ID().String() {
+			t.Errorf("Expected id attribute %q, got: %q", c.ID().String(), c.Attr("id"))
+		}
+	}
+}
+
+// winIDs returns the IDs of the components of a window, including the extra IDs
+// of the components (e.g. the IDs of the input tags of check boxes).
+func winIDs(w Window) []ID {
+	ids := []ID{w.(*windowImpl).popup.ID()}
+	Walk(w, func(c Comp) bool {
+		ids = append(ids, c.ID())
+		switch c2 := c.(type) {
+		case *stateButtonImpl:
+			ids = append(ids, c2.inputID)
+		case *switchButtonImpl:
+			ids = append(ids, c2.onButton.ID(), c2.offButton.ID())
+		}
+		return true
+	})
+	return ids
+}
+
+// TestWinIDsConcurrent tests that windows built concurrently
+// (e.g. by multiple servers or sessions) allocate IDs independently:
+// IDs are unique within a window, and each window starts from 1.
+func TestWinIDsConcurrent(t *testing.T) {
+	const workers, perWorker = 8, 50
+
+	wins := make(chan Window, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			win := NewWindow("main", "Main")
+			for j := 0; j < perWorker; j++ {
+				p := NewPanel()
+				switch j % 3 {
+				case 0:
+					p.Add(NewLabel(""))
+				case 1:
+					p.Add(NewCheckBox(""))
+				default:
+					p.Add(NewSwitchButton())
+				}
+				win.Add(p)
+			}
+			wins <- win
+		}()
+	}
+	wg.Wait()
+	close(wins)
+
+	var first []ID
+	for win := range wins {
+		ids := winIDs(win)
+		seen := make(map[ID]bool, len(ids))
+		for _, id := range ids {
+			if seen[id] {
+				t.Fatalf("Duplicate ID in window: %v", id)
+			}
+			seen[id] = true
+			if id < 1 || int(id) > len(ids) {
+				t.Errorf("Expected ID in range [1..%d], got: %v", len(ids), id)
+			}
+		}
+		if win.ID() != 1 {
+			t.Errorf("Expected window ID 1, got: %v", win.ID())
+		}
+		if first == nil {
+			first = ids
+		} else if len(ids) != len(first) {
+			t.Errorf("Expected %d IDs, got: %d", len(first), len(ids))
+		}
+	}
+}
+
+// TestMoveBetweenWins tests that moving a component tree to another window
+// (or to a container not in a window) allocates new IDs in the new place,
+// keeping the cell formatters and internal event handlers.
+func TestMoveBetweenWins(t *testing.T) {
+	win1, win2 := NewWindow("w1", "W1"), NewWindow("w2", "W2")
+	win2.Add(NewLabel("other"))
+
+	p := NewPanel()
+	l := NewLabel("label")
+	p.Add(l)
+	p.CellFmt(l).Style().SetWidthPx(10)
+	e := NewExpander()
+	header := NewLabel("header")
+	e.SetHeader(header)
+	p.Add(e)
+
+	checkIn := func(win Window) {
+		t.Helper()
+		for _, c := range []Comp{p, l, e, header} {
+			if c.ID() < 1 {
+				t.Errorf("Expected window ID, got: %v", c.ID())
+			}
+			if c.Attr("id") != c.ID().String() {
+				t.Errorf("Expected id attribute %q, got: %q", c.ID().String(), c.Attr("id"))
+			}
+			if found := win.ByID(c.ID()); found == nil || !found.Equals(c) {
+				t.Errorf("Component %v not found by ID", c.ID())
+			}
+		}
+		if p.CellFmt(l).Style().Width() != "10px" {
+			t.Errorf("Cell formatter lost")
+		}
+	}
+
+	win1.Add(p)
+	checkIn(win1)
+
+	win2.Add(p)
+	checkIn(win2)
+	if win1.CompIdx(p) >= 0 {
+		t.Errorf("Panel not removed from previous window")
+	}
+
+	detached := NewPanel()
+	detached.Add(p)
+	for _, c := range []Comp{p, l, e, header} {
+		if c.ID() >= 0 {
+			t.Errorf("Expected provisional ID, got: %v", c.ID())
+		}
+	}
+	if p.CellFmt(l).Style().Width() != "10px" {
+		t.Errorf("Cell formatter lost")
+	}
+
+	// Internal handlers registered by the expander are removed with the header
+	if len(header.(*labelImpl).handlers[ETypeClick]) != 1 {
+		t.Errorf("Internal handler of the expander not registered")
+	}
+	e.SetHeader(NewLabel("header2"))
+	if len(header.(*labelImpl).handlers[ETypeClick]) != 0 {
+		t.Errorf("Internal handler of the expander not removed from the old header")
+	}
+}
+
+// TestEquals tests that Equals compares component identities,
+// not IDs (which are only unique within a window).
+func TestEquals(t *testing.T) {
+	win1, win2 := NewWindow("w1", "W1"), NewWindow("w2", "W2")
+	l1, l2 := NewLabel("1"), NewLabel("2")
+	win1.Add(l1)
+	win2.Add(l2)
+
+	if l1.ID() != l2.ID() {
+		t.Fatalf("Expected same IDs in different windows, got: %v, %v", l1.ID(), l2.ID())
+	}
+	if l1.Equals(l2) || win1.Equals(win2) {
+		t.Errorf("Different components are equal")
+	}
+	if !l1.Equals(l1) || !win1.Equals(win1) {
+		t.Errorf("Component is not equal to itself")
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// IdleMonitor component interface and implementation.
+
+package gwu
+
+import (
+	"html"
+	"strconv"
+	"time"
+)
+
+// IdleMonitor interface defines a component which tracks the activity
+// of the user (mouse, keyboard, touch and scrolling) at the client side,
+// and generates an ETypeStateChange event when the user has been idle
+// for the specified timeout. The event is generated once per idle period.
+//
+// The monitor can show a countdown dialog before the timeout (see SetWarning());
+// any activity of the user dismisses the dialog and restarts the idle period.
+//
+// This is distinct from the session timeout: a session does not expire while
+// the window generates events (e.g. by timers), but the user may still be away.
+// Typical use is to log out idle users:
+//
+//	im := gwu.NewIdleMonitor(15 * time.Minute)
+//	im.SetWarning(time.Minute)
+//	im.AddEHandlerFunc(func(e gwu.Event) {
+//		e.RemoveSess()
+//		e.ReloadWin("login")
+//	}, gwu.ETypeStateChange)
+//	win.Add(im)
+//
+// Default style classes: "gwu-IdleMonitor", "gwu-IdleMonitor-Dialog"
+type IdleMonitor interface {
+	// IdleMonitor is a component.
+	Comp
+
+	// Timeout returns the idle period after which the event is generated.
+	Timeout() time.Duration
+
+	// SetTimeout sets the idle period after which the event is generated.
+	// Implementation uses second precision.
+	SetTimeout(timeout time.Duration)
+
+	// Warning returns the period before the timeout during which the countdown dialog is shown.
+	Warning() time.Duration
+
+	// SetWarning sets the period before the timeout during which the countdown dialog is shown.
+	// Pass 0 to not show the countdown dialog. This is the default.
+	SetWarning(warning time.Duration)
+
+	// WarningText returns the text of the countdown dialog.
+	WarningText() string
+
+	// SetWarningText sets the text of the countdown dialog.
+	// "{s}" in the text is replaced with the remaining seconds.
+	SetWarningText(text string)
+
+	// Active tells if the idle monitor is active.
+	Active() bool
+
+	// SetActive sets if the idle monitor is active.
+	// If the idle monitor is not active, events are not generated.
+	SetActive(active bool)
+}
+
+// IdleMonitor implementation.
+type idleMonitorImpl struct {
+	compImpl // Component implementation
+
+	timeout  time.Duration // Idle period after which the event is generated
+	warning  time.Duration // Period before the timeout during which the countdown dialog is shown
+	warnText string        // Text of the countdown dialog
+	active   bool          // Tells if the idle monitor is active
+}
+
+// NewIdleMonitor creates a new IdleMonitor with the specified idle timeout.
+// By default the monitor is active, and shows no countdown dialog.
+func NewIdleMonitor(timeout time.Duration) IdleMonitor {
+	c := &idleMonitorImpl{compImpl: newCompImpl(nil), timeout: timeout, active: true,
+		warnText: "You will be signed out in {s} seconds due to inactivity. Move the mouse or press a key to stay signed in."}
+	c.Style().AddClass("gwu-IdleMonitor")
+	c.updateAttrs()
+	return c
+}
+
+func (c *idleMonitorImpl) Timeout() time.Duration {
+	return c.timeout
+}
+
+func (c *idleMonitorImpl) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+	c.updateAttrs()
+}
+
+func (c *idleMonitorImpl) Warning() time.Duration {
+	return c.warning
+}
+
+func (c *idleMonitorImpl) SetWarning(warning time.Duration) {
+	c.warning = warning
+	c.updateAttrs()
+}
+
+func (c *idleMonitorImpl) WarningText() string {
+	return c.warnText
+}
+
+func (c *idleMonitorImpl) SetWarningText(text string) {
+	c.warnText = text
+	c.updateAttrs()
+}
+
+func (c *idleMonitorImpl) Active() bool {
+	return c.active
+}
+
+func (c *idleMonitorImpl) SetActive(active bool) {
+	c.active = active
+	c.updateAttrs()
+}
+
+// updateAttrs updates the attributes telling the client the idle timeout and the warning.
+// The client only monitors components having the idle timeout attribute.
+func (c *idleMonitorImpl) updateAttrs() {
+	timeout, warning := "", ""
+	if c.active {
+		timeout = strconv.FormatInt(int64(c.timeout/time.Second), 10)
+		if c.warning > 0 {
+			warning = strconv.FormatInt(int64(c.warning/time.Second), 10)
+		}
+	}
+	c.SetAttr(attrIdle, timeout)
+	c.SetAttr(attrIdleWarn, warning)
+	if warning != "" {
+		c.SetAttr(attrIdleText, html.EscapeString(c.warnText))
+	} else {
+		c.SetAttr(attrIdleText, "")
+	}
+}
+
+func (c *idleMonitorImpl) Render(w Writer) {
+	w.Write(strSpanOp)
+	c.renderAttrsAndStyle(w)
+	c.renderEHandlers(w)
+	w.Write(strGT)
+	w.Write(strSpanCl)
+}
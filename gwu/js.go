@@ -37,11 +37,71 @@ func init() {
 		"',_pFocCompId='" + paramFocusedCompID +
 		"',_pMouseWX='" + paramMouseWX +
 		"',_pMouseWY='" + paramMouseWY +
+		"',_pMousePX='" + paramMousePX +
+		"',_pMousePY='" + paramMousePY +
+		"',_pSrcRectX='" + paramSrcRectX +
+		"',_pSrcRectY='" + paramSrcRectY +
+		"',_pSrcRectW='" + paramSrcRectW +
+		"',_pSrcRectH='" + paramSrcRectH +
 		"',_pMouseX='" + paramMouseX +
 		"',_pMouseY='" + paramMouseY +
 		"',_pMouseBtn='" + paramMouseBtn +
 		"',_pModKeys='" + paramModKeys +
 		"',_pKeyCode='" + paramKeyCode +
+		"',_pKeyText='" + paramKeyText +
+		"',_pKeyRepeat='" + paramKeyRepeat +
+		"',_pWheelDX='" + paramWheelDX +
+		"',_pWheelDY='" + paramWheelDY +
+		"',_pScrollX='" + paramScrollX +
+		"',_pScrollY='" + paramScrollY +
+		"',_pScrollMaxX='" + paramScrollMaxX +
+		"',_pScrollMaxY='" + paramScrollMaxY +
+		"',_pSelStart='" + paramSelStart +
+		"',_pSelEnd='" + paramSelEnd +
+		"',_pClipboard='" + paramClipboard +
+		"',_pDownloadToken='" + paramDownloadToken +
+		"',_pNoAccess='" + paramNoAccess +
+		"',_pDataPrefix='" + paramDataPrefix +
+		"',_pWinNonce='" + paramWinNonce +
+		"',_pTabId='" + paramTabID +
+		"';\n" +
+		// Window-relative path consts
+		"var _pathRelEvent='" + pathEvent +
+		"',_pathRelRenderComp='" + pathRenderComp +
+		"',_pathRelRenderWin='" + pathRenderWin +
+		"',_pathRelDownload='" + pathDownload +
+		"';\n" +
+		// Response header consts
+		"var _hdrWinTitle='" + headerWinTitle +
+		"',_hdrFocusCompId='" + headerFocusCompID +
+		"',_hdrWinNonce='" + headerWinNonce +
+		"',_hdrActions='" + headerActions +
+		"',_hdrNoAccess='" + headerNoAccess +
+		"';\n" +
+		// Attribute names
+		"var _attrPreserveState='" + attrPreserveState +
+		"',_attrPseudoCSS='" + attrPseudoCSS +
+		"',_attrSwipe='" + attrSwipe +
+		"',_attrLongPress='" + attrLongPress +
+		"',_attrDblClick='" + attrDblClick +
+		"',_attrJsURLs='" + attrJsURLs +
+		"',_attrInlineJS='" + attrInlineJS +
+		"',_attrMask='" + attrMask +
+		"',_attrSubmit='" + attrSubmit +
+		"',_attrCancel='" + attrCancel +
+		"',_attrSelection='" + attrSelection +
+		"',_attrCounter='" + attrCounter +
+		"',_attrCalValue='" + attrCalValue +
+		"',_attrVirtualList='" + attrVirtualList +
+		"',_attrFilter='" + attrFilter +
+		"',_attrFocusTrap='" + attrFocusTrap +
+		"',_attrResizable='" + attrResizable +
+		"',_attrTwoFactor='" + attrTwoFactor +
+		"',_attrIdle='" + attrIdle +
+		"',_attrIdleWarn='" + attrIdleWarn +
+		"',_attrIdleText='" + attrIdleText +
+		"',_attrPopupAnchor='" + attrPopupAnchor +
+		"',_attrPopupPlace='" + attrPopupPlace +
 		"';\n" +
 		// Modifier key masks
 		"var _modKeyAlt=" + strconv.Itoa(int(ModKeyAlt)) +
@@ -49,33 +109,85 @@ func init() {
 		",_modKeyMeta=" + strconv.Itoa(int(ModKeyMeta)) +
 		",_modKeyShift=" + strconv.Itoa(int(ModKeyShift)) +
 		";\n" +
+		// Event types synthesized at the client side
+		"var _etSwipeLeft=" + strconv.Itoa(int(ETypeSwipeLeft)) +
+		",_etSwipeRight=" + strconv.Itoa(int(ETypeSwipeRight)) +
+		",_etLongPress=" + strconv.Itoa(int(ETypeLongPress)) +
+		",_etDblClick=" + strconv.Itoa(int(ETypeDblClick)) +
+		",_etStateChange=" + strconv.Itoa(int(ETypeStateChange)) +
+		",_etScroll=" + strconv.Itoa(int(ETypeScroll)) +
+		",_etBlur=" + strconv.Itoa(int(ETypeBlur)) +
+		";\n" +
+		// Popup placements
+		"var _placeBelow=" + strconv.Itoa(int(PlacementBelow)) +
+		",_placeAbove=" + strconv.Itoa(int(PlacementAbove)) +
+		",_placeRight=" + strconv.Itoa(int(PlacementRight)) +
+		",_placeLeft=" + strconv.Itoa(int(PlacementLeft)) +
+		";\n" +
+		// Component value of the state change events of windows refreshed by a broadcast
+		"var _broadcastValue='" + broadcastValue + "';\n" +
 		// Event response action consts
 		"var _eraNoAction=" + strconv.Itoa(eraNoAction) +
 		",_eraReloadWin=" + strconv.Itoa(eraReloadWin) +
 		",_eraDirtyComps=" + strconv.Itoa(eraDirtyComps) +
 		",_eraFocusComp=" + strconv.Itoa(eraFocusComp) +
+		",_eraDownload=" + strconv.Itoa(eraDownload) +
+		",_eraSwitchWin=" + strconv.Itoa(eraSwitchWin) +
+		",_eraAnimate=" + strconv.Itoa(eraAnimate) +
+		",_eraToast=" + strconv.Itoa(eraToast) +
+		",_eraAnnounce=" + strconv.Itoa(eraAnnounce) +
+		",_eraSchedule=" + strconv.Itoa(eraSchedule) +
+		",_eraWinExpired=" + strconv.Itoa(eraWinExpired) +
+		",_eraMask=" + strconv.Itoa(eraMask) +
+		",_eraTabBadge=" + strconv.Itoa(eraTabBadge) +
+		",_eraAttention=" + strconv.Itoa(eraAttention) +
+		",_eraOpenWin=" + strconv.Itoa(eraOpenWin) +
+		",_eraBroadcast=" + strconv.Itoa(eraBroadcast) +
 		";" +
 		`
 
 function createXmlHttp() {
-	if (window.XMLHttpRequest) // IE7+, Firefox, Chrome, Opera, Safari
-		return new XMLHttpRequest();
-	else // IE6, IE5
-		return new ActiveXObject("Microsoft.XMLHTTP");
+	return new XMLHttpRequest();
 }
 
-// Send event
-function se(event, etype, compId, compValue) {
-	var xhr = createXmlHttp();
+// Queue of pending DOM updates. Updates are functions taking a done callback,
+// they are run one after the other in the order they were queued.
+var _updQueue = [];
+var _updRunning = false;
 
-	xhr.onreadystatechange = function() {
-		if (xhr.readyState == 4 && xhr.status == 200)
-			procEresp(xhr);
-	}
+// Queue a DOM update
+function queueUpdate(upd) {
+	_updQueue.push(upd);
+	if (!_updRunning)
+		runUpdates();
+}
 
-	xhr.open("POST", _pathEvent, true); // asynch call
-	xhr.setRequestHeader("Content-type", "application/x-www-form-urlencoded");
+// Run the queued DOM updates
+function runUpdates() {
+	if (_updQueue.length == 0) {
+		_updRunning = false;
+		return;
+	}
+	_updRunning = true;
+	var upd = _updQueue.shift();
+	var called = false;
+	var done = function() {
+		if (called)
+			return;
+		called = true;
+		runUpdates();
+	};
+	try {
+		upd(done);
+	} catch (err) {
+		done();
+		throw err;
+	}
+}
 
+// Send event
+// onDone is optional, if provided, it is called when the response arrives (or the request fails).
+function se(event, etype, compId, compValue, onDone) {
 	var data="";
 
 	if (etype != null)
@@ -86,43 +198,251 @@ function se(event, etype, compId, compValue) {
 		data += "&" + _pCompValue + "=" + compValue;
 	if (document.activeElement.id != null && document.activeElement.id !== "")
 		data += "&" + _pFocCompId + "=" + document.activeElement.id;
+	data += "&" + _pWinNonce + "=" + _winNonce;
+	if (_tabId)
+		data += "&" + _pTabId + "=" + _tabId;
+
+	var src = compId != null ? document.getElementById(compId) : null;
+	if (src) {
+		// Data attributes of the source (excluding internal ones)
+		for (var i = 0; i < src.attributes.length; i++) {
+			var a = src.attributes[i];
+			if (a.name.indexOf("data-") == 0 && a.name.indexOf("data-gwu-") != 0)
+				data += "&" + _pDataPrefix + a.name.substring(5) + "=" + encodeURIComponent(a.value);
+		}
+		// Bounding rect of the source, inside the page
+		var rect = src.getBoundingClientRect();
+		data += "&" + _pSrcRectX + "=" + Math.round(rect.left + window.pageXOffset);
+		data += "&" + _pSrcRectY + "=" + Math.round(rect.top + window.pageYOffset);
+		data += "&" + _pSrcRectW + "=" + Math.round(rect.width);
+		data += "&" + _pSrcRectH + "=" + Math.round(rect.height);
+		// Selection of text boxes, in runes
+		if (src.tagName == "INPUT" || src.tagName == "TEXTAREA") {
+			try {
+				if (typeof src.selectionStart == "number") {
+					data += "&" + _pSelStart + "=" + runeCount(src.value.substring(0, src.selectionStart));
+					data += "&" + _pSelEnd + "=" + runeCount(src.value.substring(0, src.selectionEnd));
+				}
+			} catch (err) {
+				// Some input types do not support selection
+			}
+		}
+	}
 
 	if (event != null) {
-		if (event.clientX != null) {
-			// Mouse data
-			var x = event.clientX, y = event.clientY;
-			// Account for the amount body is scrolled:
-			eventDoc = (event.target && event.target.ownerDocument) || document;
-			doc = eventDoc.documentElement;
-			body = eventDoc.body;
-			x += (doc && doc.scrollLeft || body && body.scrollLeft || 0) -
-				 (doc && doc.clientLeft || body && body.clientLeft || 0);
-			y += (doc && doc.scrollTop  || body && body.scrollTop  || 0) -
-				 (doc && doc.clientTop  || body && body.clientTop  || 0 );
-			data += "&" + _pMouseWX + "=" + x;
-			data += "&" + _pMouseWY + "=" + y;
-			var parent = document.getElementById(compId);
-			do {
-				x -= parent.offsetLeft;
-				y -= parent.offsetTop;
-			} while (parent = parent.offsetParent);
-			data += "&" + _pMouseX + "=" + x;
-			data += "&" + _pMouseY + "=" + y;
-			data += "&" + _pMouseBtn + "=" + (event.button < 4 ? event.button : 1); // IE8 and below uses 4 for middle btn
+		// For touch events use the first changed touch point
+		var pt = event.changedTouches && event.changedTouches.length > 0 ? event.changedTouches[0] : event;
+		if (pt.clientX != null) {
+			// Mouse data; bounding rects take CSS transforms and scrolled ancestors into account
+			var x = pt.clientX, y = pt.clientY;
+			data += "&" + _pMouseWX + "=" + Math.round(x);
+			data += "&" + _pMouseWY + "=" + Math.round(y);
+			data += "&" + _pMousePX + "=" + Math.round(x + window.pageXOffset);
+			data += "&" + _pMousePY + "=" + Math.round(y + window.pageYOffset);
+			if (src) {
+				data += "&" + _pMouseX + "=" + Math.round(x - rect.left);
+				data += "&" + _pMouseY + "=" + Math.round(y - rect.top);
+			}
+			if (event.button != null)
+				data += "&" + _pMouseBtn + "=" + (event.button < 4 ? event.button : 1); // IE8 and below uses 4 for middle btn
 		}
 
-		var modKeys;
+		var modKeys = 0;
 		modKeys += event.altKey ? _modKeyAlt : 0;
-		modKeys += event.ctlrKey ? _modKeyCtlr : 0;
+		modKeys += event.ctrlKey ? _modKeyCtlr : 0;
 		modKeys += event.metaKey ? _modKeyMeta : 0;
 		modKeys += event.shiftKey ? _modKeyShift : 0;
 		data += "&" + _pModKeys + "=" + modKeys;
 		data += "&" + _pKeyCode + "=" + (event.which ? event.which : event.keyCode);
+		if (event.deltaY != null) {
+			// Wheel event, normalize deltas to pixels (deltaMode: 0=pixel, 1=line, 2=page)
+			var m = event.deltaMode == 1 ? 16 : event.deltaMode == 2 ? window.innerHeight : 1;
+			data += "&" + _pWheelDX + "=" + Math.round(event.deltaX * m);
+			data += "&" + _pWheelDY + "=" + Math.round(event.deltaY * m);
+		}
+		if (event.type == "scroll" && src) {
+			data += "&" + _pScrollX + "=" + Math.round(src.scrollLeft);
+			data += "&" + _pScrollY + "=" + Math.round(src.scrollTop);
+			data += "&" + _pScrollMaxX + "=" + (src.scrollWidth - src.clientWidth);
+			data += "&" + _pScrollMaxY + "=" + (src.scrollHeight - src.clientHeight);
+		}
+		if (event.type == "cut" || event.type == "copy" || event.type == "paste") {
+			var cbText = clipboardText(event, src);
+			if (cbText != null)
+				data += "&" + _pClipboard + "=" + encodeURIComponent(cbText);
+		}
+		if (event.key != null) {
+			// Key event
+			data += "&" + _pKeyText + "=" + encodeURIComponent(event.key);
+			if (event.repeat)
+				data += "&" + _pKeyRepeat + "=1";
+		}
+	}
+
+	sendEvent(data, onDone);
+}
+
+// Returns the text of a cut, copy or paste event: the selected text being cut or copied,
+// or the text being pasted. Returns null if not available.
+function clipboardText(event, src) {
+	try {
+		if (event.type == "paste") {
+			var cd = event.clipboardData || window.clipboardData;
+			return cd ? cd.getData("text") : null;
+		}
+		// The clipboard is not yet written at cut and copy, send the selected text
+		if (src && (src.tagName == "INPUT" || src.tagName == "TEXTAREA") && typeof src.selectionStart == "number")
+			return src.value.substring(src.selectionStart, src.selectionEnd);
+		return window.getSelection ? window.getSelection().toString() : null;
+	} catch (err) {
+		return null; // Access denied
+	}
+}
+
+// Id of the browser tab, kept in the session storage so it survives page reloads
+var _tabId = (function() {
+	try {
+		var id = sessionStorage.getItem("gwu-tab-id");
+		if (!id) {
+			id = Math.random().toString(36).substring(2) + Date.now().toString(36);
+			sessionStorage.setItem("gwu-tab-id", id);
+		}
+		return id;
+	} catch (err) { // Session storage not available
+		return "";
 	}
+})();
 
+// Offline state: if the connection is lost, events are queued and sending them is retried
+var _offline = false;
+var _offlineQueue = [];
+var _retryDelay = 0;
+var _retryTimer = null;
+
+// Send the data of an event, or queue it if we're offline
+function sendEvent(data, onDone) {
+	if (_offline) {
+		_offlineQueue.push({data: data, onDone: onDone});
+		return;
+	}
+	postEvent(data, onDone, function() {
+		// Connection error
+		_offlineQueue.push({data: data, onDone: onDone});
+		goOffline();
+	});
+}
+
+// Post the data of an event. onFail is called if the server could not be reached.
+function postEvent(data, onDone, onFail) {
+	var xhr = createXmlHttp();
+
+	xhr.onreadystatechange = function() {
+		if (xhr.readyState != 4)
+			return;
+		if (xhr.status == 0) { // Status 0 means connection error
+			onFail();
+			return;
+		}
+		if (xhr.status == 200)
+			procEresp(xhr);
+		if (onDone)
+			onDone();
+	}
+
+	xhr.open("POST", _pathEvent, true); // asynch call
+	xhr.setRequestHeader("Content-type", "application/x-www-form-urlencoded");
 	xhr.send(data);
 }
 
+// Switch to offline state
+function goOffline() {
+	if (_offline)
+		return;
+	_offline = true;
+	document.body.classList.add("gwu-Offline");
+	if (_offlineText) {
+		var b = document.createElement("div");
+		b.id = "gwu-OfflineBanner";
+		b.className = "gwu-OfflineBanner";
+		b.textContent = _offlineText;
+		document.body.appendChild(b);
+	}
+	_retryDelay = 1000;
+	scheduleRetry();
+}
+
+// Schedule the next retry of sending the queued events, with exponential backoff
+function scheduleRetry() {
+	_retryTimer = setTimeout(retryOffline, _retryDelay);
+	_retryDelay = Math.min(_retryDelay * 2, 30000);
+}
+
+// Try to send the first queued event, and if succeeds, go back online
+function retryOffline() {
+	clearTimeout(_retryTimer);
+	_retryTimer = null;
+	var ev = _offlineQueue.shift();
+	if (!ev) {
+		goOnline();
+		return;
+	}
+	postEvent(ev.data, function() {
+		goOnline();
+		if (ev.onDone)
+			ev.onDone();
+	}, function() {
+		_offlineQueue.unshift(ev);
+		scheduleRetry();
+	});
+}
+
+// Switch back to online state: send the queued events and fire the reconnect event
+function goOnline() {
+	if (!_offline)
+		return;
+	_offline = false;
+	document.body.classList.remove("gwu-Offline");
+	var b = document.getElementById("gwu-OfflineBanner");
+	if (b)
+		b.parentNode.removeChild(b);
+
+	var queue = _offlineQueue;
+	_offlineQueue = [];
+	for (var i = 0; i < queue.length; i++)
+		sendEvent(queue[i].data, queue[i].onDone);
+
+	for (var i = 0; i < _reconnectFuncs.length; i++)
+		_reconnectFuncs[i]();
+}
+
+// Functions to call when the connection to the server is restored
+var _reconnectFuncs = [];
+function addonreconnect(func) {
+	_reconnectFuncs.push(func);
+}
+
+// Retry right away if the browser tells the network is available again
+window.addEventListener("online", function() {
+	if (_offline && _retryTimer != null)
+		retryOffline();
+});
+
+// Send event in single fire mode:
+// no new event is sent from the source component until the response arrives.
+function sesf(event, etype, compId, compValue) {
+	var e = document.getElementById(compId);
+	if (e) {
+		if (e.gwuBusy)
+			return;
+		e.gwuBusy = true;
+	}
+
+	se(event, etype, compId, compValue, function() {
+		if (e)
+			e.gwuBusy = false;
+	});
+}
+
 function procEresp(xhr) {
 	var actions = xhr.responseText.split(";");
 
@@ -130,46 +450,718 @@ function procEresp(xhr) {
 		window.alert("No response received!");
 		return;
 	}
-	for (var i = 0; i < actions.length; i++) {
-		var n = actions[i].split(",");
-
-		switch (parseInt(n[0])) {
-		case _eraDirtyComps:
-			for (var j = 1; j < n.length; j++)
-				rerenderComp(n[j]);
-			break;
-		case _eraFocusComp:
-			if (n.length > 1)
-				focusComp(parseInt(n[1]));
-			break;
-		case _eraNoAction:
-			break;
-		case _eraReloadWin:
-			if (n.length > 1 && n[1].length > 0)
-				window.location.href = _pathApp + n[1];
+	var noAccess = xhr.getResponseHeader(_hdrNoAccess) != null;
+	for (var i = 0; i < actions.length; i++)
+		queueAction(actions[i].split(","), noAccess);
+}
+
+// Queue an event response action, so it is run after the previous DOM updates completed.
+// noAccess tells if the event did not register an access to the session.
+function queueAction(n, noAccess) {
+	queueUpdate(function(done) {
+		runAction(n, done, noAccess);
+	});
+}
+
+// Run an event response action, and call done when it completes
+function runAction(n, done, noAccess) {
+	switch (parseInt(n[0])) {
+	case _eraDirtyComps:
+		rerenderComps(n.slice(1), done, noAccess);
+		return;
+	case _eraFocusComp:
+		if (n.length > 1)
+			focusComp(parseInt(n[1]));
+		break;
+	case _eraDownload:
+		if (n.length > 1)
+			download(n[1]);
+		break;
+	case _eraNoAction:
+		break;
+	case _eraReloadWin:
+		if (n.length > 1 && n[1].length > 0)
+			window.location.href = _pathApp + n[1];
+		else
+			window.location.reload(true); // force reload
+		break;
+	case _eraAnimate:
+		if (n.length > 3)
+			animate(n[1], n[2], parseInt(n[3]));
+		break;
+	case _eraSwitchWin:
+		if (n.length > 1)
+			switchWin(n[1], true);
+		break;
+	case _eraToast:
+		if (n.length > 2)
+			toast(decodeURIComponent(n[2]), n[1] == "true");
+		break;
+	case _eraSchedule:
+		if (n.length > 3)
+			schedule(n[1], n[2], parseInt(n[3]));
+		break;
+	case _eraAnnounce:
+		if (n.length > 2)
+			announce(decodeURIComponent(n[2]), n[1] == "true");
+		break;
+	case _eraMask:
+		if (n.length > 3) {
+			var e = document.getElementById(n[1]);
+			if (n[2] == "true")
+				mask(e, decodeURIComponent(n[3]));
 			else
-				window.location.reload(true); // force reload
-			break;
-		default:
-			window.alert("Unknown response code:" + n[0]);
-			break;
+				unmask(e);
 		}
+		break;
+	case _eraTabBadge:
+		if (n.length > 1)
+			setTabBadge(parseInt(n[1]));
+		break;
+	case _eraAttention:
+		requestAttention();
+		break;
+	case _eraOpenWin:
+		if (n.length > 2)
+			window.open(_pathApp + decodeURIComponent(n[1]), "gwu-" + decodeURIComponent(n[1]), decodeURIComponent(n[2]));
+		break;
+	case _eraBroadcast:
+		broadcast(n.slice(1));
+		break;
+	case _eraWinExpired:
+		// Page is stale (e.g. restored from the back-forward cache), reload the current window
+		window.location.reload(true);
+		return;
+	default:
+		window.alert("Unknown response code:" + n[0]);
+		break;
 	}
+	done();
 }
 
-function rerenderComp(compId) {
+// Run a CSS animation on a component
+function animate(compId, name, durationMs) {
 	var e = document.getElementById(compId);
 	if (!e) // Component removed or not visible (e.g. on inactive tab of TabPanel)
 		return;
 
+	e.style.animation = "none";
+	void e.offsetWidth; // Trigger reflow so the same animation can be restarted
+	e.style.animation = name + " " + durationMs + "ms forwards";
+}
+
+// Show a toast (notification message) which disappears automatically
+function toast(msg, isError) {
+	var c = document.getElementById("gwu-Toasts");
+	if (!c) {
+		c = document.createElement("div");
+		c.id = "gwu-Toasts";
+		c.className = "gwu-Toasts";
+		document.body.appendChild(c);
+	}
+
+	var t = document.createElement("div");
+	t.className = isError ? "gwu-Toast gwu-Toast-Error" : "gwu-Toast";
+	t.textContent = msg;
+	c.appendChild(t);
+	setTimeout(function() {
+		if (t.parentNode)
+			t.parentNode.removeChild(t);
+	}, 4000);
+}
+
+// Schedule a server-side task of a window
+var _tasks = {};
+function schedule(winId, taskId, delayMs) {
+	var key = winId + "-" + taskId;
+	if (_tasks[key])
+		return; // Already scheduled
+	_tasks[key] = true;
+	setTimeout(function() {
+		if (document.getElementById(winId)) // Only if the window is still displayed
+			se(null, _etStateChange, winId, taskId);
+	}, delayMs);
+}
+
+// Announce a text in a (visually hidden) ARIA live region
+function announce(text, polite) {
+	var id = polite ? "gwu-LiveRegion-Polite" : "gwu-LiveRegion-Assertive";
+	var r = document.getElementById(id);
+	if (!r) {
+		r = document.createElement("div");
+		r.id = id;
+		r.className = "gwu-LiveRegion";
+		r.setAttribute("aria-live", polite ? "polite" : "assertive");
+		r.setAttribute("aria-atomic", "true");
+		document.body.appendChild(r);
+	}
+
+	// Clear first and set the text a little later, so the same text is announced again
+	r.textContent = "";
+	setTimeout(function() {
+		r.textContent = text;
+	}, 100);
+}
+
+// Badge of the browser tab
+var _tabBadge = 0, _favicon = null;
+
+// Set the badge of the browser tab: prepend the count to the document title
+// and draw it over the favicon (count <= 0 removes the badge)
+function setTabBadge(count) {
+	_tabBadge = count > 0 ? count : 0;
+	document.title = badgeTitle(document.title);
+
+	var link = document.querySelector("link[rel~='icon']");
+	if (!link) {
+		link = document.createElement("link");
+		link.rel = "icon";
+		document.head.appendChild(link);
+	}
+	if (_favicon === null)
+		_favicon = link.getAttribute("href") || "";
+	if (_tabBadge == 0) {
+		if (_favicon)
+			link.href = _favicon;
+		else
+			link.removeAttribute("href");
+		return;
+	}
+
+	var canvas = document.createElement("canvas");
+	canvas.width = canvas.height = 32;
+	var ctx = canvas.getContext("2d");
+	if (!ctx)
+		return;
+	var drawBadge = function() {
+		ctx.fillStyle = "#d00";
+		ctx.beginPath();
+		ctx.arc(21, 11, 11, 0, 2 * Math.PI);
+		ctx.fill();
+		ctx.fillStyle = "white";
+		ctx.font = "bold 16px sans-serif";
+		ctx.textAlign = "center";
+		ctx.textBaseline = "middle";
+		ctx.fillText(_tabBadge > 99 ? "99" : String(_tabBadge), 21, 12);
+		link.href = canvas.toDataURL("image/png");
+	};
+	if (!_favicon) {
+		drawBadge();
+		return;
+	}
+	var img = new Image();
+	img.onload = function() {
+		ctx.drawImage(img, 0, 0, 32, 32);
+		drawBadge();
+	};
+	img.onerror = drawBadge;
+	img.src = _favicon;
+}
+
+// Return the specified title with the current tab badge count
+function badgeTitle(title) {
+	title = title.replace(/^\(\d+\) /, "");
+	return _tabBadge > 0 ? "(" + _tabBadge + ") " + title : title;
+}
+
+// Flash the document title until the browser tab gets focused
+var _attentionTimer = null;
+function requestAttention() {
+	if (_attentionTimer !== null || (!document.hidden && document.hasFocus()))
+		return;
+
+	var title = document.title, flash = false;
+	var stop = function() {
+		clearInterval(_attentionTimer);
+		_attentionTimer = null;
+		document.title = title;
+		window.removeEventListener("focus", stop);
+	};
+	_attentionTimer = setInterval(function() {
+		flash = !flash;
+		document.title = flash ? "\u2605 " + title : title;
+	}, 1000);
+	window.addEventListener("focus", stop);
+}
+
+// Channel to notify other browser windows (tabs) of the application
+var _channel = typeof BroadcastChannel !== "undefined" ? new BroadcastChannel("gwu-" + _pathApp) : null;
+if (_channel)
+	_channel.onmessage = function(m) {
+		refreshWins(m.data);
+	};
+
+// Notify other browser windows to refresh the specified windows ("id:name" entries)
+function broadcast(wins) {
+	if (_channel)
+		_channel.postMessage(wins);
+}
+
+// Fetch the changes of the specified windows ("id:name" entries) if they are displayed
+function refreshWins(wins) {
+	for (var i = 0; i < wins.length; i++) {
+		var j = wins[i].indexOf(":");
+		var winId = wins[i].substring(0, j);
+		if (decodeURIComponent(wins[i].substring(j + 1)) == _winName && document.getElementById(winId))
+			se(null, _etStateChange, winId, _broadcastValue);
+	}
+}
+
+// Cover an element with a mask (overlay with a spinner and an optional label)
+function mask(e, label) {
+	if (!e) // Component removed or not visible
+		return;
+	unmask(e);
+
+	var m = document.createElement("div");
+	m.className = "gwu-Mask";
+	var s = document.createElement("div");
+	s.className = "gwu-Mask-Spinner";
+	m.appendChild(s);
+	if (label) {
+		var l = document.createElement("div");
+		l.className = "gwu-Mask-Label";
+		l.textContent = label;
+		m.appendChild(l);
+	}
+	e.classList.add("gwu-Masked");
+	e.appendChild(m);
+}
+
+// Remove the mask of an element
+function unmask(e) {
+	if (!e)
+		return;
+	for (var i = e.children.length - 1; i >= 0; i--)
+		if (e.children[i].className == "gwu-Mask")
+			e.removeChild(e.children[i]);
+	e.classList.remove("gwu-Masked");
+}
+
+// Add the masks of an element and its descendants rendered as masked
+function applyMasks(root) {
+	if (!root)
+		return;
+
+	var elements = root.hasAttribute(_attrMask) ? [root] : [];
+	var descs = root.querySelectorAll("[" + _attrMask + "]");
+	for (var i = 0; i < descs.length; i++)
+		elements.push(descs[i]);
+	for (var i = 0; i < elements.length; i++)
+		mask(elements[i], elements[i].getAttribute(_attrMask));
+}
+
+// Returns the number of runes (code points) in a string
+function runeCount(s) {
+	var n = 0;
+	for (var i = 0; i < s.length; i++) {
+		var c = s.charCodeAt(i);
+		if (c < 0xDC00 || c > 0xDFFF) // Low surrogates do not start a new rune
+			n++;
+	}
+	return n;
+}
+
+// Returns the string index of the specified rune index (negative means the end)
+function runeIndex(s, r) {
+	if (r < 0)
+		return s.length;
+	var i = 0;
+	for (; i < s.length && r > 0; i++, r--) {
+		var c = s.charCodeAt(i);
+		if (c >= 0xD800 && c <= 0xDBFF && i + 1 < s.length)
+			i++; // High surrogate, skip the low surrogate too
+	}
+	return i;
+}
+
+// Apply the selections rendered to text boxes of an element and its descendants
+function applySelections(root) {
+	if (!root)
+		return;
+
+	var elements = root.hasAttribute(_attrSelection) ? [root] : [];
+	var descs = root.querySelectorAll("[" + _attrSelection + "]");
+	for (var i = 0; i < descs.length; i++)
+		elements.push(descs[i]);
+	for (var i = 0; i < elements.length; i++) {
+		var e = elements[i], sel = e.getAttribute(_attrSelection).split(",");
+		e.removeAttribute(_attrSelection); // Only applied once
+		try {
+			e.setSelectionRange(runeIndex(e.value, parseInt(sel[0])), runeIndex(e.value, parseInt(sel[1])));
+		} catch (err) {
+			// Some input types do not support selection
+		}
+	}
+}
+
+// Update the character counter of a text box (the counter is created after the text box if needed)
+function updateCounter(e) {
+	var c = document.getElementById(e.id + "_cnt");
+	if (!c) {
+		c = document.createElement("span");
+		c.id = e.id + "_cnt";
+		e.parentNode.insertBefore(c, e.nextSibling);
+	}
+	var n = runeCount(e.value), max = e.hasAttribute("maxlength") ? parseInt(e.getAttribute("maxlength")) : -1;
+	var f = e.getAttribute(_attrCounter) || (max >= 0 ? "{n}/{max}" : "{n}");
+	c.textContent = f.split("{n}").join(n).split("{max}").join(max >= 0 ? max : "").split("{left}").join(max >= 0 ? max - n : "");
+	c.className = "gwu-TextBox-Counter" + (max >= 0 && n >= max ? " gwu-TextBox-Counter-Full" : "");
+}
+
+// Apply the character counters of text boxes of an element and its descendants
+function applyCounters(root) {
+	if (!root)
+		return;
+
+	if (!root.hasAttribute(_attrCounter)) {
+		// Counter of a re-rendered text box might have been disabled
+		var c = document.getElementById(root.id + "_cnt");
+		if (c && c.previousSibling == root)
+			c.parentNode.removeChild(c);
+	}
+	var elements = root.hasAttribute(_attrCounter) ? [root] : [];
+	var descs = root.querySelectorAll("[" + _attrCounter + "]");
+	for (var i = 0; i < descs.length; i++)
+		elements.push(descs[i]);
+	for (var i = 0; i < elements.length; i++)
+		updateCounter(elements[i]);
+}
+
+document.addEventListener("input", function(event) {
+	var e = event.target;
+	if (e.hasAttribute && e.hasAttribute(_attrCounter))
+		updateCounter(e);
+	if (e.hasAttribute && e.hasAttribute(_attrFilter))
+		applyFilter(e);
+}, true);
+
+// Filter the items of the target of a filter box: hide items not containing the filter text
+function applyFilter(fb) {
+	var t = document.getElementById(fb.getAttribute(_attrFilter));
+	if (!t)
+		return;
+
+	var items;
+	if (t.tagName == "SELECT")
+		items = t.options;
+	else if (t.tagName == "TABLE")
+		items = t.rows.length == 1 ? t.rows[0].cells : t.rows;
+	else
+		items = t.children;
+
+	var f = fb.value.toLowerCase();
+	for (var i = 0; i < items.length; i++) {
+		var item = items[i];
+		if (f.length == 0 || item.textContent.toLowerCase().indexOf(f) >= 0)
+			item.classList.remove("gwu-Filtered");
+		else
+			item.classList.add("gwu-Filtered");
+	}
+}
+
+// Apply all filter boxes (targets might have been re-rendered)
+function applyFilters() {
+	var fbs = document.querySelectorAll("[" + _attrFilter + "]");
+	for (var i = 0; i < fbs.length; i++)
+		if (fbs[i].getAttribute(_attrFilter))
+			applyFilter(fbs[i]);
+}
+
+// Returns the two-factor box of a digit box, null if the element is not a digit box
+function tfaBox(e) {
+	var b = e.parentNode;
+	return b && b.hasAttribute && b.hasAttribute(_attrTwoFactor) ? b : null;
+}
+
+// Returns the code entered into a two-factor box
+function tfaCode(b) {
+	var ds = b.getElementsByTagName("input"), code = "";
+	for (var i = 0; i < ds.length; i++)
+		code += ds[i].value;
+	return code;
+}
+
+// Fills the digit boxes of a two-factor box with the digits of a text starting at the specified digit box,
+// and fires a change event on the two-factor box if the code is complete
+function tfaFill(b, d, text) {
+	var ds = b.getElementsByTagName("input"), j = Array.prototype.indexOf.call(ds, d);
+	for (var i = 0; i < text.length && j < ds.length; i++)
+		if (/[0-9]/.test(text.charAt(i)))
+			ds[j++].value = text.charAt(i);
+	ds[Math.min(j, ds.length - 1)].focus();
+	if (/^[0-9]+$/.test(tfaCode(b)) && tfaCode(b).length == ds.length)
+		b.dispatchEvent(new Event("change"));
+}
+
+document.addEventListener("input", function(event) {
+	var d = event.target, b = tfaBox(d);
+	if (!b)
+		return;
+	var text = d.value;
+	d.value = "";
+	tfaFill(b, d, text);
+}, true);
+
+document.addEventListener("paste", function(event) {
+	var d = event.target, b = tfaBox(d);
+	if (!b || !event.clipboardData)
+		return;
+	event.preventDefault();
+	tfaFill(b, d, event.clipboardData.getData("text"));
+}, true);
+
+document.addEventListener("keydown", function(event) {
+	var d = event.target, b = tfaBox(d);
+	if (b && event.keyCode == 8 && d.value == "" && d.previousSibling) {
+		event.preventDefault();
+		d.previousSibling.value = "";
+		d.previousSibling.focus();
+	}
+}, true);
+
+document.addEventListener("focus", function(event) {
+	if (tfaBox(event.target))
+		event.target.select();
+}, true);
+
+// Change events of digit boxes are not sent, the two-factor box fires its own when the code is complete
+document.addEventListener("change", function(event) {
+	if (tfaBox(event.target))
+		event.stopPropagation();
+}, true);
+
+// Time of the last user activity, the last activity times idle events were sent at
+// and the shown countdown dialogs of idle monitors mapped from monitor ids
+var lastActivity = new Date().getTime(), idleSent = {}, idleDialogs = {}, idleDialogsShown = false;
+
+function idleActivity() {
+	lastActivity = new Date().getTime();
+	if (idleDialogsShown)
+		hideIdleDialogs();
+}
+
+["mousemove", "mousedown", "keydown", "wheel", "touchstart", "scroll"].forEach(function(etype) {
+	document.addEventListener(etype, idleActivity, true);
+});
+
+// Shows or updates the countdown dialog of an idle monitor
+function showIdleDialog(m, sec) {
+	var d = idleDialogs[m.id];
+	if (!d) {
+		d = idleDialogs[m.id] = document.createElement("div");
+		d.className = "gwu-IdleMonitor-Dialog";
+		d.setAttribute("role", "alertdialog");
+		document.body.appendChild(d);
+		idleDialogsShown = true;
+	}
+	d.textContent = (m.getAttribute(_attrIdleText) || "").replace("{s}", sec);
+}
+
+function hideIdleDialogs() {
+	for (var id in idleDialogs) {
+		var d = idleDialogs[id];
+		if (d.parentNode)
+			d.parentNode.removeChild(d);
+	}
+	idleDialogs = {};
+	idleDialogsShown = false;
+}
+
+// Check the idle monitors every second
+setInterval(function() {
+	var ms = document.querySelectorAll("[" + _attrIdle + "]");
+	var idle = (new Date().getTime() - lastActivity) / 1000;
+	for (var i = 0; i < ms.length; i++) {
+		var m = ms[i], timeout = parseInt(m.getAttribute(_attrIdle)), warn = parseInt(m.getAttribute(_attrIdleWarn) || "0");
+		if (idle >= timeout) {
+			hideIdleDialogs();
+			// Send the event once per idle period (the monitor might be re-rendered)
+			if (idleSent[m.id] != lastActivity) {
+				idleSent[m.id] = lastActivity;
+				se(null, _etStateChange, m.id);
+			}
+		} else if (warn > 0 && idle >= timeout - warn)
+			showIdleDialog(m, Math.ceil(timeout - idle));
+	}
+}, 1000);
+
+// Position the shown popups relative to their anchors
+function applyPopups() {
+	var ps = document.querySelectorAll("[" + _attrPopupAnchor + "]");
+	for (var i = 0; i < ps.length; i++)
+		positionPopup(ps[i]);
+}
+
+// Position a popup relative to its anchor (in page coordinates)
+function positionPopup(p) {
+	var a = document.getElementById(p.getAttribute(_attrPopupAnchor));
+	if (!a)
+		return;
+	var r = a.getBoundingClientRect(), sx = window.pageXOffset, sy = window.pageYOffset;
+	var w = p.offsetWidth, h = p.offsetHeight, place = parseInt(p.getAttribute(_attrPopupPlace));
+	// Flip vertically if there is not enough room
+	if (place == _placeBelow && r.bottom + h > window.innerHeight && r.top >= h)
+		place = _placeAbove;
+	else if (place == _placeAbove && r.top < h && r.bottom + h <= window.innerHeight)
+		place = _placeBelow;
+	var x = r.left, y = r.top;
+	switch (place) {
+	case _placeBelow: y = r.bottom; break;
+	case _placeAbove: y = r.top - h; break;
+	case _placeRight: x = r.right; break;
+	case _placeLeft: x = r.left - w; break;
+	}
+	p.style.left = Math.round(x + sx) + "px";
+	p.style.top = Math.round(y + sy) + "px";
+}
+
+// Close the shown popups (except those the target is inside of or is the anchor of),
+// and notify the server. Returns true if a popup was closed.
+function closePopups(target) {
+	var ps = document.querySelectorAll("[" + _attrPopupAnchor + "]"), closed = false;
+	for (var i = 0; i < ps.length; i++) {
+		var p = ps[i], a = document.getElementById(p.getAttribute(_attrPopupAnchor));
+		if (target && (p.contains(target) || a && a.contains(target)))
+			continue;
+		p.removeAttribute(_attrPopupAnchor);
+		p.style.setProperty("display", "none", "important");
+		se(null, _etBlur, p.id);
+		closed = true;
+	}
+	return closed;
+}
+
+document.addEventListener("mousedown", function(event) {
+	closePopups(event.target);
+}, true);
+
+window.addEventListener("resize", applyPopups);
+
+// Sync the size of resizable panels resized by the user (by dragging the resize handle)
+var _rsPanel = null;
+document.addEventListener("mousedown", function(event) {
+	var e = closestWithAttr(event.target, _attrResizable);
+	_rsPanel = e ? {e: e, w: e.style.width, h: e.style.height} : null;
+}, true);
+document.addEventListener("mouseup", function() {
+	var r = _rsPanel;
+	_rsPanel = null;
+	// Browsers set the inline size of elements resized by the user
+	if (r && (r.e.style.width != r.w || r.e.style.height != r.h))
+		se(null, _etStateChange, r.e.id, "s" + parseInt(r.e.style.width || r.e.offsetWidth) + "," + parseInt(r.e.style.height || r.e.offsetHeight));
+}, true);
+
+// Download a pending file (identified by its token) using a hidden iframe
+function download(token) {
+	var f = document.createElement("iframe");
+	f.style.display = "none";
+	f.src = _pathDownload + "?" + _pDownloadToken + "=" + token;
+	document.body.appendChild(f);
+	setTimeout(function() {
+		document.body.removeChild(f);
+	}, 60000);
+}
+
+// Set the window-relative paths to point to the specified window
+function setWinPaths(winName) {
+	_winName = winName;
+	_pathWin = _pathApp + winName + "/";
+	_pathEvent = _pathWin + _pathRelEvent;
+	_pathRenderComp = _pathWin + _pathRelRenderComp;
+	_pathDownload = _pathWin + _pathRelDownload;
+}
+
+// Switch to the specified window without page reload (history navigation mode).
+// If push is true, the window is registered in the browser history.
+function switchWin(winName, push) {
+	var xhr = createXmlHttp();
+
+	xhr.onreadystatechange = function() {
+		if (xhr.readyState != 4)
+			return;
+		if (xhr.status != 200) {
+			// Window not available without reload (e.g. session creator name), fall back to page navigation
+			window.location.href = _pathApp + winName;
+			return;
+		}
+
+		// Timers of the old window must not fire anymore:
+		for (var compId in timers)
+			setupTimer(compId, null, 0, false, false, false);
+
+		setWinPaths(winName);
+		_winNonce = xhr.getResponseHeader(_hdrWinNonce);
+		document.title = badgeTitle(decodeURIComponent(xhr.getResponseHeader(_hdrWinTitle)));
+		if (push)
+			history.pushState({gwuWin: winName}, document.title, _pathApp + winName);
+		document.body.innerHTML = xhr.responseText;
+
+		// Window event handlers of the old window must not be called anymore:
+		_reconnectFuncs = [];
+
+		// Inserted JS code is not executed automatically, do it manually:
+		var scripts = document.body.getElementsByTagName("script");
+		for (var i = 0; i < scripts.length; i++) {
+			eval(scripts[i].innerText);
+		}
+
+		applyPseudoCSS(document.body);
+		applyMasks(document.body);
+		applySelections(document.body);
+		applyCounters(document.body);
+		applyFilters();
+		applyPopups();
+		applyJS(document.body);
+		focusComp(xhr.getResponseHeader(_hdrFocusCompId));
+	}
+
+	xhr.open("GET", _pathApp + winName + "/" + _pathRelRenderWin, true);
+	xhr.send();
+}
+
+// Re-render the specified components one after the other, and call done when all completed
+function rerenderComps(compIds, done, noAccess) {
+	if (compIds.length == 0) {
+		done();
+		return;
+	}
+	rerenderComp(compIds[0], function() {
+		rerenderComps(compIds.slice(1), done, noAccess);
+	}, noAccess);
+}
+
+// Re-render a component asynchronously, and call done when completed (or failed).
+// If noAccess is true, the re-render does not register an access to the session.
+function rerenderComp(compId, done, noAccess) {
+	if (!document.getElementById(compId)) { // Component removed or not visible (e.g. on inactive tab of TabPanel)
+		done();
+		return;
+	}
+
 	var xhr = createXmlHttp();
 
 	xhr.onreadystatechange = function() {
-		if (xhr.readyState == 4 && xhr.status == 200) {
+		if (xhr.readyState != 4)
+			return;
+		// Element must be "re-get" as it might have been replaced since the request was sent
+		var e = document.getElementById(compId);
+		if (xhr.status == 200 && e) {
 			// Remember focused comp which might be replaced here:
 			var focusedCompId = document.activeElement.id;
+			var states = e.hasAttribute(_attrPreserveState) ? captureStates(e) : null;
 			e.outerHTML = xhr.responseText;
 			focusComp(focusedCompId);
+			if (states != null)
+				restoreStates(states);
+			applyPseudoCSS(document.getElementById(compId));
+			applyMasks(document.getElementById(compId));
+			applySelections(document.getElementById(compId));
+			applyCounters(document.getElementById(compId));
+			applyFilters();
+			applyPopups();
+		applyPopups();
+			applyJS(document.getElementById(compId));
 
 			// Inserted JS code is not executed automatically, do it manually:
 			// Have to "re-get" element by compId!
@@ -177,13 +1169,182 @@ function rerenderComp(compId) {
 			for (var i = 0; i < scripts.length; i++) {
 				eval(scripts[i].innerText);
 			}
+		} else if (xhr.getResponseHeader(_hdrActions) != null) {
+			// Rendering failed, server tells what to do (e.g. reload the window)
+			procEresp(xhr);
 		}
+		done();
 	}
 
-	xhr.open("POST", _pathRenderComp, false); // synch call (if async, browser specific DOM rendering errors may arise)
+	xhr.open("POST", _pathRenderComp, true); // asynch call, DOM updates are serialized by the update queue
 	xhr.setRequestHeader("Content-type", "application/x-www-form-urlencoded");
 
-	xhr.send(_pCompId + "=" + compId);
+	xhr.send(_pCompId + "=" + compId + (noAccess ? "&" + _pNoAccess + "=1" : ""));
+}
+
+// Capture client side states of an element and its descendants having an id
+function captureStates(e) {
+	var states = [];
+	var elements = [e];
+	var descs = e.querySelectorAll("[id]");
+	for (var i = 0; i < descs.length; i++)
+		elements.push(descs[i]);
+
+	for (var i = 0; i < elements.length; i++) {
+		var el = elements[i];
+		var st = {id: el.id, scrollTop: el.scrollTop, scrollLeft: el.scrollLeft};
+		var tag = el.tagName;
+		if (tag == "INPUT" || tag == "TEXTAREA") {
+			st.value = el.value;
+			st.defValue = el.defaultValue;
+			st.checked = el.checked;
+			st.defChecked = el.defaultChecked;
+			try {
+				st.selStart = el.selectionStart;
+				st.selEnd = el.selectionEnd;
+			} catch (err) {
+				// Input type does not support selection
+			}
+		} else if (tag == "SELECT") {
+			st.sel = [];
+			st.defSel = [];
+			for (var j = 0; j < el.options.length; j++) {
+				st.sel.push(el.options[j].selected);
+				st.defSel.push(el.options[j].defaultSelected);
+			}
+		}
+		states.push(st);
+	}
+
+	return states;
+}
+
+// Restore client side states captured by captureStates()
+function restoreStates(states) {
+	for (var i = 0; i < states.length; i++) {
+		var st = states[i];
+		var el = document.getElementById(st.id);
+		if (!el)
+			continue;
+
+		// Only restore values which were not changed by the server
+		if (st.defValue !== undefined && el.defaultValue === st.defValue) {
+			if (el.value !== st.value)
+				el.value = st.value;
+			if (el.defaultChecked === st.defChecked)
+				el.checked = st.checked;
+			if (st.selStart != null && el === document.activeElement) {
+				try {
+					el.setSelectionRange(st.selStart, st.selEnd);
+				} catch (err) {
+					// Input type does not support selection
+				}
+			}
+		}
+		if (st.sel !== undefined && el.tagName == "SELECT" && el.options.length == st.sel.length) {
+			var same = true;
+			for (var j = 0; j < el.options.length; j++)
+				if (el.options[j].defaultSelected !== st.defSel[j]) {
+					same = false;
+					break;
+				}
+			if (same)
+				for (var j = 0; j < el.options.length; j++)
+					el.options[j].selected = st.sel[j];
+		}
+
+		el.scrollTop = st.scrollTop;
+		el.scrollLeft = st.scrollLeft;
+	}
+}
+
+// Generate the pseudo-class style rules of an element and its descendants
+// into a dynamic style sheet
+function applyPseudoCSS(root) {
+	if (!root)
+		return;
+
+	var elements = [root];
+	var descs = root.querySelectorAll("[" + _attrPseudoCSS + "]");
+	for (var i = 0; i < descs.length; i++)
+		elements.push(descs[i]);
+
+	for (var i = 0; i < elements.length; i++) {
+		var e = elements[i];
+		if (!e.id)
+			continue;
+		var pcss = e.getAttribute(_attrPseudoCSS);
+		var styleId = "gwu-pcs-" + e.id;
+		var st = document.getElementById(styleId);
+		if (pcss == null) {
+			if (st)
+				st.parentNode.removeChild(st);
+			continue;
+		}
+		if (!st) {
+			st = document.createElement("style");
+			st.id = styleId;
+			document.head.appendChild(st);
+		}
+		st.textContent = pcss.replace(/(\w+)\{/g, "#" + e.id + ":$1{");
+	}
+}
+
+// Scripts loaded by loadJS(), mapped from URL.
+// Value is true if loaded, else the array of callbacks waiting for the script.
+var _scripts = {};
+
+// Load an external script into the head (only once), and call onLoad when it is loaded
+function loadJS(url, onLoad) {
+	var st = _scripts[url];
+	if (st === true) {
+		onLoad();
+		return;
+	}
+	if (st) {
+		st.push(onLoad);
+		return;
+	}
+	_scripts[url] = [onLoad];
+	var sc = document.createElement("script");
+	sc.src = url;
+	sc.onload = sc.onerror = function() {
+		var callbacks = _scripts[url];
+		_scripts[url] = true;
+		for (var i = 0; i < callbacks.length; i++)
+			callbacks[i]();
+	}
+	document.head.appendChild(sc);
+}
+
+// Load the scripts required by an element and its descendants,
+// and execute their inline initialization codes
+function applyJS(root) {
+	if (!root)
+		return;
+
+	var elements = [root];
+	var descs = root.querySelectorAll("[" + _attrJsURLs + "],[" + _attrInlineJS + "]");
+	for (var i = 0; i < descs.length; i++)
+		elements.push(descs[i]);
+
+	for (var i = 0; i < elements.length; i++) {
+		var e = elements[i];
+		var urls = e.hasAttribute(_attrJsURLs) ? e.getAttribute(_attrJsURLs).split(" ") : [];
+		var code = e.getAttribute(_attrInlineJS);
+		if (urls.length == 0 && code == null)
+			continue;
+		// Scripts are loaded in order, inline code is executed when all are loaded
+		var next = function(e, urls, code, idx) {
+			if (idx < urls.length) {
+				loadJS(urls[idx], function() { next(e, urls, code, idx + 1); });
+				return;
+			}
+			if (code != null)
+				new Function(code).call(e);
+		};
+		next(e, urls, code, 0);
+	}
 }
 
 // Get selected indices (of an HTML select)
@@ -197,15 +1358,27 @@ function selIdxs(select) {
 	return selected;
 }
 
+// Get the value of the clicked cell of a calendar
+function calVal(event, cal) {
+	for (var e = event.target; e && e != cal; e = e.parentNode)
+		if (e.getAttribute && e.getAttribute(_attrCalValue))
+			return e.getAttribute(_attrCalValue);
+	return "";
+}
+
 // Get and update switch button value
-function sbtnVal(event, onBtnId, offBtnId) {
-	var onBtn = document.getElementById(onBtnId);
-	var offBtn = document.getElementById(offBtnId);
+function sbtnVal(event, sbtn) {
+	var btns = sbtn.getElementsByTagName("button");
+	var onBtn = btns[0], offBtn = btns[1];
 
 	if (onBtn == null)
 		return false;
 
-	var value = onBtn == document.elementFromPoint(event.clientX, event.clientY);
+	var value;
+	if (event.detail === 0) // Click generated by the keyboard (e.g. SPACE): toggle
+		value = onBtn.className != "gwu-SwitchButton-On-Active";
+	else
+		value = onBtn == document.elementFromPoint(event.clientX, event.clientY);
 	if (value) {
 		onBtn.className = "gwu-SwitchButton-On-Active";
 		offBtn.className = "gwu-SwitchButton-Off-Inactive";
@@ -217,6 +1390,21 @@ function sbtnVal(event, onBtnId, offBtnId) {
 	return value;
 }
 
+// Toggle and update switch button value (toggle only mode)
+function sbtnTgl(sbtn) {
+	var btns = sbtn.getElementsByTagName("button");
+	var onBtn = btns[0], offBtn = btns[1];
+
+	if (onBtn == null)
+		return false;
+
+	var value = onBtn.style.display == "none";
+	onBtn.style.display = value ? "" : "none";
+	offBtn.style.display = value ? "none" : "";
+
+	return value;
+}
+
 function focusComp(compId) {
 	if (compId != null && compId !== "") {
 		var e = document.getElementById(compId);
@@ -253,11 +1441,11 @@ function addonbeforeunload(func) {
 
 var timers = new Object();
 
-function setupTimer(compId, js, timeout, repeat, active, reset) {
+function setupTimer(compId, js, timeout, repeat, active, reset, maxTicks) {
 	var timer = timers[compId];
 
 	if (timer != null) {
-		var changed = timer.js != js || timer.timeout != timeout || timer.repeat != repeat || timer.reset != reset;
+		var changed = timer.js != js || timer.timeout != timeout || timer.repeat != repeat || timer.reset != reset || timer.maxTicks != maxTicks;
 		if (!active || changed) {
 			if (timer.repeat)
 				clearInterval(timer.id);
@@ -277,11 +1465,20 @@ function setupTimer(compId, js, timeout, repeat, active, reset) {
 	timer.timeout = timeout;
 	timer.repeat = repeat;
 	timer.reset = reset;
+	timer.maxTicks = maxTicks;
 
 	// Start the timer
-	if (timer.repeat)
-		timer.id = setInterval(js, timeout);
-	else
+	if (timer.repeat) {
+		if (maxTicks > 0) {
+			var f = new Function(js), ticks = 0;
+			timer.id = setInterval(function() {
+				if (++ticks >= maxTicks)
+					clearInterval(timer.id);
+				f();
+			}, timeout);
+		} else
+			timer.id = setInterval(js, timeout);
+	} else
 		timer.id = setTimeout(js, timeout);
 }
 
@@ -293,7 +1490,10 @@ function checkSession(compId) {
 	var xhr = createXmlHttp();
 
 	xhr.onreadystatechange = function() {
-		if (xhr.readyState == 4 && xhr.status == 200) {
+		if (xhr.readyState != 4)
+			return;
+		if (xhr.status == 200) {
+			e.classList.remove("gwu-SessMonitor-Error");
 			var timeoutSec = parseFloat(xhr.responseText);
 			if (timeoutSec < 60)
 				e.classList.add("gwu-SessMonitor-Expired");
@@ -301,17 +1501,15 @@ function checkSession(compId) {
 				e.classList.remove("gwu-SessMonitor-Expired");
 			var cnvtr = window[e.getAttribute("gwuJsFuncName")];
 			e.children[0].innerText = typeof cnvtr === 'function' ? cnvtr(timeoutSec) : convertSessTimeout(timeoutSec);
+		} else {
+			// Status 0 means connection error
+			e.classList.add("gwu-SessMonitor-Error");
+			e.children[0].innerText = "CONN ERR";
 		}
 	}
 
-	xhr.open("GET", _pathSessCheck, false); // synch call (else we can't catch connection error)
-	try {
-		xhr.send();
-		e.classList.remove("gwu-SessMonitor-Error");
-	} catch (err) {
-		e.classList.add("gwu-SessMonitor-Error");
-		e.children[0].innerText = "CONN ERR";
-	}
+	xhr.open("GET", _pathSessCheck, true); // asynch call
+	xhr.send();
 }
 
 function convertSessTimeout(sec) {
@@ -326,7 +1524,227 @@ function convertSessTimeout(sec) {
 // INITIALIZATION
 
 addonload(function() {
+	applyPseudoCSS(document.body);
+	applyMasks(document.body);
+	applySelections(document.body);
+	applyCounters(document.body);
+	applyFilters();
+	applyPopups();
+	applyJS(document.body);
 	focusComp(_focCompId);
 });
+
+// Synthesize swipe gestures from touch events
+var _swipe = null;
+document.addEventListener("touchstart", function(event) {
+	_swipe = null;
+	if (event.touches.length != 1)
+		return;
+	for (var e = event.target; e && e.getAttribute; e = e.parentNode)
+		if (e.getAttribute(_attrSwipe)) {
+			var t = event.touches[0];
+			_swipe = {e: e, x: t.clientX, y: t.clientY, time: Date.now()};
+			return;
+		}
+}, true);
+document.addEventListener("touchend", function(event) {
+	if (!_swipe || event.changedTouches.length < 1)
+		return;
+	var s = _swipe, t = event.changedTouches[0];
+	_swipe = null;
+	var dx = t.clientX - s.x, dy = t.clientY - s.y;
+	// Must be a quick, long enough, mostly horizontal move
+	if (Date.now() - s.time > 1000 || Math.abs(dx) < 50 || Math.abs(dx) < 2 * Math.abs(dy))
+		return;
+	var dirs = s.e.getAttribute(_attrSwipe);
+	if (dx < 0 && dirs.indexOf("L") >= 0)
+		se(null, _etSwipeLeft, s.e.id);
+	else if (dx > 0 && dirs.indexOf("R") >= 0)
+		se(null, _etSwipeRight, s.e.id);
+}, true);
+
+// Scroll events of virtual lists are sent when scrolling stops
+var _vlTimers = {};
+document.addEventListener("scroll", function(event) {
+	var e = event.target;
+	if (!e.hasAttribute || !e.hasAttribute(_attrVirtualList))
+		return;
+	clearTimeout(_vlTimers[e.id]);
+	_vlTimers[e.id] = setTimeout(function() {
+		delete _vlTimers[e.id];
+		se({type: "scroll"}, _etScroll, e.id);
+	}, 150);
+}, true);
+
+// Returns the closest element (starting from e) having the specified attribute
+function closestWithAttr(e, attr) {
+	for (; e && e.getAttribute; e = e.parentNode)
+		if (e.getAttribute(attr))
+			return e;
+	return null;
+}
+
+// Enter in a text input of a panel having a submit button clicks the button,
+// Escape inside a panel having a cancel button clicks the cancel button.
+// Also handles focus traps and keyboard navigation of tabs and expander headers.
+document.addEventListener("keydown", function(event) {
+	if (event.defaultPrevented || event.altKey || event.ctrlKey || event.metaKey)
+		return;
+	var t = event.target, attr;
+	if (event.key == "Tab" || event.keyCode == 9) {
+		trapFocus(event);
+		return;
+	}
+	if (keyNav(event))
+		return;
+	if ((event.key == "Enter" || event.keyCode == 13) && t.tagName == "INPUT")
+		attr = _attrSubmit;
+	else if (event.key == "Escape" || event.key == "Esc" || event.keyCode == 27) {
+		if (closePopups(null)) {
+			event.preventDefault();
+			return;
+		}
+		attr = _attrCancel;
+	}
+	else
+		return;
+	var f = closestWithAttr(t, attr);
+	if (!f)
+		return;
+	var b = document.getElementById(f.getAttribute(attr));
+	if (!b || b.disabled)
+		return;
+	event.preventDefault();
+	// Blurring fires the change event of the input first, so its value is sent before the click
+	if (t.blur)
+		t.blur();
+	b.click();
+});
+
+// Keyboard navigation of tabs and expander headers (elements having role="tab" or role="button"):
+// arrow keys, Home and End move the focus between tabs (roving tabindex), Enter and Space click.
+// Returns true if the event was handled.
+function keyNav(event) {
+	var t = event.target, role = t.getAttribute ? t.getAttribute("role") : null;
+	if (role != "tab" && role != "button" || t.tagName == "BUTTON" || t.tagName == "INPUT" || t.tagName == "A")
+		return false;
+	var k = event.key;
+	if (k == "Enter" || k == " " || k == "Spacebar") {
+		event.preventDefault();
+		t.click();
+		return true;
+	}
+	if (role != "tab")
+		return false;
+	var list = t.parentNode;
+	while (list && list.getAttribute && list.getAttribute("role") != "tablist")
+		list = list.parentNode;
+	if (!list || !list.getAttribute)
+		return false;
+	var tabs = list.querySelectorAll("[role=tab]"), i = Array.prototype.indexOf.call(tabs, t), j;
+	if (k == "ArrowRight" || k == "ArrowDown")
+		j = (i + 1) % tabs.length;
+	else if (k == "ArrowLeft" || k == "ArrowUp")
+		j = (i - 1 + tabs.length) % tabs.length;
+	else if (k == "Home")
+		j = 0;
+	else if (k == "End")
+		j = tabs.length - 1;
+	else
+		return false;
+	event.preventDefault();
+	t.tabIndex = -1;
+	tabs[j].tabIndex = 0;
+	tabs[j].focus();
+	return true;
+}
+
+// Keep the focus inside the focus trap panel of the target of a Tab key event
+function trapFocus(event) {
+	var p = closestWithAttr(event.target, _attrFocusTrap);
+	if (!p)
+		return;
+	var all = p.querySelectorAll("input,select,textarea,button,a[href],[tabindex]"), elements = [];
+	for (var i = 0; i < all.length; i++) {
+		var e = all[i];
+		if (!e.disabled && e.tabIndex >= 0 && e.type != "hidden" && (e.offsetWidth > 0 || e.offsetHeight > 0))
+			elements.push(e);
+	}
+	if (elements.length == 0)
+		return;
+	var first = elements[0], last = elements[elements.length - 1];
+	if (event.shiftKey && event.target == first) {
+		event.preventDefault();
+		last.focus();
+	} else if (!event.shiftKey && event.target == last) {
+		event.preventDefault();
+		first.focus();
+	}
+}
+
+// Synthesize long press events
+var _lp = null;
+function lpStart(event) {
+	lpCancel();
+	_lp = null;
+	var e = closestWithAttr(event.target, _attrLongPress);
+	if (!e)
+		return;
+	var pt = event.touches ? event.touches[0] : event;
+	_lp = {e: e, x: pt.clientX, y: pt.clientY};
+	_lp.timer = setTimeout(function() {
+		_lp.fired = true;
+		se(null, _etLongPress, e.id);
+	}, parseInt(e.getAttribute(_attrLongPress)));
+}
+function lpMove(event) {
+	if (!_lp || _lp.fired)
+		return;
+	var pt = event.touches ? event.touches[0] : event;
+	if (Math.abs(pt.clientX - _lp.x) > 10 || Math.abs(pt.clientY - _lp.y) > 10)
+		lpCancel();
+}
+function lpCancel() {
+	// If fired, keep it so the following click can be suppressed
+	if (_lp && !_lp.fired) {
+		clearTimeout(_lp.timer);
+		_lp = null;
+	}
+}
+document.addEventListener("mousedown", lpStart, true);
+document.addEventListener("touchstart", lpStart, true);
+document.addEventListener("mousemove", lpMove, true);
+document.addEventListener("touchmove", lpMove, true);
+document.addEventListener("mouseup", lpCancel, true);
+document.addEventListener("touchend", lpCancel, true);
+document.addEventListener("click", function(event) {
+	if (_lp && _lp.fired) {
+		// Suppress the click following a long press
+		_lp = null;
+		event.stopPropagation();
+		event.preventDefault();
+		return;
+	}
+
+	// Custom double click detection
+	var e = closestWithAttr(event.target, _attrDblClick);
+	if (!e)
+		return;
+	var now = Date.now();
+	if (e.gwuLastClick && now - e.gwuLastClick <= parseInt(e.getAttribute(_attrDblClick))) {
+		e.gwuLastClick = 0;
+		se(event, _etDblClick, e.id);
+	} else
+		e.gwuLastClick = now;
+}, true);
+
+// Register the initial window so we can navigate back to it (history navigation mode)
+if (window.history && history.replaceState) {
+	history.replaceState({gwuWin: _winName}, document.title);
+	window.addEventListener("popstate", function(event) {
+		if (event.state && event.state.gwuWin)
+			switchWin(event.state.gwuWin, false);
+	});
+}
 `)
 }
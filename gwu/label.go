@@ -17,6 +17,14 @@
 
 package gwu
 
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
 // Label interface defines a component which wraps a text into a component.
 //
 // Default style class: "gwu-Label"
@@ -26,28 +34,155 @@ type Label interface {
 
 	// Label has text.
 	HasText
+
+	// Markup tells if the text of the label is markup text.
+	Markup() bool
+
+	// SetMarkup sets a markup text, a text containing a safe subset of HTML.
+	// Allowed tags are <b>, <i>, <br> and <span> (with an optional class attribute),
+	// all other tags are escaped, and the rest of the text is HTML-escaped.
+	// Unclosed tags are closed automatically.
+	//
+	// Calling SetText() afterwards turns off markup rendering.
+	SetMarkup(markup string)
+
+	// Multiline tells if newline characters of the text
+	// are rendered as line breaks.
+	Multiline() bool
+
+	// SetMultiline sets whether newline characters of the text
+	// are rendered as line breaks.
+	SetMultiline(multiline bool)
 }
 
 // Label implementation
 type labelImpl struct {
 	compImpl    // Component implementation
 	hasTextImpl // Has text implementation
+
+	markup    bool // Tells if the text is (sanitized) markup
+	multiline bool // Tells if newline characters are rendered as line breaks
 }
 
 // NewLabel creates a new Label.
 func NewLabel(text string) Label {
-	c := &labelImpl{newCompImpl(nil), newHasTextImpl(text)}
+	c := &labelImpl{compImpl: newCompImpl(nil), hasTextImpl: newHasTextImpl(text)}
 	c.Style().AddClass("gwu-Label")
 	return c
 }
 
+// NewLabelf creates a new Label whose text is formatted
+// according to a format specifier.
+// This is a shorthand for
+//     NewLabel(fmt.Sprintf(format, a...))
+func NewLabelf(format string, a ...interface{}) Label {
+	return NewLabel(fmt.Sprintf(format, a...))
+}
+
+func (c *labelImpl) SetText(text string) {
+	c.text = text
+	c.markup = false
+}
+
+func (c *labelImpl) Markup() bool {
+	return c.markup
+}
+
+func (c *labelImpl) SetMarkup(markup string) {
+	c.text = sanitizeMarkup(markup)
+	c.markup = true
+}
+
+func (c *labelImpl) Multiline() bool {
+	return c.multiline
+}
+
+func (c *labelImpl) SetMultiline(multiline bool) {
+	c.multiline = multiline
+}
+
+var strBr = []byte("<br>") // "<br>"
+
 func (c *labelImpl) Render(w Writer) {
 	w.Write(strSpanOp)
 	c.renderAttrsAndStyle(w)
 	c.renderEHandlers(w)
 	w.Write(strGT)
 
-	c.renderText(w)
+	switch {
+	case c.markup && c.multiline:
+		w.Writes(strings.Replace(c.text, "\n", "<br>", -1))
+	case c.markup:
+		w.Writes(c.text)
+	case c.multiline:
+		for i, line := range strings.Split(c.text, "\n") {
+			if i > 0 {
+				w.Write(strBr)
+			}
+			w.Writees(line)
+		}
+	default:
+		c.renderText(w)
+	}
 
 	w.Write(strSpanCl)
 }
+
+// Tags allowed in markup texts.
+var markupTags = map[string]bool{"b": true, "i": true, "br": true, "span": true}
+
+var (
+	rxMarkupTag   = regexp.MustCompile(`<(/?)([a-zA-Z]+)([^<>]*)>`)
+	rxMarkupClass = regexp.MustCompile(`class\s*=\s*"([^"]*)"`)
+)
+
+// sanitizeMarkup sanitizes the specified markup text:
+// only allowed tags are kept (normalized), everything else is HTML-escaped.
+// Unclosed tags are closed at the end.
+func sanitizeMarkup(markup string) string {
+	buf := &bytes.Buffer{}
+	var open []string // Stack of open tags
+
+	pos := 0
+	for _, m := range rxMarkupTag.FindAllStringSubmatchIndex(markup, -1) {
+		closing, name, rest := markup[m[2]:m[3]] == "/", strings.ToLower(markup[m[4]:m[5]]), markup[m[6]:m[7]]
+		if !markupTags[name] {
+			continue // Not allowed, will be escaped as part of the text
+		}
+
+		buf.WriteString(html.EscapeString(markup[pos:m[0]]))
+		pos = m[1]
+
+		switch {
+		case name == "br":
+			buf.WriteString("<br>")
+		case closing:
+			// Only close if it's open; also close tags opened after it.
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == name {
+					for j := len(open) - 1; j >= i; j-- {
+						buf.WriteString("</" + open[j] + ">")
+					}
+					open = open[:i]
+					break
+				}
+			}
+		default:
+			buf.WriteString("<" + name)
+			if name == "span" {
+				if cm := rxMarkupClass.FindStringSubmatch(rest); cm != nil {
+					buf.WriteString(` class="` + html.EscapeString(html.UnescapeString(cm[1])) + `"`)
+				}
+			}
+			buf.WriteString(">")
+			open = append(open, name)
+		}
+	}
+	buf.WriteString(html.EscapeString(markup[pos:]))
+
+	for i := len(open) - 1; i >= 0; i-- {
+		buf.WriteString("</" + open[i] + ">")
+	}
+
+	return buf.String()
+}
@@ -17,6 +17,10 @@
 
 package gwu
 
+import (
+	"html"
+)
+
 // Link interface defines a clickable link pointing to a URL.
 // Links are usually used with a text, although Link is a
 // container, and allows to set a child component
@@ -41,6 +45,25 @@ type Link interface {
 	// (this is the default).
 	SetTarget(target string)
 
+	// Rel returns the explicitly set relationship of the linked URL (the "rel" attribute).
+	Rel() string
+
+	// SetRel sets the relationship of the linked URL (the "rel" attribute).
+	// If no rel is set explicitly and the target is "_blank",
+	// "noopener noreferrer" is rendered.
+	// Pass an empty string to use the default.
+	SetRel(rel string)
+
+	// Download returns the file name of the download link.
+	// Returns an empty string if the link is not a download link.
+	Download() string
+
+	// SetDownload turns the link into a download link: the linked URL will be
+	// downloaded (and saved) instead of being navigated to.
+	// fileName is the suggested file name to save the downloaded file as.
+	// Pass an empty string to make it a normal link.
+	SetDownload(fileName string)
+
 	// Comp returns the optional child component, if set.
 	Comp() Comp
 
@@ -56,24 +79,43 @@ type linkImpl struct {
 	hasURLImpl  // Has text implementation
 
 	comp Comp // Optional child component
+
+	downloadFunc DownloadFunc // Optional server-side generator of the download content
+	mimeType     string       // MIME type of the server-generated download
 }
 
 // NewLink creates a new Link.
 // By default links open in a new window (tab)
 // because their target is set to "_blank".
 func NewLink(text, url string) Link {
-	c := &linkImpl{newCompImpl(nil), newHasTextImpl(text), newHasURLImpl(url), nil}
+	c := &linkImpl{compImpl: newCompImpl(nil), hasTextImpl: newHasTextImpl(text), hasURLImpl: newHasURLImpl(url)}
 	c.SetTarget("_blank")
 	c.Style().AddClass("gwu-Link")
 	return c
 }
 
+// NewDownloadLink creates a new Link which downloads a file whose content
+// is generated at the server side, when the link is clicked.
+// The file is served with the specified file name and MIME type;
+// if mimeType is empty, "application/octet-stream" is used.
+// f is called each time the link is clicked, its output is streamed to the client.
+//
+// The URL of the link is managed internally, it must not be changed.
+// Note that the link works only if it is (directly or indirectly) added to a Window.
+func NewDownloadLink(text, fileName, mimeType string, f DownloadFunc) Link {
+	c := &linkImpl{compImpl: newCompImpl(nil), hasTextImpl: newHasTextImpl(text), hasURLImpl: newHasURLImpl("#"),
+		downloadFunc: f, mimeType: mimeType}
+	c.SetDownload(fileName)
+	c.Style().AddClass("gwu-Link")
+	return c
+}
+
 func (c *linkImpl) Remove(c2 Comp) bool {
 	if c.comp == nil || !c.comp.Equals(c2) {
 		return false
 	}
 
-	c2.setParent(nil)
+	c2.onRemoved(c)
 	c.comp = nil
 
 	return true
@@ -99,9 +141,33 @@ func (c *linkImpl) ByID(id ID) Comp {
 	return nil
 }
 
+func (c *linkImpl) childComps() []Comp {
+	if c.comp != nil {
+		return []Comp{c.comp}
+	}
+	return nil
+}
+
+func (c *linkImpl) dispose() {
+	c.compImpl.dispose()
+	c.comp = nil
+	c.downloadFunc = nil
+}
+
+func (c *linkImpl) childPath(id ID) string {
+	if c.comp != nil && c.comp.ID() == id {
+		return pathSeg(c.comp, 0)
+	}
+	return ""
+}
+
+func (c *linkImpl) SetEnabledRecursive(enabled bool) {
+	setEnabledRecursive(c, enabled)
+}
+
 func (c *linkImpl) Clear() {
 	if c.comp != nil {
-		c.comp.setParent(nil)
+		c.comp.onRemoved(c)
 		c.comp = nil
 	}
 }
@@ -118,23 +184,59 @@ func (c *linkImpl) SetTarget(target string) {
 	}
 }
 
+func (c *linkImpl) Rel() string {
+	return c.attrs["rel"]
+}
+
+func (c *linkImpl) SetRel(rel string) {
+	c.SetAttr("rel", rel)
+}
+
+func (c *linkImpl) Download() string {
+	return html.UnescapeString(c.Attr("download"))
+}
+
+func (c *linkImpl) SetDownload(fileName string) {
+	c.SetAttr("download", html.EscapeString(fileName))
+}
+
+func (c *linkImpl) downloadInfo() (fileName, mimeType string, f DownloadFunc) {
+	return c.Download(), c.mimeType, c.downloadFunc
+}
+
 func (c *linkImpl) Comp() Comp {
 	return c.comp
 }
 
 func (c *linkImpl) SetComp(c2 Comp) {
+	if c2 != nil {
+		c2.makeOrphan()
+	}
+	if c.comp != nil {
+		c.comp.onRemoved(c)
+	}
 	c.comp = c2
+	if c2 != nil {
+		setParent(c2, c)
+	}
 }
 
 var (
-	strAOp = []byte("<a")   // "<a"
-	strACL = []byte("</a>") // "</a>"
+	strAOp         = []byte("<a")                                           // "<a"
+	strACL         = []byte("</a>")                                         // "</a>"
+	strRelNoopener = []byte(` rel="noopener noreferrer"`)                   // ` rel="noopener noreferrer"`
+	strJsDlHrefOp  = []byte("document.getElementById('")                    // "document.getElementById('"
+	strJsDlHrefMid = []byte("').href=_pathDownload+'?" + paramCompID + "=") // "').href=_pathDownload+'?cid="
+	strJsDlHrefCl  = []byte("';")                                           // "';"
 )
 
 func (c *linkImpl) Render(w Writer) {
 	w.Write(strAOp)
 	c.renderURL("href", w)
 	c.renderAttrsAndStyle(w)
+	if c.attrs["rel"] == "" && c.attrs["target"] == "_blank" {
+		w.Write(strRelNoopener)
+	}
 	c.renderEHandlers(w)
 	w.Write(strGT)
 
@@ -144,5 +246,16 @@ func (c *linkImpl) Render(w Writer) {
 		c.comp.Render(w)
 	}
 
+	if c.downloadFunc != nil && !reportMode(w) {
+		// The URL of the download is window-relative, set it from JavaScript:
+		w.Write(strScriptOp)
+		w.Write(strJsDlHrefOp)
+		writeID(w, c.id)
+		w.Write(strJsDlHrefMid)
+		writeID(w, c.id)
+		w.Write(strJsDlHrefCl)
+		w.Write(strScriptCl)
+	}
+
 	w.Write(strACL)
 }
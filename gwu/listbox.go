@@ -39,9 +39,24 @@ type ListBox interface {
 	// Values returns the values.
 	Values() []string
 
-	// SetValues sets the values. Also clears the selection.
+	// SetValues sets the values. Also clears the selection,
+	// the groups and the disabled states of the values.
 	SetValues(values []string)
 
+	// AddGroup appends the specified values as a group (rendered as an optgroup)
+	// having the specified label. Indices of the values continue the indices of
+	// the existing values, e.g. after NewListBox([]string{"a"}) and AddGroup("G", "b", "c"),
+	// "b" and "c" are at indices 1 and 2 (as returned by Values()).
+	AddGroup(label string, values ...string)
+
+	// ItemEnabled tells if the value at index i is enabled (can be selected).
+	ItemEnabled(i int) bool
+
+	// SetItemEnabled sets whether the value at index i is enabled (can be selected).
+	// Disabled values can't be selected by the user, but they can still be
+	// selected by SetSelected() and SetSelectedIndices().
+	SetItemEnabled(i int, enabled bool)
+
 	// Multi tells if multiple selections are allowed.
 	Multi() bool
 
@@ -94,6 +109,14 @@ type listBoxImpl struct {
 	multi    bool     // Allow multiple selection
 	selected []bool   // Array of selection state of the values
 	rows     int      // Number of displayed rows
+	disabled []bool   // Lazily initialized disabled states of the values
+	groups   []lbGrp  // Groups of the values
+}
+
+// lbGrp is a group of values of a list box.
+type lbGrp struct {
+	label      string // Label of the group
+	start, end int    // Index of the first value and the index after the last value of the group
 }
 
 var (
@@ -102,7 +125,7 @@ var (
 
 // NewListBox creates a new ListBox.
 func NewListBox(values []string) ListBox {
-	c := &listBoxImpl{newCompImpl(strSelidx), newHasEnabledImpl(), values, false, make([]bool, len(values)), 1}
+	c := &listBoxImpl{newCompImpl(strSelidx), newHasEnabledImpl(), values, false, make([]bool, len(values)), 1, nil, nil}
 	c.AddSyncOnETypes(ETypeChange)
 	c.Style().AddClass("gwu-ListBox")
 	return c
@@ -115,6 +138,33 @@ func (c *listBoxImpl) Values() []string {
 func (c *listBoxImpl) SetValues(values []string) {
 	c.values = values
 	c.selected = make([]bool, len(values))
+	c.disabled = nil
+	c.groups = nil
+}
+
+func (c *listBoxImpl) AddGroup(label string, values ...string) {
+	start := len(c.values)
+	// Full slice expression: do not overwrite the backing array of the slice passed to us
+	c.values = append(c.values[:start:start], values...)
+	c.selected = append(c.selected, make([]bool, len(values))...)
+	if c.disabled != nil {
+		c.disabled = append(c.disabled, make([]bool, len(values))...)
+	}
+	c.groups = append(c.groups, lbGrp{label: label, start: start, end: len(c.values)})
+}
+
+func (c *listBoxImpl) ItemEnabled(i int) bool {
+	return c.disabled == nil || !c.disabled[i]
+}
+
+func (c *listBoxImpl) SetItemEnabled(i int, enabled bool) {
+	if c.disabled == nil {
+		if enabled {
+			return
+		}
+		c.disabled = make([]bool, len(c.values))
+	}
+	c.disabled[i] = !enabled
 }
 
 func (c *listBoxImpl) Multi() bool {
@@ -203,19 +253,21 @@ func (c *listBoxImpl) preprocessEvent(event Event, r *http.Request) {
 
 	// Set selected indices
 	for _, sidx := range strings.Split(value, ",") {
-		if idx, err := strconv.Atoi(sidx); err == nil {
+		if idx, err := strconv.Atoi(sidx); err == nil && idx >= 0 && idx < len(c.selected) && c.ItemEnabled(idx) {
 			c.selected[idx] = true
 		}
 	}
 }
 
 var (
-	strSelectOp    = []byte("<select")                      // "<select"
-	strMultiple    = []byte(` multiple="multiple"`)         // ` multiple="multiple"`
-	strOptionOpSel = []byte(`<option selected="selected">`) // `<option selected="selected">`
-	strOptionOp    = []byte("<option>")                     // "<option>"
-	strOptionCl    = []byte("</option>")                    // "</option>"
-	strSelectCl    = []byte("</select>")                    // "</select>"
+	strSelectOp   = []byte("<select")              // "<select"
+	strMultiple   = []byte(` multiple="multiple"`) // ` multiple="multiple"`
+	strOptionOp   = []byte("<option")              // "<option"
+	strSelAttr    = []byte(` selected="selected"`) // ` selected="selected"`
+	strOptionCl   = []byte("</option>")            // "</option>"
+	strOptGroupOp = []byte(`<optgroup label="`)    // `<optgroup label="`
+	strOptGroupCl = []byte("</optgroup>")          // "</optgroup>"
+	strSelectCl   = []byte("</select>")            // "</select>"
 )
 
 func (c *listBoxImpl) Render(w Writer) {
@@ -229,14 +281,30 @@ func (c *listBoxImpl) Render(w Writer) {
 	c.renderEHandlers(w)
 	w.Write(strGT)
 
+	groups := c.groups
 	for i, value := range c.values {
+		if len(groups) > 0 && groups[0].start == i {
+			w.Write(strOptGroupOp)
+			w.Writees(groups[0].label)
+			w.Write(strQuote)
+			w.Write(strGT)
+		}
+
+		w.Write(strOptionOp)
 		if c.selected[i] {
-			w.Write(strOptionOpSel)
-		} else {
-			w.Write(strOptionOp)
+			w.Write(strSelAttr)
+		}
+		if !c.ItemEnabled(i) {
+			w.Write(strDisabled)
 		}
+		w.Write(strGT)
 		w.Writees(value)
 		w.Write(strOptionCl)
+
+		if len(groups) > 0 && groups[0].end == i+1 {
+			w.Write(strOptGroupCl)
+			groups = groups[1:]
+		}
 	}
 
 	w.Write(strSelectCl)
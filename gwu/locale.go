@@ -0,0 +1,190 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Locale dependent parsing and formatting helpers.
+
+package gwu
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// localeFormat describes the number and date formats of a locale.
+type localeFormat struct {
+	decSep     string // Decimal separator
+	groupSep   string // Digit group separator
+	dateLayout string // Date layout (as used by the time package)
+}
+
+// Locale formats mapped from lower-cased language tags.
+// Full tags (e.g. "en-gb") take precedence over the language (e.g. "en").
+var localeFormats = map[string]localeFormat{
+	"en":    {".", ",", "01/02/2006"},
+	"en-gb": {".", ",", "02/01/2006"},
+	"en-au": {".", ",", "02/01/2006"},
+	"en-in": {".", ",", "02/01/2006"},
+	"de":    {",", ".", "02.01.2006"},
+	"de-ch": {".", "'", "02.01.2006"},
+	"fr":    {",", " ", "02/01/2006"},
+	"fr-ch": {".", "'", "02.01.2006"},
+	"es":    {",", ".", "02/01/2006"},
+	"it":    {",", ".", "02/01/2006"},
+	"pt":    {",", ".", "02/01/2006"},
+	"nl":    {",", ".", "02-01-2006"},
+	"da":    {",", ".", "02.01.2006"},
+	"fi":    {",", " ", "2.1.2006"},
+	"sv":    {",", " ", "2006-01-02"},
+	"nb":    {",", " ", "02.01.2006"},
+	"pl":    {",", " ", "02.01.2006"},
+	"cs":    {",", " ", "02.01.2006"},
+	"sk":    {",", " ", "02.01.2006"},
+	"hu":    {",", " ", "2006.01.02."},
+	"ro":    {",", ".", "02.01.2006"},
+	"ru":    {",", " ", "02.01.2006"},
+	"uk":    {",", " ", "02.01.2006"},
+	"tr":    {",", ".", "02.01.2006"},
+	"ja":    {".", ",", "2006/01/02"},
+	"zh":    {".", ",", "2006/01/02"},
+	"ko":    {".", ",", "2006.01.02"},
+}
+
+// localeFormatOf returns the locale format of the session.
+// English formats are used if sess is nil or its locale is unknown.
+func localeFormatOf(sess Session) localeFormat {
+	if sess != nil {
+		tag := strings.ToLower(strings.Replace(sess.Locale(), "_", "-", -1))
+		if lf, ok := localeFormats[tag]; ok {
+			return lf
+		}
+		if i := strings.IndexByte(tag, '-'); i > 0 {
+			if lf, ok := localeFormats[tag[:i]]; ok {
+				return lf
+			}
+		}
+	}
+	return localeFormats["en"]
+}
+
+// parseAcceptLanguage returns the most preferred language tag
+// of an Accept-Language header value (e.g. "de-DE,de;q=0.9,en;q=0.8").
+func parseAcceptLanguage(header string) string {
+	best, bestQ := "", -1.0
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			if f = strings.TrimSpace(f); strings.HasPrefix(f, "q=") {
+				if v, err := strconv.ParseFloat(f[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+	return best
+}
+
+// ErrInvalidNumber is returned by ParseNumber if the input is not a valid number.
+var ErrInvalidNumber = errors.New("invalid number")
+
+// ParseNumber parses a number entered by a user, using the number format
+// of the locale of the session (see Session.Locale()).
+// For example "1.234,56" is parsed as 1234.56 for a German user.
+// Leading and trailing spaces are ignored.
+func ParseNumber(sess Session, s string) (float64, error) {
+	lf := localeFormatOf(sess)
+
+	s = strings.TrimSpace(s)
+	if lf.groupSep == " " {
+		// Browsers and users often use non-breaking spaces for grouping
+		s = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "").Replace(s)
+	} else {
+		s = strings.Replace(s, lf.groupSep, "", -1)
+	}
+	s = strings.Replace(s, lf.decSep, ".", 1)
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, ErrInvalidNumber
+	}
+	return f, nil
+}
+
+// FormatNumber formats a number using the number format of the locale
+// of the session (see Session.Locale()), with prec digits after the decimal separator.
+// For example 1234.56 is formatted as "1.234,56" for a German user (with prec=2).
+func FormatNumber(sess Session, f float64, prec int) string {
+	lf := localeFormatOf(sess)
+
+	s := strconv.FormatFloat(f, 'f', prec, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+
+	buf := make([]byte, 0, len(s)+len(s)/3+2)
+	buf = append(buf, sign...)
+	for i := 0; i < len(intPart); i++ {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			buf = append(buf, lf.groupSep...)
+		}
+		buf = append(buf, intPart[i])
+	}
+	if fracPart != "" {
+		buf = append(buf, lf.decSep...)
+		buf = append(buf, fracPart...)
+	}
+	return string(buf)
+}
+
+// DateLayout returns the date layout (as used by the time package)
+// of the locale of the session (see Session.Locale()).
+func DateLayout(sess Session) string {
+	return localeFormatOf(sess).dateLayout
+}
+
+// ParseDate parses a date entered by a user, using the date format
+// of the locale of the session (see Session.Locale()).
+// The ISO 8601 format (e.g. "2006-01-02") is also accepted.
+// The returned time is in the specified location.
+func ParseDate(sess Session, s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	t, err := time.ParseInLocation(DateLayout(sess), s, loc)
+	if err != nil {
+		if t2, err2 := time.ParseInLocation("2006-01-02", s, loc); err2 == nil {
+			return t2, nil
+		}
+	}
+	return t, err
+}
+
+// FormatDate formats a date using the date format
+// of the locale of the session (see Session.Locale()).
+func FormatDate(sess Session, t time.Time) string {
+	return t.Format(DateLayout(sess))
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"testing"
+)
+
+// TestNumberLocale tests parsing and formatting numbers with session locales.
+func TestNumberLocale(t *testing.T) {
+	cases := []struct {
+		locale string
+		text   string
+		value  float64
+		prec   int
+	}{
+		{"", "1,234.56", 1234.56, 2},
+		{"en-US", "-1,234,567.5", -1234567.5, 1},
+		{"de-DE", "1.234,56", 1234.56, 2},
+		{"de-CH", "1'234.56", 1234.56, 2},
+		{"hu", "1 234,56", 1234.56, 2},
+		{"fr_FR", "12,5", 12.5, 1},
+	}
+
+	for _, c := range cases {
+		sess := newSessionImpl(true)
+		sess.SetLocale(c.locale)
+		v, err := ParseNumber(&sess, c.text)
+		if err != nil || v != c.value {
+			t.Errorf("ParseNumber(%q, %q) = %v, %v; want %v", c.locale, c.text, v, err, c.value)
+		}
+		if s := FormatNumber(&sess, c.value, c.prec); s != c.text {
+			t.Errorf("FormatNumber(%q, %v) = %q; want %q", c.locale, c.value, s, c.text)
+		}
+	}
+
+	if _, err := ParseNumber(nil, "1.2.3"); err == nil {
+		t.Errorf("Expected error for invalid number")
+	}
+}
+
+// TestParseAcceptLanguage tests picking the preferred language tag.
+func TestParseAcceptLanguage(t *testing.T) {
+	cases := map[string]string{
+		"":                            "",
+		"de-DE,de;q=0.9,en;q=0.8":     "de-DE",
+		"en;q=0.5, hu;q=0.9, *;q=0.1": "hu",
+	}
+	for header, exp := range cases {
+		if got := parseAcceptLanguage(header); got != exp {
+			t.Errorf("parseAcceptLanguage(%q) = %q; want %q", header, got, exp)
+		}
+	}
+}
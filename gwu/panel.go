@@ -19,6 +19,10 @@ package gwu
 
 import (
 	"bytes"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
 )
 
 // Layout strategy type.
@@ -58,10 +62,40 @@ type PanelView interface {
 	// -1 is returned if the component is not added to the panel.
 	CompIdx(c Comp) int
 
+	// Comps returns the components added to the panel.
+	// The returned slice is a copy, modifying it does not affect the panel.
+	// See Walk() to traverse component trees.
+	Comps() []Comp
+
 	// CellFmt returns the cell formatter of the specified child component.
 	// If the specified component is not a child, nil is returned.
-	// Cell formatting has no effect if layout is LayoutNatural.
+	// Cell formatting has no effect if layout is LayoutNatural
+	// (except positioning, see SetChildPos() and SetChildZ()).
 	CellFmt(c Comp) CellFmt
+
+	// SetChildPos positions the specified child component absolutely,
+	// at (x, y) pixels relative to the top left corner of the panel, e.g. to use
+	// the panel as a free-form canvas. The position is set in the style of the
+	// cell formatter of the child; if the layout is LayoutNatural, the child is
+	// rendered inside a wrapper tag having this style.
+	// The panel is made positioned (its position is set to "relative" if not set),
+	// and it should have a size, e.g. p.Style().SetDisplay(DisplayBlock).SetSizePx(800, 600).
+	SetChildPos(c Comp, x, y int)
+
+	// SetChildZ sets the z-index (stack order) of the specified child component
+	// in the style of its cell formatter (see SetChildPos()).
+	// Children having greater z-index are displayed over the ones having lower z-index.
+	SetChildZ(c Comp, z int)
+
+	// UniformCellWidth tells if cells have uniform width in horizontal layout.
+	UniformCellWidth() bool
+
+	// SetUniformCellWidth sets whether cells should have uniform width
+	// if layout is LayoutHorizontal.
+	// The panel should have a width (e.g. full width), else the cells
+	// only get their natural widths. Widths set explicitly by cell formatters
+	// take precedence.
+	SetUniformCellWidth(uniform bool)
 }
 
 // Panel interface defines a container which stores child components
@@ -81,6 +115,94 @@ type Panel interface {
 	// in which case comp will be the last component.
 	Insert(c Comp, idx int) bool
 
+	// Masked tells if the panel is masked.
+	Masked() bool
+
+	// SetMasked sets whether the panel is masked: covered with a semi-transparent
+	// overlay with a spinner (and the label if not empty), e.g. while a long-running
+	// operation is in progress. Components of a masked panel can't be clicked.
+	// The panel has to be marked dirty for the change to take effect.
+	// See also Event.Mask() to mask components without re-rendering them.
+	SetMasked(masked bool, label string)
+
+	// SubmitButton returns the submit button of the panel, nil if it has none.
+	SubmitButton() Button
+
+	// SetSubmitButton sets the submit button of the panel: pressing Enter in any
+	// (single-line) TextBox or PasswBox inside the panel clicks the button
+	// (if it is enabled), triggering its ETypeClick handlers.
+	// The button does not have to be inside the panel. Pass nil to clear it.
+	// The panel has to be marked dirty for the change to take effect.
+	SetSubmitButton(b Button)
+
+	// CancelButton returns the cancel button of the panel, nil if it has none.
+	CancelButton() Button
+
+	// SetCancelButton sets the cancel button of the panel: pressing Escape while
+	// the focus is inside the panel clicks the button (if it is enabled).
+	// The button does not have to be inside the panel. Pass nil to clear it.
+	// The panel has to be marked dirty for the change to take effect.
+	SetCancelButton(b Button)
+
+	// FocusTrap tells if the focus is trapped inside the panel.
+	FocusTrap() bool
+
+	// SetFocusTrap sets whether the focus is trapped inside the panel:
+	// if the focus is inside the panel, Tab and Shift+Tab cycle through
+	// the focusable elements of the panel (e.g. in modal dialogs).
+	// The panel has to be marked dirty for the change to take effect.
+	// See also Event.FocusFirst() to move the focus inside the panel.
+	SetFocusTrap(trap bool)
+
+	// Resizable tells if the panel is resizable by the user.
+	Resizable() bool
+
+	// SetResizable sets whether the panel is resizable by the user with a resize handle
+	// (the panel is rendered inside a block wrapper whose content can be scrolled).
+	// The size set by the user is synced to the server: it is set as the size of the
+	// style of the panel, and an ETypeStateChange event is fired whose source is the panel.
+	// The panel has to be marked dirty for the change to take effect.
+	// See Collapsible() for how sizes are persisted.
+	SetResizable(resizable bool)
+
+	// Collapsible tells if the panel is collapsible.
+	Collapsible() bool
+
+	// SetCollapsible sets whether the panel is collapsible: if so, a title bar
+	// is rendered with the specified title, clicking on it collapses or expands
+	// the panel (hides or shows its child components), and fires an ETypeStateChange
+	// event whose source is the panel.
+	// The panel has to be marked dirty for the change to take effect.
+	//
+	// The sizes of resizable panels and the collapsed states of collapsible panels
+	// changed by the user are stored in the attributes of the (private) session,
+	// and they are restored when a window having a panel with the same path
+	// (see Comp.Path()) is added to the session (see Session.AddWin()),
+	// so layouts survive rebuilding windows (e.g. when a window is reloaded).
+	SetCollapsible(collapsible bool, title string)
+
+	// Title returns the title of the title bar of collapsible panels.
+	Title() string
+
+	// Collapsed tells if the panel is collapsed.
+	Collapsed() bool
+
+	// SetCollapsed sets whether a collapsible panel is collapsed.
+	// The panel has to be marked dirty for the change to take effect.
+	SetCollapsed(collapsed bool)
+
+	// ReplaceAt replaces the component at the specified index with the specified
+	// component, and returns the replaced component. The cell formatter of the
+	// slot is kept (it will belong to the new component).
+	// nil is returned (and nothing is changed) if the index is invalid
+	// or the specified component is already a child of the panel (use Swap() to reorder).
+	ReplaceAt(idx int, c Comp) Comp
+
+	// Swap swaps the components at the specified indices.
+	// Cell formatters of the slots are kept (they are not swapped).
+	// Returns false (and nothing is changed) if an index is invalid.
+	Swap(i, j int) bool
+
 	// AddHSpace adds and returns a fixed-width horizontal space consumer.
 	// Useful when layout is LayoutHorizontal.
 	AddHSpace(width int) Comp
@@ -112,6 +234,24 @@ type panelImpl struct {
 	layout   Layout              // Layout strategy
 	comps    []Comp              // Components added to this panel
 	cellFmts map[ID]*cellFmtImpl // Lazily initialized cell formatters of the child components
+
+	uniformCellWidth bool // Tells if cells have uniform width in horizontal layout
+
+	masked    bool   // Tells if the panel is masked
+	maskLabel string // Label of the mask
+
+	submitBtn Button // Button clicked when Enter is pressed in a text box of the panel
+	cancelBtn Button // Button clicked when Escape is pressed inside the panel
+	focusTrap bool   // Tells if the focus is trapped inside the panel
+
+	resizable   bool   // Tells if the panel is resizable by the user
+	collapsible bool   // Tells if the panel is collapsible
+	collapsed   bool   // Tells if the panel is collapsed
+	title       string // Title of the title bar of collapsible panels
+
+	// Container embedding this panel (e.g. a Window), nil if not embedded.
+	// It is set as the parent of the child components.
+	outer Container
 }
 
 // NewPanel creates a new Panel.
@@ -168,7 +308,7 @@ func (c *panelImpl) Remove(c2 Comp) bool {
 		delete(c.cellFmts, c2.ID())
 	}
 
-	c2.setParent(nil)
+	c2.onRemoved(c)
 	// When removing, also reference must be cleared to allow the comp being gc'ed, also to prevent memory leak.
 	oldComps := c.comps
 	// Copy the part after the removable comp, backward by 1:
@@ -179,9 +319,18 @@ func (c *panelImpl) Remove(c2 Comp) bool {
 	return true
 }
 
+// self returns the container to be set as the parent of the child components:
+// the embedding container if there is one, else the panel itself.
+func (c *panelImpl) self() Container {
+	if c.outer != nil {
+		return c.outer
+	}
+	return c
+}
+
 func (c *panelImpl) ByID(id ID) Comp {
 	if c.id == id {
-		return c
+		return c.self()
 	}
 
 	for _, c2 := range c.comps {
@@ -198,6 +347,46 @@ func (c *panelImpl) ByID(id ID) Comp {
 	return nil
 }
 
+func (c *panelImpl) childComps() []Comp {
+	return c.comps
+}
+
+// remapIDs updates the keys of the cell formatters
+// according to the specified new IDs mapped from previous IDs.
+func (c *panelImpl) remapIDs(remap map[ID]ID) {
+	if len(c.cellFmts) == 0 {
+		return
+	}
+	cellFmts := make(map[ID]*cellFmtImpl, len(c.cellFmts))
+	for id, cf := range c.cellFmts {
+		if id2, ok := remap[id]; ok {
+			id = id2
+		}
+		cellFmts[id] = cf
+	}
+	c.cellFmts = cellFmts
+}
+
+func (c *panelImpl) dispose() {
+	c.compImpl.dispose()
+	c.comps = nil
+	c.cellFmts = nil
+	c.submitBtn, c.cancelBtn = nil, nil
+}
+
+func (c *panelImpl) childPath(id ID) string {
+	for i, c2 := range c.comps {
+		if c2.ID() == id {
+			return pathSeg(c2, i)
+		}
+	}
+	return ""
+}
+
+func (c *panelImpl) SetEnabledRecursive(enabled bool) {
+	setEnabledRecursive(c.self(), enabled)
+}
+
 func (c *panelImpl) Clear() {
 	// Clear cell formatters
 	if c.cellFmts != nil {
@@ -205,7 +394,7 @@ func (c *panelImpl) Clear() {
 	}
 
 	for _, c2 := range c.comps {
-		c2.setParent(nil)
+		c2.onRemoved(c)
 	}
 	c.comps = nil
 }
@@ -229,6 +418,10 @@ func (c *panelImpl) CompAt(idx int) Comp {
 	return c.comps[idx]
 }
 
+func (c *panelImpl) Comps() []Comp {
+	return append([]Comp(nil), c.comps...)
+}
+
 func (c *panelImpl) CompIdx(c2 Comp) int {
 	for i, c3 := range c.comps {
 		if c2.Equals(c3) {
@@ -255,10 +448,32 @@ func (c *panelImpl) CellFmt(c2 Comp) CellFmt {
 	return cf
 }
 
+func (c *panelImpl) SetChildPos(c2 Comp, x, y int) {
+	cf := c.CellFmt(c2)
+	if cf == nil {
+		return
+	}
+	cf.Style().SetPosition(PositionAbsolute).SetTopLeftPx(y, x)
+	if c.Style().Position() == "" {
+		c.Style().SetPosition(PositionRelative)
+	}
+}
+
+func (c *panelImpl) SetChildZ(c2 Comp, z int) {
+	cf := c.CellFmt(c2)
+	if cf == nil {
+		return
+	}
+	if cf.Style().Position() == "" {
+		cf.Style().SetPosition(PositionRelative) // z-index only applies to positioned elements
+	}
+	cf.Style().SetZIndex(z)
+}
+
 func (c *panelImpl) Add(c2 Comp) {
 	c2.makeOrphan()
 	c.comps = append(c.comps, c2)
-	c2.setParent(c)
+	setParent(c2, c.self())
 }
 
 func (c *panelImpl) Insert(c2 Comp, idx int) bool {
@@ -273,11 +488,231 @@ func (c *panelImpl) Insert(c2 Comp, idx int) bool {
 	copy(c.comps[idx+1:], c.comps[idx:len(c.comps)-1])
 	c.comps[idx] = c2
 
-	c2.setParent(c)
+	setParent(c2, c.self())
 
 	return true
 }
 
+func (c *panelImpl) Masked() bool {
+	return c.masked
+}
+
+func (c *panelImpl) SetMasked(masked bool, label string) {
+	c.masked, c.maskLabel = masked, label
+}
+
+func (c *panelImpl) SubmitButton() Button {
+	return c.submitBtn
+}
+
+func (c *panelImpl) SetSubmitButton(b Button) {
+	c.submitBtn = b
+}
+
+func (c *panelImpl) CancelButton() Button {
+	return c.cancelBtn
+}
+
+func (c *panelImpl) SetCancelButton(b Button) {
+	c.cancelBtn = b
+}
+
+func (c *panelImpl) FocusTrap() bool {
+	return c.focusTrap
+}
+
+func (c *panelImpl) SetFocusTrap(trap bool) {
+	c.focusTrap = trap
+}
+
+func (c *panelImpl) Resizable() bool {
+	return c.resizable
+}
+
+func (c *panelImpl) SetResizable(resizable bool) {
+	c.resizable = resizable
+	if resizable {
+		c.Style().AddClass("gwu-Panel-Resizable")
+	} else {
+		c.Style().RemoveClass("gwu-Panel-Resizable")
+	}
+}
+
+func (c *panelImpl) Collapsible() bool {
+	return c.collapsible
+}
+
+func (c *panelImpl) SetCollapsible(collapsible bool, title string) {
+	c.collapsible, c.title = collapsible, title
+	if !collapsible {
+		c.SetCollapsed(false)
+	}
+}
+
+func (c *panelImpl) Title() string {
+	return c.title
+}
+
+func (c *panelImpl) Collapsed() bool {
+	return c.collapsed
+}
+
+func (c *panelImpl) SetCollapsed(collapsed bool) {
+	c.collapsed = collapsed
+	if collapsed {
+		c.Style().AddClass("gwu-Panel-Collapsed")
+	} else {
+		c.Style().RemoveClass("gwu-Panel-Collapsed")
+	}
+}
+
+// framed tells if the panel is rendered inside a frame (a block wrapper),
+// which is the case if it is resizable or collapsible.
+func (c *panelImpl) framed() bool {
+	return c.resizable || c.collapsible
+}
+
+// panelLayout is the layout of a panel changed by the user,
+// stored in the attributes of the session.
+type panelLayout struct {
+	width, height string // Size of the panel
+	collapsed     bool   // Tells if the panel is collapsed
+}
+
+// panelLayoutAttrPrefix is the prefix of the names of the session attributes
+// storing panel layouts, the rest is the path of the panel.
+const panelLayoutAttrPrefix = "gwu-layout:"
+
+// basePanel returns the panel implementation (of types embedding it).
+func (c *panelImpl) basePanel() *panelImpl {
+	return c
+}
+
+func (c *panelImpl) preprocessEvent(event Event, r *http.Request) {
+	if event.Type() != ETypeStateChange {
+		return
+	}
+
+	value := r.FormValue(paramCompValue)
+	switch {
+	case value == "c" && c.collapsible:
+		c.SetCollapsed(!c.collapsed)
+		event.MarkDirty(c.self())
+	case strings.HasPrefix(value, "s") && c.resizable:
+		parts := strings.Split(value[1:], ",")
+		if len(parts) != 2 {
+			return
+		}
+		width, err1 := strconv.Atoi(parts[0])
+		height, err2 := strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil || width < 0 || height < 0 {
+			return
+		}
+		c.Style().SetSizePx(width, height)
+	default:
+		return
+	}
+
+	if sess := event.Session(); sess != nil && sess.Private() {
+		sess.SetAttr(panelLayoutAttrPrefix+c.self().Path(),
+			panelLayout{width: c.Style().Width(), height: c.Style().Height(), collapsed: c.collapsed})
+	}
+}
+
+// restorePanelLayouts restores the layouts of the resizable and collapsible panels
+// of the specified window stored in the attributes of the specified session.
+func restorePanelLayouts(sess Session, win Window) {
+	Walk(win, func(c Comp) bool {
+		bp, ok := c.(interface{ basePanel() *panelImpl })
+		if !ok {
+			return true
+		}
+		p := bp.basePanel()
+		if !p.framed() {
+			return true
+		}
+		if l, ok := sess.Attr(panelLayoutAttrPrefix + c.Path()).(panelLayout); ok {
+			if p.resizable {
+				p.Style().SetWidth(l.width).SetHeight(l.height)
+			}
+			if p.collapsible {
+				p.SetCollapsed(l.collapsed)
+			}
+		}
+		return true
+	})
+}
+
+var (
+	strSubmitAttrOp = []byte(" " + attrSubmit + `="`)      // ` data-gwu-sb="`
+	strCancelAttrOp = []byte(" " + attrCancel + `="`)      // ` data-gwu-cb="`
+	strFocusTrap    = []byte(" " + attrFocusTrap + `="1"`) // ` data-gwu-ft="1"`
+)
+
+// renderPanelAttrs renders the mask attribute of the panel if it is masked,
+// and the ids of the submit and cancel buttons if set.
+func (c *panelImpl) renderPanelAttrs(w Writer) {
+	if c.masked {
+		w.WriteAttr(attrMask, html.EscapeString(c.maskLabel))
+	}
+	if c.submitBtn != nil {
+		w.Write(strSubmitAttrOp)
+		w.Writev(c.submitBtn.ID())
+		w.Write(strQuote)
+	}
+	if c.cancelBtn != nil {
+		w.Write(strCancelAttrOp)
+		w.Writev(c.cancelBtn.ID())
+		w.Write(strQuote)
+	}
+	if c.focusTrap {
+		w.Write(strFocusTrap)
+	}
+}
+
+func (c *panelImpl) ReplaceAt(idx int, c2 Comp) Comp {
+	if idx < 0 || idx >= len(c.comps) || c.CompIdx(c2) >= 0 {
+		return nil
+	}
+
+	c2.makeOrphan()
+
+	old := c.comps[idx]
+	old.onRemoved(c)
+
+	c.comps[idx] = c2
+	setParent(c2, c.self()) // Before moving the cell formatter: this may change the ID of c2
+	if cf := c.cellFmts[old.ID()]; cf != nil {
+		delete(c.cellFmts, old.ID())
+		c.cellFmts[c2.ID()] = cf
+	}
+
+	return old
+}
+
+func (c *panelImpl) Swap(i, j int) bool {
+	if i < 0 || i >= len(c.comps) || j < 0 || j >= len(c.comps) {
+		return false
+	}
+
+	ci, cj := c.comps[i], c.comps[j]
+	c.comps[i], c.comps[j] = cj, ci
+
+	// Cell formatters are stored by component ID, swap them to keep them at their slots
+	if c.cellFmts != nil {
+		cfi, cfj := c.cellFmts[ci.ID()], c.cellFmts[cj.ID()]
+		delete(c.cellFmts, ci.ID())
+		delete(c.cellFmts, cj.ID())
+		if cfi != nil {
+			c.cellFmts[cj.ID()] = cfi
+		}
+		if cfj != nil {
+			c.cellFmts[ci.ID()] = cfj
+		}
+	}
+	return true
+}
+
 func (c *panelImpl) AddHSpace(width int) Comp {
 	l := NewLabel("")
 	l.Style().SetDisplay(DisplayBlock).SetWidthPx(width)
@@ -313,7 +748,84 @@ func (c *panelImpl) AddVConsumer() Comp {
 	return l
 }
 
+func (c *panelImpl) UniformCellWidth() bool {
+	return c.uniformCellWidth
+}
+
+func (c *panelImpl) SetUniformCellWidth(uniform bool) {
+	c.uniformCellWidth = uniform
+}
+
+var (
+	strResizableAttr = []byte(" " + attrResizable + `="1"`)                 // ` data-gwu-rs="1"`
+	strPanelTitleOp  = []byte(`<div class="gwu-Panel-Title gwuimg-`)        // `<div class="gwu-Panel-Title gwuimg-`
+	strPanelToggleOp = []byte(`" onclick="se(event,` + etStateChange + `,`) // `" onclick="se(event,<ETypeStateChange>,`
+	strPanelToggleCl = []byte(`,'c')">`)                                    // `,'c')">`
+	strCollapsed     = []byte("collapsed")                                  // "collapsed"
+	strExpanded      = []byte("expanded")                                   // "expanded"
+	strPanelInner    = []byte(` class="gwu-Panel-Inner"`)                   // ` class="gwu-Panel-Inner"`
+)
+
+// etStateChange is ETypeStateChange as a string.
+var etStateChange = ETypeStateChange.String()
+
 func (c *panelImpl) Render(w Writer) {
+	if !c.framed() {
+		c.renderLayout(w)
+		return
+	}
+
+	// Resizable and collapsible panels are rendered inside a frame
+	w.Write(strDivOp)
+	c.renderAttrsAndStyle(w)
+	c.renderPanelAttrs(w)
+	if c.resizable {
+		w.Write(strResizableAttr)
+	}
+	c.renderEHandlers(w)
+	w.Write(strGT)
+
+	report := reportMode(w)
+	if c.collapsible {
+		w.Write(strPanelTitleOp)
+		if c.collapsed && !report {
+			w.Write(strCollapsed)
+		} else {
+			w.Write(strExpanded)
+		}
+		if report {
+			w.Write(strQuote)
+			w.Write(strGT)
+		} else {
+			w.Write(strPanelToggleOp)
+			writeID(w, c.id)
+			w.Write(strPanelToggleCl)
+		}
+		w.Writees(c.title)
+		w.Write(strDivCl)
+	}
+	// Reports show the content of collapsed panels too
+	if !c.collapsed || report {
+		c.renderLayout(w)
+	}
+
+	w.Write(strDivCl)
+}
+
+// renderLayoutAttrs renders the attributes of the layout tag of the panel.
+// If the panel is framed, attributes are rendered by the frame.
+func (c *panelImpl) renderLayoutAttrs(w Writer) {
+	if c.framed() {
+		w.Write(strPanelInner)
+		return
+	}
+	c.renderAttrsAndStyle(w)
+	c.renderPanelAttrs(w)
+	c.renderEHandlers(w)
+}
+
+// renderLayout renders the panel using its layout strategy.
+func (c *panelImpl) renderLayout(w Writer) {
 	switch c.layout {
 	case LayoutNatural:
 		c.layoutNatural(w)
@@ -329,12 +841,18 @@ func (c *panelImpl) Render(w Writer) {
 func (c *panelImpl) layoutNatural(w Writer) {
 	// No wrapper table but we still need a wrapper tag for attributes...
 	w.Write(strSpanOp)
-	c.renderAttrsAndStyle(w)
-	c.renderEHandlers(w)
+	c.renderLayoutAttrs(w)
 	w.Write(strGT)
 
 	for _, c2 := range c.comps {
-		c2.Render(w)
+		// Positioned children are rendered inside a wrapper having the style of their cell formatter
+		if cf := c.cellFmts[c2.ID()]; cf != nil && cf.styleImpl != nil && cf.styleImpl.Position() != "" {
+			cf.render(strSpanOp, w)
+			c2.Render(w)
+			w.Write(strSpanCl)
+		} else {
+			c2.Render(w)
+		}
 	}
 
 	w.Write(strSpanCl)
@@ -344,10 +862,19 @@ func (c *panelImpl) layoutNatural(w Writer) {
 // using the horizontal layout strategy.
 func (c *panelImpl) layoutHorizontal(w Writer) {
 	w.Write(strTableOp)
-	c.renderAttrsAndStyle(w)
-	c.renderEHandlers(w)
+	c.renderLayoutAttrs(w)
 	w.Write(strGT)
 
+	if c.uniformCellWidth && len(c.comps) > 0 {
+		// Same col tag for each column:
+		w.Write(strColGroupOp)
+		width := strconv.FormatFloat(100/float64(len(c.comps)), 'f', 3, 64)
+		for range c.comps {
+			w.Writess(`<col style="width:`, width, `%">`)
+		}
+		w.Write(strColGroupCl)
+	}
+
 	c.renderTr(w)
 
 	for _, c2 := range c.comps {
@@ -362,8 +889,7 @@ func (c *panelImpl) layoutHorizontal(w Writer) {
 // using the vertical layout strategy.
 func (c *panelImpl) layoutVertical(w Writer) {
 	w.Write(strTableOp)
-	c.renderAttrsAndStyle(w)
-	c.renderEHandlers(w)
+	c.renderLayoutAttrs(w)
 	w.Write(strGT)
 
 	// There is the same TR tag for each cell:
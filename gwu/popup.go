@@ -0,0 +1,168 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Popup layer of windows.
+
+package gwu
+
+// Placement is the placement of a popup relative to its anchor component.
+type Placement int
+
+// Popup placements.
+// If there is not enough room in the window, popups placed below
+// the anchor are placed above it, and vice versa.
+const (
+	PlacementBelow Placement = iota // Below the anchor, aligned to its left edge
+	PlacementAbove                  // Above the anchor, aligned to its left edge
+	PlacementRight                  // Right to the anchor, aligned to its top edge
+	PlacementLeft                   // Left to the anchor, aligned to its top edge
+)
+
+// popupImpl is the popup layer of a window: a floating container
+// holding the content of the popup shown in the window (if any).
+//
+// The popup layer is rendered after the window component (not inside it),
+// so re-rendering the window does not affect it.
+//
+// Default style class: "gwu-Popup"
+type popupImpl struct {
+	compImpl // Component implementation
+
+	content   Comp      // Content of the popup, nil if no popup is shown
+	anchor    Comp      // Component the popup is positioned relative to
+	placement Placement // Placement of the popup relative to the anchor
+}
+
+// newPopupImpl creates a new popupImpl.
+func newPopupImpl() *popupImpl {
+	c := &popupImpl{compImpl: newCompImpl(nil)}
+	c.Style().AddClass("gwu-Popup")
+	c.hidden = true
+	// The client sends a blur event when the popup is closed by clicking
+	// outside of it or by pressing Escape.
+	c.AddEHandler(internalHandler{c.base(), func(e Event) {
+		c.close()
+	}}, ETypeBlur)
+	return c
+}
+
+// show shows the specified content in the popup,
+// replacing the current content if any.
+func (c *popupImpl) show(content, anchor Comp, placement Placement) {
+	if c.content != nil && !c.content.Equals(content) {
+		c.close()
+	}
+	if c.content == nil {
+		content.makeOrphan()
+		setParent(content, c)
+	}
+	c.content, c.anchor, c.placement = content, anchor, placement
+	c.hidden = false
+}
+
+// close closes the popup, removing its content.
+func (c *popupImpl) close() {
+	if c.content != nil {
+		c.content.onRemoved(c)
+	}
+	c.content, c.anchor = nil, nil
+	c.hidden = true
+}
+
+func (c *popupImpl) Remove(c2 Comp) bool {
+	if c.content == nil || !c.content.Equals(c2) {
+		return false
+	}
+	c.close()
+	return true
+}
+
+func (c *popupImpl) ByID(id ID) Comp {
+	if c.id == id {
+		return c
+	}
+	if c.content == nil {
+		return nil
+	}
+	if c.content.ID() == id {
+		return c.content
+	}
+	if c2, isContainer := c.content.(Container); isContainer {
+		return c2.ByID(id)
+	}
+	return nil
+}
+
+func (c *popupImpl) Clear() {
+	c.close()
+}
+
+func (c *popupImpl) SetEnabledRecursive(enabled bool) {
+	setEnabledRecursive(c, enabled)
+}
+
+func (c *popupImpl) childComps() []Comp {
+	if c.content == nil {
+		return nil
+	}
+	return []Comp{c.content}
+}
+
+func (c *popupImpl) dispose() {
+	c.compImpl.dispose()
+	c.content, c.anchor = nil, nil
+}
+
+func (c *popupImpl) childPath(id ID) string {
+	if c.content != nil && c.content.ID() == id {
+		return "content"
+	}
+	return ""
+}
+
+var (
+	strPopupAnchorOp = []byte(" " + attrPopupAnchor + `="`) // ` data-gwu-pa="`
+	strPopupPlaceOp  = []byte(`" ` + attrPopupPlace + `="`) // `" data-gwu-pp="`
+)
+
+func (c *popupImpl) Render(w Writer) {
+	w.Write(strDivOp)
+	c.renderAttrsAndStyle(w)
+	if c.content != nil {
+		w.Write(strPopupAnchorOp)
+		if c.anchor != nil {
+			w.Writev(c.anchor.ID())
+		}
+		w.Write(strPopupPlaceOp)
+		w.Writev(int(c.placement))
+		w.Write(strQuote)
+	}
+	w.Write(strGT)
+
+	if c.content != nil {
+		c.content.Render(w)
+	}
+
+	w.Write(strDivCl)
+}
+
+// popupOf returns the popup layer of the window of the specified component,
+// nil if the component is not added to a window.
+func popupOf(c Comp) *popupImpl {
+	if w := windowOf(c); w != nil {
+		return w.popup
+	}
+	return nil
+}
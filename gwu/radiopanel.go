@@ -0,0 +1,121 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// RadioPanel component interface and implementation.
+
+package gwu
+
+// RadioPanel interface defines a PanelView which holds a set of
+// radio buttons belonging to the same RadioGroup.
+// The radio group and the radio buttons are created by the radio panel.
+//
+// You can register ETypeChange event handlers which will be called when the user
+// changes the selection by clicking on a radio button. The event source will be the
+// radio panel. The event will have a parent event whose source will be the clicked
+// radio button and will contain the mouse coordinates.
+//
+// Default style class: "gwu-RadioPanel"
+type RadioPanel interface {
+	// RadioPanel is a PanelView.
+	PanelView
+
+	// Group returns the radio group of the radio buttons.
+	Group() RadioGroup
+
+	// ButtonAt returns the radio button at the specified index.
+	// Returns nil if idx<0 or idx>=CompsCount().
+	ButtonAt(idx int) RadioButton
+
+	// SelectedIdx returns the index of the selected radio button.
+	// Returns -1 if no radio button is selected.
+	SelectedIdx() int
+
+	// SetSelectedIdx selects the radio button at the specified index.
+	// If idx < 0, no radio buttons will be selected.
+	// If idx >= CompsCount(), this is a no-op.
+	SetSelectedIdx(idx int)
+}
+
+// RadioPanel implementation.
+type radioPanelImpl struct {
+	panelImpl // panel implementation: RadioPanel is a Panel, but only PanelView's methods are exported.
+
+	group   RadioGroup // Radio group of the radio buttons
+	lastIdx int        // Last known selected index, used to detect selection changes
+}
+
+// NewRadioPanel creates a new RadioPanel with radio buttons having the specified labels.
+// The name is used as the name of the radio group.
+// Default layout strategy is LayoutVertical,
+// no radio button is selected initially.
+func NewRadioPanel(name string, labels []string) RadioPanel {
+	c := &radioPanelImpl{panelImpl: newPanelImpl(), group: NewRadioGroup(name), lastIdx: -1}
+	c.outer = c
+	c.Style().AddClass("gwu-RadioPanel")
+
+	for _, label := range labels {
+		rb := NewRadioButton(label, c.group)
+		c.panelImpl.Add(rb)
+
+		rb.AddEHandler(internalHandler{c.base(), func(e Event) {
+			// Clicking on the selected radio button does not change the selection:
+			idx := c.SelectedIdx()
+			if idx == c.lastIdx {
+				return
+			}
+			c.lastIdx = idx
+			if c.handlers[ETypeChange] != nil {
+				c.dispatchEvent(e.forkEvent(ETypeChange, c))
+			}
+		}}, ETypeClick)
+	}
+
+	return c
+}
+
+func (c *radioPanelImpl) Group() RadioGroup {
+	return c.group
+}
+
+func (c *radioPanelImpl) ButtonAt(idx int) RadioButton {
+	if rb, ok := c.CompAt(idx).(RadioButton); ok {
+		return rb
+	}
+	return nil
+}
+
+func (c *radioPanelImpl) SelectedIdx() int {
+	if sel := c.group.Selected(); sel != nil {
+		return c.CompIdx(sel)
+	}
+	return -1
+}
+
+func (c *radioPanelImpl) SetSelectedIdx(idx int) {
+	if idx >= c.CompsCount() {
+		return
+	}
+
+	if idx < 0 {
+		if sel := c.group.Selected(); sel != nil {
+			sel.SetState(false)
+		}
+		c.lastIdx = -1
+		return
+	}
+
+	c.ButtonAt(idx).SetState(true)
+	c.lastIdx = idx
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Event rate limiting.
+
+package gwu
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimitFunc is a function which is called when an event is refused
+// because of exceeding an event rate limit.
+// The event is not dispatched to the event handlers of its source component,
+// but it can be used to notify the user, e.g. with Event.ShowToast().
+type RateLimitFunc func(e Event)
+
+// rateWindow is the counter of a key in the current time window.
+type rateWindow struct {
+	start time.Time     // Start of the time window
+	per   time.Duration // Length of the time window
+	count int           // Number of events in the time window
+}
+
+// rateLimiter counts events in fixed time windows mapped from keys
+// (e.g. session ID or client IP).
+type rateLimiter struct {
+	mux       sync.Mutex             // Mutex to protect the windows
+	windows   map[string]*rateWindow // Time windows mapped from key
+	lastSweep time.Time              // Time of the last removal of expired windows
+}
+
+// allow registers an event of the specified key, and tells if
+// it is within the limit of n events per the specified duration.
+func (l *rateLimiter) allow(key string, n int, per time.Duration, now time.Time) bool {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if l.windows == nil {
+		l.windows = make(map[string]*rateWindow)
+	}
+
+	// Remove expired windows from time to time so the map does not grow indefinitely
+	if now.Sub(l.lastSweep) >= time.Minute {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= w.per {
+				delete(l.windows, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	w := l.windows[key]
+	if w == nil || now.Sub(w.start) >= w.per {
+		w = &rateWindow{start: now, per: per}
+		l.windows[key] = w
+	}
+	w.count++
+	return w.count <= n
+}
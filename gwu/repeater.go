@@ -0,0 +1,141 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Repeater component interface and implementation.
+
+package gwu
+
+// RepeaterBindFunc is a function which binds the data element at index idx
+// to the specified item (the clone of the prototype) of a Repeater,
+// e.g. sets the texts of the labels of the item.
+type RepeaterBindFunc func(item Comp, idx int)
+
+// Repeater interface defines a PanelView which renders a list of similar items:
+// each item is a clone of a prototype component (tree), bound to an element of
+// a data slice by a bind function.
+//
+// The items are cloned with event handlers (see Clone()), so handlers of the
+// components of the prototype are called for the items too;
+// use IdxOf() with the event source to find out which item the event belongs to.
+// Example:
+//
+//	row := gwu.NewHorizontalPanel()
+//	row.Add(gwu.NewLabel(""))
+//	del := gwu.NewButton("Delete")
+//	row.Add(del)
+//	var rep gwu.Repeater
+//	del.AddEHandlerFunc(func(e gwu.Event) {
+//		i := rep.IdxOf(e.Src())
+//		names = append(names[:i], names[i+1:]...)
+//		rep.SetCount(len(names))
+//		e.MarkDirty(rep)
+//	}, gwu.ETypeClick)
+//	rep = gwu.NewRepeater(row, func(item gwu.Comp, idx int) {
+//		item.(gwu.Panel).CompAt(0).(gwu.Label).SetText(names[idx])
+//	})
+//	rep.SetCount(len(names))
+//
+// Default style class: "gwu-Repeater"
+type Repeater interface {
+	// Repeater is a PanelView.
+	PanelView
+
+	// Prototype returns the prototype component.
+	Prototype() Comp
+
+	// Count returns the number of items.
+	Count() int
+
+	// SetCount sets the number of items (e.g. the length of the data slice).
+	// Existing items are reused, missing items are cloned from the prototype,
+	// extra items are removed. The bind function is called for all items.
+	// Note: if the prototype can't be cloned (it contains custom components
+	// implemented outside of the gwu package), no items are created.
+	SetCount(n int)
+
+	// Refresh calls the bind function for all items, e.g. after the data has changed.
+	Refresh()
+
+	// ItemAt returns the item at the specified index.
+	// Returns nil if idx<0 or idx>=Count().
+	ItemAt(idx int) Comp
+
+	// IdxOf returns the index of the item containing the specified component
+	// (the item itself or any of its descendants), e.g. the source of an event.
+	// -1 is returned if the component is not part of an item of the repeater.
+	IdxOf(c Comp) int
+}
+
+// Repeater implementation.
+type repeaterImpl struct {
+	panelImpl // panel implementation: Repeater is a Panel, but only PanelView's methods are exported.
+
+	proto Comp             // Prototype of the items
+	bind  RepeaterBindFunc // Function to bind data elements to items
+}
+
+// NewRepeater creates a new Repeater with the specified prototype and bind function.
+// The repeater has no items initially, call SetCount() to create them.
+// Default layout strategy is LayoutVertical.
+func NewRepeater(prototype Comp, bind RepeaterBindFunc) Repeater {
+	c := &repeaterImpl{panelImpl: newPanelImpl(), proto: prototype, bind: bind}
+	c.outer = c
+	c.Style().AddClass("gwu-Repeater")
+	return c
+}
+
+func (c *repeaterImpl) Prototype() Comp {
+	return c.proto
+}
+
+func (c *repeaterImpl) Count() int {
+	return len(c.comps)
+}
+
+func (c *repeaterImpl) SetCount(n int) {
+	for len(c.comps) > n && len(c.comps) > 0 {
+		c.panelImpl.Remove(c.comps[len(c.comps)-1])
+	}
+	for len(c.comps) < n {
+		item := Clone(c.proto, true)
+		if item == nil {
+			break
+		}
+		c.panelImpl.Add(item)
+	}
+	c.Refresh()
+}
+
+func (c *repeaterImpl) Refresh() {
+	if c.bind == nil {
+		return
+	}
+	for i, item := range c.comps {
+		c.bind(item, i)
+	}
+}
+
+func (c *repeaterImpl) ItemAt(idx int) Comp {
+	return c.CompAt(idx)
+}
+
+func (c *repeaterImpl) IdxOf(c2 Comp) int {
+	for ; c2 != nil; c2 = c2.Parent() {
+		if p := c2.Parent(); p != nil && c.Equals(p) {
+			return c.CompIdx(c2)
+		}
+	}
+	return -1
+}
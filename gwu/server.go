@@ -19,12 +19,19 @@
 package gwu
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"net/url"
 	"path"
+	"runtime/pprof"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -37,6 +44,8 @@ const (
 	pathSessCheck  = "_sess_ch"     // App path-relative path for checking session (without registering access)
 	pathEvent      = "e"            // Window-relative path for sending events
 	pathRenderComp = "rc"           // Window-relative path for rendering a component
+	pathRenderWin  = "rw"           // Window-relative path for rendering the content of a window (without reload)
+	pathDownload   = "dl"           // Window-relative path for serving the file download of a component
 )
 
 // Parameters passed between the browser and the server.
@@ -45,13 +54,35 @@ const (
 	paramCompID        = "cid"  // Component id parameter name
 	paramCompValue     = "cval" // Component value parameter name
 	paramFocusedCompID = "fcid" // Focused component id parameter name
-	paramMouseWX       = "mwx"  // Mouse x pixel coordinate (inside window)
-	paramMouseWY       = "mwy"  // Mouse y pixel coordinate (inside window)
+	paramMouseWX       = "mwx"  // Mouse x pixel coordinate (inside window viewport)
+	paramMouseWY       = "mwy"  // Mouse y pixel coordinate (inside window viewport)
 	paramMouseX        = "mx"   // Mouse x pixel coordinate (relative to source component)
 	paramMouseY        = "my"   // Mouse y pixel coordinate (relative to source component)
+	paramMousePX       = "mpx"  // Mouse x pixel coordinate (inside page)
+	paramMousePY       = "mpy"  // Mouse y pixel coordinate (inside page)
+	paramSrcRectX      = "rx"   // X coordinate of the rect of the source component (inside page)
+	paramSrcRectY      = "ry"   // Y coordinate of the rect of the source component (inside page)
+	paramSrcRectW      = "rw"   // Width of the rect of the source component
+	paramSrcRectH      = "rh"   // Height of the rect of the source component
 	paramMouseBtn      = "mb"   // Mouse button
 	paramModKeys       = "mk"   // Modifier key states
 	paramKeyCode       = "kc"   // Key code
+	paramKeyText       = "kt"   // Key text (value of the key)
+	paramKeyRepeat     = "kr"   // Key repeat flag
+	paramWheelDX       = "wdx"  // Mouse wheel horizontal delta
+	paramWheelDY       = "wdy"  // Mouse wheel vertical delta
+	paramScrollX       = "sx"   // Horizontal scroll position
+	paramScrollY       = "sy"   // Vertical scroll position
+	paramScrollMaxX    = "smx"  // Maximum horizontal scroll position
+	paramScrollMaxY    = "smy"  // Maximum vertical scroll position
+	paramSelStart      = "ss"   // Selection start in the source component (text box)
+	paramSelEnd        = "se"   // Selection end in the source component (text box)
+	paramClipboard     = "cb"   // Clipboard text of cut, copy and paste events
+	paramDownloadToken = "t"    // Download token
+	paramNoAccess      = "na"   // Tells that rendering does not register an access to the session
+	paramDataPrefix    = "d-"   // Prefix of the data attribute parameter names of the event source
+	paramWinNonce      = "wn"   // Nonce of the window instance the event originates from
+	paramTabID         = "tid"  // Id of the browser tab the event originates from
 )
 
 // Event response actions (client actions to take after processing an event).
@@ -60,11 +91,63 @@ const (
 	eraReloadWin         // Window name to be reloaded
 	eraDirtyComps        // There are dirty components which needs to be refreshed
 	eraFocusComp         // Focus a component
+	eraDownload          // Download a file (identified by a download token)
+	eraSwitchWin         // Window name to switch to (without page reload, using the browser history)
+	eraAnimate           // Run a CSS animation on a component
+	eraToast             // Show a toast (notification message)
+	eraAnnounce          // Announce a text in an ARIA live region
+	eraSchedule          // Schedule a server-side task of the window
+	eraWinExpired        // The window (as known by the browser) has expired and must be reloaded
+	eraMask              // Mask or unmask a component
+	eraTabBadge          // Set the badge of the browser tab
+	eraAttention         // Request the attention of the user
+	eraOpenWin           // Open a window in a new browser window
+	eraBroadcast         // Refresh other windows (browser tabs) of the session
+)
+
+// HTTP response headers used when rendering the content of a window.
+const (
+	headerWinTitle    = "Gwu-Win-Title"     // Title of the window (URL-encoded)
+	headerFocusCompID = "Gwu-Focus-Comp-Id" // ID of the component to be focused in the window
+	headerWinNonce    = "Gwu-Win-Nonce"     // Nonce of the window instance
+	headerActions     = "Gwu-Actions"       // Marks that the response body contains event response actions
+	headerNoAccess    = "Gwu-No-Access"     // Marks that the event did not register an access to the session
 )
 
 // Default GWU session id cookie name
 const defaultSessIDCookieName = "gwu-sessid"
 
+// MultiTabPolicy is the type of the policies defining how
+// windows open in multiple browser tabs are treated.
+type MultiTabPolicy int
+
+// Multi-tab policies.
+const (
+	MultiTabAllow  MultiTabPolicy = iota // Windows may be used from multiple tabs without restrictions
+	MultiTabWarn                         // Events from a second tab are processed, but a warning toast is shown in it
+	MultiTabRefuse                       // Events from a second tab are refused while the owner tab is active
+)
+
+// SessionOverflowPolicy is the type of the policies defining what happens
+// when a session limit is reached (see Server.SetMaxSessions()).
+type SessionOverflowPolicy int
+
+// Session overflow policies.
+const (
+	SessOverflowReject SessionOverflowPolicy = iota // No new session is created, an error page is rendered
+	SessOverflowEvict                               // The least recently accessed session is removed to make room for the new one
+)
+
+// Inactivity duration after which the owner tab of a window
+// is considered gone (see Server.SetMultiTabPolicy()).
+const tabOwnerTimeout = time.Minute
+
+// Default text of the banner displayed when the connection to the server is lost
+const defaultOfflineText = "Connection lost, reconnecting..."
+
+// Default time before the expiry of sessions when the functions registered by Server.OnSessionExpiring() are called
+const defaultExpiringBefore = time.Minute
+
 // SessionHandler interface defines a callback to get notified
 // for certain events related to session life-cycles.
 type SessionHandler interface {
@@ -82,6 +165,40 @@ type SessionHandler interface {
 // sess is the shared, public session if no private session is created.
 type AppRootHandlerFunc func(w http.ResponseWriter, r *http.Request, sess Session)
 
+// ErrorPresenterFunc is the function type that presents an error returned by an event handler
+// to the user. e is the event whose handler returned the error.
+type ErrorPresenterFunc func(e Event, err error)
+
+// EventAuditorFunc is the function type that is called after dispatching an event,
+// see Server.SetEventAuditor(). comp is the source component of the event,
+// and dur is the time spent processing the event (dispatching it to the handlers).
+type EventAuditorFunc func(sess Session, win Window, comp Comp, etype EventType, dur time.Duration)
+
+// SSESourceFunc is the function type that produces the events of a Server-Sent Events stream,
+// see Server.AddSSE(). It should call send for each event to be sent (event is the
+// event name, it may be empty; data may be multi-line), until ctx is done (the client
+// disconnected). The stream ends when the function returns.
+// send is safe for concurrent use, it can be called from other goroutines too.
+type SSESourceFunc func(ctx context.Context, send func(event, data string))
+
+// ServerConfig is the part of the server configuration
+// that is required to render windows (see Window.RenderToString()).
+// Server implements ServerConfig.
+type ServerConfig interface {
+	// AppPath returns the application path.
+	AppPath() string
+
+	// Theme returns the default CSS theme of the server.
+	Theme() string
+
+	// OfflineText returns the text of the banner displayed
+	// when the connection to the server is lost.
+	OfflineText() string
+
+	// RenderPaths tells if component paths are rendered.
+	RenderPaths() bool
+}
+
 // Server interface defines the GUI server which handles sessions,
 // renders the windows, components and handles event dispatching.
 type Server interface {
@@ -100,6 +217,8 @@ type Server interface {
 	Secure() bool
 
 	// AppURL returns the application URL string.
+	// If the server address has port 0 (e.g. "localhost:0"), the port is
+	// chosen when the server is started, and the URL is updated to contain it.
 	AppURL() string
 
 	// AppPath returns the application path string.
@@ -132,9 +251,49 @@ type Server interface {
 	// 		}
 	AddSessCreatorName(name, text string)
 
+	// AddSessWindowTemplate registers a window template whose build function
+	// is called to instantiate a per-session copy of the window
+	// for each private session.
+	//
+	// The window is created and added to new private sessions (before SessionHandlers
+	// are notified), and it is also created on demand for existing private sessions
+	// which do not have a window with the specified name.
+	// The name also works as a session creator name (see AddSessCreatorName()):
+	// its path auto-creates a new session if the current session is not private.
+	// The name of the built window is set to the specified name.
+	//
+	// Tip: Build a window once and use Window.Clone() in the build function:
+	//     tmpl := buildCartWindow()
+	//     server.AddSessWindowTemplate("cart", "Cart", func(sess gwu.Session) gwu.Window {
+	//         return tmpl.Clone()
+	//     })
+	AddSessWindowTemplate(name, text string, build func(sess Session) Window)
+
 	// AddSHandler adds a new session handler.
 	AddSHandler(handler SessionHandler)
 
+	// OnSessionExpiring registers a function to be called when a private session
+	// is about to expire (see SetSessExpiringBefore()), e.g. to persist unsaved state
+	// before the session is removed. remaining is the remaining time until the session expires.
+	// The function is called once per idle period of the session (it is called again
+	// if the session is accessed and becomes idle again), from the goroutine
+	// that removes expired sessions, with the session locked.
+	OnSessionExpiring(f func(sess Session, remaining time.Duration))
+
+	// SetSessExpiringBefore sets how long before the expiry of sessions
+	// the functions registered by OnSessionExpiring() are called.
+	// Sessions are checked every 10 seconds. The default is 1 minute.
+	SetSessExpiringBefore(before time.Duration)
+
+	// Sessions returns information about the private sessions of the server
+	// (e.g. for admin consoles), sorted by creation time.
+	Sessions() []SessionInfo
+
+	// RemoveSession removes (invalidates) the private session with the specified ID,
+	// e.g. to log out a user from an admin console.
+	// Returns false if no session exists with the specified ID.
+	RemoveSession(id string) bool
+
 	// SetHeaders sets extra HTTP response headers that are added to all responses.
 	// Supplied values are copied, so changes to the passed map afterwards have no effect.
 	//
@@ -162,6 +321,56 @@ type Server interface {
 	// "/tmp/myimg/faces/happy.gif", just as the the request for relative path "img/faces/happy.gif".
 	AddStaticDir(path, dir string) error
 
+	// HandleFunc registers a handler function for the specified app path relative path,
+	// e.g. to serve non-GUI endpoints (health checks, webhooks, JSON APIs) from the same server.
+	// Paths ending with a slash match the whole subtree (the same way as with http.ServeMux).
+	// The extra headers set by SetHeaders() are added to the responses, and incoming
+	// requests are logged to the logger (if set).
+	//
+	// An error is returned if the path is already registered, or if its first
+	// segment is the name of a window of the server or of a session window template.
+	// Registered paths take precedence over window names added later.
+	// Tip: Use SessionFromRequest() to access the Gowut session associated with the request.
+	//
+	// Example:
+	//     server.HandleFunc("api/health", func(w http.ResponseWriter, r *http.Request) {
+	//         w.Write([]byte("OK"))
+	//     })
+	// Then requests for "/appname/api/health" will be served by the handler.
+	HandleFunc(path string, h http.HandlerFunc) error
+
+	// AddSSE registers a Server-Sent Events endpoint for the specified app path relative path,
+	// e.g. to feed external data to the custom JavaScript code of a window or to an HTML component
+	// (subscribing with new EventSource("path")).
+	// The endpoint is registered with HandleFunc(), so the extra headers set by SetHeaders()
+	// are added to the responses, and incoming requests are logged to the logger (if set).
+	// A new source is called for each client.
+	//
+	// Note that the write timeout of the HTTP server (if any) also limits the stream duration.
+	//
+	// Example:
+	//     server.AddSSE("feeds/time", func(ctx context.Context, send func(event, data string)) {
+	//         for {
+	//             select {
+	//             case <-ctx.Done():
+	//                 return
+	//             case t := <-time.After(time.Second):
+	//                 send("time", t.Format(time.RFC3339))
+	//             }
+	//         }
+	//     })
+	AddSSE(path string, source SSESourceFunc) error
+
+	// SessionFromRequest returns the session associated with the specified request
+	// (based on the session ID cookie). If there is no valid private session
+	// associated with the request, the public session (the server) is returned.
+	//
+	// This is to be used in custom HTTP handlers (e.g. registered by HandleFunc())
+	// to access the same session the GUI windows use.
+	// Note that this does not register an access to the session (does not extend its timeout),
+	// and that custom handlers are not synchronized with GUI event handlers of the session.
+	SessionFromRequest(r *http.Request) Session
+
 	// Theme returns the default CSS theme of the server.
 	Theme() string
 
@@ -176,6 +385,23 @@ type Server interface {
 	// Logger returns the logger that is used to log incoming requests.
 	Logger() *log.Logger
 
+	// SetErrorPresenter sets the function which presents errors returned by
+	// event handlers (registered with Comp.AddEHandlerErr()) to the user.
+	// Errors are logged before they are passed to the error presenter.
+	// Pass nil to restore the default, which shows the error message
+	// in an error toast (see Event.ShowToast()).
+	SetErrorPresenter(presenter ErrorPresenterFunc)
+
+	// SetEventAuditor sets a function which is called after each dispatched event
+	// (including scheduled tasks of windows), e.g. to build audit trails
+	// (who clicked what and when) or latency dashboards.
+	// sess is the session of the event after dispatching it (which is a new session
+	// if the handlers created one, see Event.NewSession()).
+	// The auditor is called while the session is locked, so it should return quickly.
+	// Events refused by the multi-tab policy or by rate limits are not audited.
+	// Pass nil to remove the auditor. This is the default.
+	SetEventAuditor(auditor EventAuditorFunc)
+
 	// AddRootHeadHTML adds an HTML text which will be included
 	// in the HTML <head> section of the window list page (the app root).
 	// Note that these will be ignored if you take over the app root
@@ -186,6 +412,34 @@ type Server interface {
 	// that was previously added with AddRootHeadHTML().
 	RemoveRootHeadHTML(html string)
 
+	// SetWinListTitle sets the title of the window list page (the app root).
+	// If an empty string is set, the default title is used which is
+	// the server text followed by " - Window List".
+	// Note that this is ignored if you take over the app root (by calling SetAppRootHandler).
+	SetWinListTitle(title string)
+
+	// SetWinListHeader sets a component to be displayed below the title
+	// of the window list page (the app root), e.g. a logo or a welcome message.
+	// The header is rendered as static content, it does not receive events.
+	// Pass nil to remove the header.
+	// Note that this is ignored if you take over the app root (by calling SetAppRootHandler).
+	SetWinListHeader(header Comp)
+
+	// SetWinListFilter sets a filter function which decides which windows
+	// are included in the window list page (the app root).
+	// Windows for which the filter returns false are not listed,
+	// but they remain accessible by their URL.
+	// Pass nil to list all windows. This is the default.
+	// Note that this is ignored if you take over the app root (by calling SetAppRootHandler).
+	SetWinListFilter(filter func(win Window) bool)
+
+	// SetDefaultWin sets the name of the default window: if set, requests of
+	// the app root are redirected (with HTTP 302 Found) to the default window
+	// (keeping the query string) instead of rendering the window list.
+	// This takes precedence over the app root handler (see SetAppRootHandler()).
+	// Pass an empty string to render the window list again. This is the default.
+	SetDefaultWin(name string)
+
 	// SetAppRootHandler sets a function that is called when the app root is requested.
 	// The default function renders the window list, including authenticated windows
 	// and session creators - with clickable links.
@@ -199,14 +453,188 @@ type Server interface {
 	// session ID.
 	SetSessIDCookieName(name string)
 
+	// HistoryNav tells if history navigation mode is enabled.
+	HistoryNav() bool
+
+	// SetHistoryNav enables or disables history navigation mode.
+	//
+	// In history navigation mode Event.ReloadWin() called with the name
+	// of a window does not cause a full page navigation: the content of the
+	// new window is fetched and rendered over AJAX, and the new window is
+	// registered in the browser history (using history.pushState()).
+	// The Back and Forward buttons of the browser navigate between the
+	// visited Gowut windows the same way, without reloading static resources.
+	//
+	// Note that the head HTMLs and the theme of the new window are not applied,
+	// and window event handlers (e.g. ETypeWinLoad) of the new window are not
+	// called in this mode. Calling Event.ReloadWin() with an empty string
+	// still reloads the current window.
+	//
+	// History navigation mode is disabled by default.
+	SetHistoryNav(enabled bool)
+
+	// MultiTabPolicy returns the policy of windows open in multiple browser tabs.
+	MultiTabPolicy() MultiTabPolicy
+
+	// SetMultiTabPolicy sets the policy of windows open in multiple browser tabs
+	// (of the same session). Events of a tab sent to windows may conflict with
+	// the ones of another tab showing the same window with stale content.
+	//
+	// The tab a window receives events from is the owner of the window.
+	// Another tab becomes the owner if the owner tab does not send events
+	// for a minute, or if the policy is MultiTabAllow or MultiTabWarn.
+	// With MultiTabWarn, a warning toast is shown in a tab taking over
+	// an active window; with MultiTabRefuse, events of non-owner tabs
+	// are not processed, and an error toast is shown instead.
+	//
+	// Note that tabs are not notified about changes made from other tabs
+	// (there is no server push), so windows open in multiple tabs
+	// might display stale content. See Event.BroadcastDirty() to refresh
+	// other windows of the session.
+	//
+	// The default policy is MultiTabAllow. See Event.TabID().
+	SetMultiTabPolicy(policy MultiTabPolicy)
+
+	// OfflineText returns the text of the banner displayed
+	// when the connection to the server is lost.
+	OfflineText() string
+
+	// SetOfflineText sets the text of the banner displayed when the
+	// connection to the server is lost. While offline, the "gwu-Offline"
+	// style class is added to the body, events are queued and sending them
+	// is retried with increasing delays. When the connection is restored,
+	// queued events are sent and ETypeReconnect is fired on the window.
+	// Pass an empty string to not display the banner.
+	SetOfflineText(text string)
+
+	// RenderPaths tells if component paths are rendered.
+	RenderPaths() bool
+
+	// EventRateLimit returns the event rate limit of the server
+	// set by SetEventRateLimit(). n is 0 if no limit is set.
+	EventRateLimit() (n int, per time.Duration)
+
+	// SetEventRateLimit sets a rate limit for events: at most n events are
+	// dispatched per the specified duration, counted both per session and
+	// per client IP (so clients can't get around the limit by dropping the
+	// session cookie). This makes brute-forcing password forms impractical.
+	// Events of Timers are not counted. Components may override the limit
+	// with Comp.SetEventRateLimit().
+	//
+	// Events exceeding the limit are not dispatched; instead the onViolation
+	// function is called (if not nil) with the refused event.
+	//
+	// Note that clients behind the same proxy or NAT share the same IP,
+	// so the limit should be generous enough.
+	// Pass 0 for n to remove the limit.
+	SetEventRateLimit(n int, per time.Duration, onViolation RateLimitFunc)
+
+	// AccessFilter returns the access filter of the server.
+	AccessFilter() AccessFilterFunc
+
+	// SetAccessFilter sets an access filter, which is evaluated for each request
+	// before rendering windows and dispatching events (before the session
+	// of the request is looked up). Requests not allowed by the filter are
+	// refused with 403 Forbidden. Static resources of Gowut are not filtered.
+	//
+	// Filters may use ClientIP() to implement IP allowlists or denylists.
+	// Pass nil to remove the access filter.
+	SetAccessFilter(filter AccessFilterFunc)
+
+	// ClientIP returns the IP address of the client sending the request.
+	// If the request comes from a trusted proxy (see SetTrustedProxies()),
+	// the client IP is taken from the X-Forwarded-For header.
+	ClientIP(r *http.Request) string
+
+	// SetTrustedProxies sets the addresses of the trusted reverse proxies,
+	// whose X-Forwarded-For headers are accepted by ClientIP().
+	// Addresses may be IP addresses (e.g. "10.0.0.1") or networks in
+	// CIDR notation (e.g. "10.0.0.0/8").
+	// Call it without arguments to not trust any proxies (the default).
+	SetTrustedProxies(proxies ...string) error
+
+	// MaxSessionComps returns the max number of components per session
+	// set by SetMaxSessionComps(). 0 means there is no limit.
+	MaxSessionComps() int
+
+	// SetMaxSessionComps sets a guardrail for the size of the component trees
+	// of sessions: after each event the components of the session are counted,
+	// and if the number exceeds max, onExceeded is called with the session
+	// and its statistics (see Session.Stats()). onExceeded is called after
+	// each event while the limit is exceeded; it may for example log a warning
+	// or remove the session. It is called while the session is locked.
+	//
+	// Counting components walks the component trees, which costs CPU time
+	// in case of large trees. Pass 0 for max to remove the limit.
+	SetMaxSessionComps(max int, onExceeded SessionStatsFunc)
+
+	// SetMaxSessions sets the maximum number of private sessions, and the policy
+	// to apply when a new session would exceed it (or the per-IP limit set by
+	// SetMaxSessionsPerIP()): with SessOverflowReject, an error page
+	// (503 Service Unavailable) is rendered instead of creating the session;
+	// with SessOverflowEvict, the least recently accessed session (of the client IP
+	// in case of the per-IP limit) is removed to make room for the new one.
+	//
+	// The limits protect servers against session floods: they are applied
+	// when sessions are created by requesting session creator windows
+	// (see AddSessCreatorName()). Sessions created by Event.NewSession()
+	// (e.g. after a successful login) are not limited, but they are counted.
+	// Pass 0 for max to remove the limit. This is the default.
+	SetMaxSessions(max int, policy SessionOverflowPolicy)
+
+	// SetMaxSessionsPerIP sets the maximum number of private sessions
+	// per client IP address (see ClientIP()). The policy set by SetMaxSessions()
+	// is applied when the limit is reached.
+	// Pass 0 for max to remove the limit. This is the default.
+	SetMaxSessionsPerIP(max int)
+
+	// SetRenderPaths sets whether component paths (see Comp.Path()) are rendered
+	// as the "data-gwu-path" HTML attribute of the components, which can be used
+	// as stable selectors in end-to-end tests, e.g. [data-gwu-path="main/button[0]"].
+	// Rendering paths costs extra CPU time, so it is disabled by default.
+	SetRenderPaths(renderPaths bool)
+
+	// ServeHTTP serves an HTTP request addressed to the GUI server
+	// (including the static resources of Gowut), so the server
+	// can be used as an http.Handler.
+	// Start() registers the server at the default ServeMux, but ServeHTTP
+	// allows serving it without starting it, e.g. in tests (see package gwutest).
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+
+	// OnStart registers a function to be called when the server is started
+	// and its listener is bound (so it is accepting connections).
+	// The function receives the address the listener is bound to.
+	// On Google App Engine addr is nil.
+	OnStart(f func(addr net.Addr))
+
+	// Addr returns the address the listener of the server is bound to,
+	// including the actual port if the server address has port 0 (e.g. "localhost:0").
+	// Returns nil if the server has not yet been started (or on Google App Engine).
+	Addr() net.Addr
+
+	// SetOpener sets the function used by Start() and StartAsync() to open
+	// windows in a browser. By default the default browser of the user is opened
+	// (with "xdg-open", "open" or "start" depending on the OS).
+	// Custom openers may be used e.g. on WSL or remote setups;
+	// pass nil to disable opening windows (e.g. in headless CI).
+	SetOpener(opener func(url string) error)
+
 	// Start starts the GUI server and waits for incoming connections.
 	//
 	// Sessionless window names may be specified as optional parameters
-	// that will be opened in the default browser.
+	// that will be opened in the default browser (see SetOpener()).
+	// If opening any of the windows fails (e.g. no browser is found), the server
+	// is not started and the errors are returned; use SetOpener(nil) to start
+	// the server without opening windows.
 	// Tip: Pass an empty string to open the window list.
 	// Tip: Not passing any window names will start the server silently
 	// without opening any windows.
 	Start(openWins ...string) error
+
+	// StartAsync starts the GUI server like Start(), but returns as soon as
+	// its listener is bound, and serves incoming connections in a new goroutine.
+	// Errors of binding the listener are returned, later errors are logged.
+	StartAsync(openWins ...string) error
 }
 
 // Server implementation.
@@ -228,15 +656,54 @@ type serverImpl struct {
 	logger             *log.Logger        // Logger.
 	headers            http.Header        // Extra headers that will be added to all responses.
 	rootHeads          []string           // Additional head HTML texts of the window list page (app root)
+	winListTitle       string             // Title of the window list page (app root)
+	winListHeader      Comp               // Header component of the window list page (app root)
+	winListFilter      func(Window) bool  // Filter of the windows listed on the window list page (app root)
+	defaultWin         string             // Name of the default window the app root is redirected to
 	appRootHandlerFunc AppRootHandlerFunc // App root handler function
 	sessIDCookieName   string             // Session ID cookie name
+	historyNav         bool               // Tells if history navigation mode is enabled
+	errorPresenter     ErrorPresenterFunc // Event handler error presenter function
+	eventAuditor       EventAuditorFunc   // Function to call after each dispatched event
+	offlineText        string             // Text of the banner displayed when the connection is lost
+	multiTabPolicy     MultiTabPolicy     // Policy of windows open in multiple browser tabs
+	renderPaths        bool               // Tells if component paths are rendered
+	rateLimitN         int                // Max number of events per rateLimitPer, 0 if there is no limit
+	rateLimitPer       time.Duration      // Time window of the event rate limit
+	rateLimitFunc      RateLimitFunc      // Function to call when an event is refused by a rate limit
+	rateLimiter        rateLimiter        // Event counters of the rate limits
+	accessFilter       AccessFilterFunc   // Access filter of requests
+	trustedProxies     []*net.IPNet       // Trusted reverse proxies
+	maxSessComps       int                // Max number of components per session, 0 if there is no limit
+	sessCompsExceeded  SessionStatsFunc   // Function to call when a session exceeds the max number of components
+	startFuncs         []func(net.Addr)   // Functions to call when the server is started
+	listenAddr         net.Addr           // Address the listener is bound to
+	mux                *http.ServeMux     // ServeMux the server is registered at, nil means http.DefaultServeMux
+	registered         bool               // Tells if the server is registered at its ServeMux
+	handlers           *http.ServeMux     // Handlers registered by AddStaticDir() and HandleFunc(). Lazily initialized.
+	handlerPaths       map[string]bool    // Paths registered at handlers
+	opener             func(string) error // Function to open windows in a browser
+
+	sessWinTemplates map[string]func(sess Session) Window // Session window template build functions mapped from window name
+
+	expiringFuncs  []func(Session, time.Duration) // Functions to call when sessions are about to expire
+	expiringBefore time.Duration                  // Time before the expiry of sessions when expiringFuncs are called
+	expiringSeen   map[string]time.Time           // Last access times of sessions expiringFuncs were called for, mapped from session ID
+
+	maxSessions      int                   // Max number of private sessions, 0 if there is no limit
+	maxSessionsPerIP int                   // Max number of private sessions per client IP, 0 if there is no limit
+	sessOverflow     SessionOverflowPolicy // Policy to apply when a session limit is reached
 
 	sessMux sync.RWMutex // Mutex to protect state related to session handling
+
+	downloads map[string]*pendingDownload // Pending file downloads mapped from download token
+	dlMux     sync.Mutex                  // Mutex to protect pending file downloads
 }
 
 // NewServer creates a new GUI server in HTTP mode.
 // The specified app name will be part of the application path (the first part).
 // If addr is empty string, "localhost:3434" will be used.
+// If the port of addr is 0 (e.g. "localhost:0"), a port is chosen when the server is started.
 //
 // Tip: Pass an empty string as appName to place the GUI server to the root path ("/").
 func NewServer(appName, addr string) Server {
@@ -246,6 +713,7 @@ func NewServer(appName, addr string) Server {
 // NewServerTLS creates a new GUI server in secure (HTTPS) mode.
 // The specified app name will be part of the application path (the first part).
 // If addr is empty string, "localhost:3434" will be used.
+// If the port of addr is 0 (e.g. "localhost:0"), a port is chosen when the server is started.
 //
 // Tip: Pass an empty string as appName to place the GUI server to the root path ("/").
 // Tip: You can use generate_cert.go in crypto/tls to generate
@@ -266,8 +734,14 @@ func newServerImpl(appName, addr, certFile, keyFile string) *serverImpl {
 		addr:             addr,
 		sessions:         make(map[string]Session),
 		sessCreatorNames: make(map[string]string),
+		sessWinTemplates: make(map[string]func(sess Session) Window),
+		downloads:        make(map[string]*pendingDownload),
 		theme:            ThemeDefault,
 		sessIDCookieName: defaultSessIDCookieName,
+		opener:           open,
+		offlineText:      defaultOfflineText,
+		expiringBefore:   defaultExpiringBefore,
+		expiringSeen:     make(map[string]time.Time),
 	}
 
 	if s.appName == "" {
@@ -276,25 +750,31 @@ func newServerImpl(appName, addr, certFile, keyFile string) *serverImpl {
 		s.appPath = "/" + s.appName + "/"
 	}
 
-	if certFile == "" || keyFile == "" {
-		s.secure = false
-		s.appURLString = "http://" + addr + s.appPath
-	} else {
+	if certFile != "" && keyFile != "" {
 		s.secure = true
-		s.appURLString = "https://" + addr + s.appPath
 		s.certFile = certFile
 		s.keyFile = keyFile
 	}
-	var err error
-	if s.appURL, err = url.Parse(s.appURLString); err != nil {
-		panic(fmt.Sprintf("Parse %q: %+v", s.appURLString, err))
-	}
+	s.setAppURL(addr)
 
 	s.appRootHandlerFunc = s.renderWinList
 
 	return s
 }
 
+// setAppURL sets the application URL from the specified server address.
+func (s *serverImpl) setAppURL(addr string) {
+	if s.secure {
+		s.appURLString = "https://" + addr + s.appPath
+	} else {
+		s.appURLString = "http://" + addr + s.appPath
+	}
+	var err error
+	if s.appURL, err = url.Parse(s.appURLString); err != nil {
+		panic(fmt.Sprintf("Parse %q: %+v", s.appURLString, err))
+	}
+}
+
 func (s *serverImpl) Secure() bool {
 	return s.secure
 }
@@ -313,17 +793,94 @@ func (s *serverImpl) AddSessCreatorName(name, text string) {
 	}
 }
 
+func (s *serverImpl) AddSessWindowTemplate(name, text string, build func(sess Session) Window) {
+	if len(name) > 0 {
+		s.sessCreatorNames[name] = text
+		s.sessWinTemplates[name] = build
+	}
+}
+
+// buildSessWin builds the session window of the template registered
+// under the specified name, and adds it to the specified session.
+// Returns the added window, or nil if no template is registered
+// with the name or the build function returned nil.
+func (s *serverImpl) buildSessWin(sess Session, name string) Window {
+	build := s.sessWinTemplates[name]
+	if build == nil {
+		return nil
+	}
+
+	win := build(sess)
+	if win == nil {
+		return nil
+	}
+	win.SetName(name)
+	if err := sess.AddWin(win); err != nil {
+		return nil
+	}
+	return win
+}
+
 func (s *serverImpl) AddSHandler(handler SessionHandler) {
 	s.sessMux.Lock()
 	s.sessionHandlers = append(s.sessionHandlers, handler)
 	s.sessMux.Unlock()
 }
 
+func (s *serverImpl) OnSessionExpiring(f func(sess Session, remaining time.Duration)) {
+	s.sessMux.Lock()
+	s.expiringFuncs = append(s.expiringFuncs, f)
+	s.sessMux.Unlock()
+}
+
+func (s *serverImpl) SetSessExpiringBefore(before time.Duration) {
+	s.sessMux.Lock()
+	s.expiringBefore = before
+	s.sessMux.Unlock()
+}
+
+func (s *serverImpl) Sessions() []SessionInfo {
+	s.sessMux.RLock()
+	sessions := make([]Session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.sessMux.RUnlock()
+
+	// Sessions are locked without holding sessMux (event handlers lock them in the reverse order)
+	infos := make([]SessionInfo, len(sessions))
+	for i, sess := range sessions {
+		infos[i] = sess.info()
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Created.Before(infos[j].Created) })
+	return infos
+}
+
+func (s *serverImpl) RemoveSession(id string) bool {
+	s.sessMux.Lock()
+	defer s.sessMux.Unlock()
+
+	sess := s.sessions[id]
+	if sess == nil {
+		return false
+	}
+	s.removeSess2(sess)
+	return true
+}
+
 // newSession creates a new (private) Session.
 // The event is optional. If specified and the current session
 // (as returned by Event.Session()) is private, it will be removed first.
 // The new session is set to the event, and also returned.
 func (s *serverImpl) newSession(e *eventImpl) Session {
+	return s.newSession2(e, "")
+}
+
+// newSession2 creates a new (private) Session like newSession().
+// If ip is not empty, the new session is created for the client IP
+// and the session limits are applied; nil is returned if the session
+// limits do not allow creating a new session.
+func (s *serverImpl) newSession2(e *eventImpl, ip string) Session {
 	if e != nil {
 		// First remove old session
 		s.removeSess(e)
@@ -331,11 +888,23 @@ func (s *serverImpl) newSession(e *eventImpl) Session {
 
 	sessImpl := newSessionImpl(true)
 	sess := &sessImpl
+	sess.addr = ip
+
+	s.sessMux.Lock()
+	if ip != "" && !s.admitSession(ip) {
+		s.sessMux.Unlock()
+		if s.logger != nil {
+			s.logger.Println("SESSION limit reached, refused client:", ip)
+		} else {
+			log.Println("SESSION limit reached, refused client:", ip)
+		}
+		return nil
+	}
+
 	if e != nil {
 		e.shared.session = sess
 	}
 	// Store new session
-	s.sessMux.Lock()
 	s.sessions[sess.ID()] = sess
 
 	if s.logger != nil {
@@ -344,6 +913,11 @@ func (s *serverImpl) newSession(e *eventImpl) Session {
 		log.Println("SESSION created:", sess.ID())
 	}
 
+	// Instantiate session window templates
+	for name := range s.sessWinTemplates {
+		s.buildSessWin(sess, name)
+	}
+
 	// Notify session handlers
 	for _, handler := range s.sessionHandlers {
 		handler.Created(sess)
@@ -353,6 +927,44 @@ func (s *serverImpl) newSession(e *eventImpl) Session {
 	return sess
 }
 
+// admitSession applies the session limits before creating a new session for
+// the specified client IP. Sessions may be evicted according to the overflow policy.
+// Returns false if the new session must not be created.
+// serverImpl.sessMux must be locked when this is called.
+// Sessions are not locked here (event handlers lock them before sessMux),
+// only their attributes mutex is used to read their access times and addresses.
+func (s *serverImpl) admitSession(ip string) bool {
+	for _, limit := range []struct {
+		max int
+		ip  string // Client IP the limit applies to, empty for all sessions
+	}{{s.maxSessions, ""}, {s.maxSessionsPerIP, ip}} {
+		if limit.max <= 0 {
+			continue
+		}
+		for {
+			var count int
+			var oldest Session
+			for _, sess := range s.sessions {
+				if limit.ip != "" && sess.remoteAddr() != limit.ip {
+					continue
+				}
+				count++
+				if oldest == nil || sess.Accessed().Before(oldest.Accessed()) {
+					oldest = sess
+				}
+			}
+			if count < limit.max {
+				break
+			}
+			if s.sessOverflow != SessOverflowEvict {
+				return false
+			}
+			s.removeSess2(oldest) // Evict the least recently accessed session
+		}
+	}
+	return true
+}
+
 // removeSess removes (invalidates) the current session of the specified event.
 // Only private sessions can be removed, calling this
 // when the current session (as returned by Event.Session()) is public is a no-op.
@@ -383,6 +995,7 @@ func (s *serverImpl) removeSess2(sess Session) {
 			handler.Removed(sess)
 		}
 		delete(s.sessions, sess.ID())
+		delete(s.expiringSeen, sess.ID())
 	}
 }
 
@@ -412,20 +1025,54 @@ func (s *serverImpl) addSessCookie(sess Session, w http.ResponseWriter) {
 func (s *serverImpl) sessCleaner() {
 	sleep := 10 * time.Second
 	for {
-		now := time.Now()
+		s.cleanSessions(time.Now())
 
-		s.sessMux.Lock()
-		for _, sess := range s.sessions {
-			if now.Sub(sess.Accessed()) > sess.Timeout() {
-				s.removeSess2(sess)
-			}
-		}
-		s.sessMux.Unlock()
+		s.removeExpiredDownloads()
 
 		time.Sleep(sleep)
 	}
 }
 
+// cleanSessions removes the private sessions which have timed out,
+// and calls the functions registered by OnSessionExpiring() for
+// sessions which are about to expire.
+func (s *serverImpl) cleanSessions(now time.Time) {
+	type expiring struct {
+		sess      Session
+		remaining time.Duration
+	}
+	var expirings []expiring
+
+	s.sessMux.Lock()
+	for _, sess := range s.sessions {
+		idle := now.Sub(sess.Accessed())
+		if idle > sess.Timeout() {
+			s.removeSess2(sess)
+			continue
+		}
+		if len(s.expiringFuncs) == 0 || idle < sess.Timeout()-s.expiringBefore {
+			continue
+		}
+		if seen, ok := s.expiringSeen[sess.ID()]; ok && seen.Equal(sess.Accessed()) {
+			continue // Already called in this idle period
+		}
+		s.expiringSeen[sess.ID()] = sess.Accessed()
+		expirings = append(expirings, expiring{sess, sess.Timeout() - idle})
+	}
+	funcs := s.expiringFuncs
+	s.sessMux.Unlock()
+
+	// Functions are called without holding sessMux, so they may access the server
+	for _, e := range expirings {
+		rwMutex := e.sess.rwMutex()
+		rwMutex.Lock()
+		for _, f := range funcs {
+			f(e.sess, e.remaining)
+		}
+		rwMutex.Unlock()
+	}
+}
+
 func (s *serverImpl) SetHeaders(headers map[string][]string) {
 	s.headers = make(map[string][]string, len(headers))
 	for k, v := range headers {
@@ -453,6 +1100,23 @@ func (s *serverImpl) addHeaders(w http.ResponseWriter) {
 	}
 }
 
+// addWinHeaders adds the extra headers of the specified window to the response,
+// replacing the values of headers having the same name.
+func addWinHeaders(win Window, w http.ResponseWriter) {
+	wi, ok := win.(*windowImpl)
+	if !ok || len(wi.headers) == 0 {
+		return
+	}
+
+	header := w.Header()
+	for k, v := range wi.headers {
+		header.Del(k)
+		for _, v2 := range v {
+			header.Add(k, v2)
+		}
+	}
+}
+
 func (s *serverImpl) AddStaticDir(path, dir string) error {
 	if strings.HasPrefix(path, "/") {
 		path = path[1:]
@@ -476,14 +1140,106 @@ func (s *serverImpl) AddStaticDir(path, dir string) error {
 
 	handler := http.StripPrefix(path, http.FileServer(http.Dir(dir)))
 	// To include extra headers in the response of static handler:
-	http.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+	return s.handle(path, func(w http.ResponseWriter, r *http.Request) {
 		s.addHeaders(w)
 		handler.ServeHTTP(w, r)
 	})
+}
+
+func (s *serverImpl) HandleFunc(path string, h http.HandlerFunc) error {
+	path = strings.TrimPrefix(path, "/")
+
+	if path == "" {
+		return errors.New("path cannot be empty string")
+	}
+
+	if strings.HasPrefix(path, pathStatic) || strings.HasPrefix(path, pathSessCheck) {
+		return errors.New("Path cannot be '" + path + "' (reserved)!")
+	}
+
+	name := path
+	if i := strings.IndexByte(name, '/'); i >= 0 {
+		name = name[:i]
+	}
+	if s.WinByName(name) != nil || s.sessWinTemplates[name] != nil {
+		return errors.New("Path cannot be '" + path + "' (collides with window name)!")
+	}
+
+	return s.handle(s.appPath+path, func(w http.ResponseWriter, r *http.Request) {
+		if s.logger != nil {
+			s.logger.Println("Incoming:", r.URL.Path)
+		}
+		s.addHeaders(w)
+		h(w, r)
+	})
+}
 
+// handle registers a handler for the specified (absolute) path pattern,
+// which will be served by ServeHTTP() before the windows of the server.
+// Returns an error if the path is already registered.
+func (s *serverImpl) handle(path string, h http.HandlerFunc) error {
+	if s.handlerPaths[path] {
+		return errors.New("A handler has already been registered for path: " + path)
+	}
+	if s.handlers == nil {
+		s.handlers = http.NewServeMux()
+		s.handlerPaths = make(map[string]bool)
+	}
+	s.handlerPaths[path] = true
+	s.handlers.HandleFunc(path, h)
 	return nil
 }
 
+func (s *serverImpl) AddSSE(path string, source SSESourceFunc) error {
+	return s.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		h := w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("X-Accel-Buffering", "no") // Disable proxy buffering (e.g. nginx)
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		var mux sync.Mutex
+		done := false // Tells if the handler returned, protected by mux
+		buf := &bytes.Buffer{}
+		send := func(event, data string) {
+			mux.Lock()
+			defer mux.Unlock()
+
+			if done || r.Context().Err() != nil {
+				return // Handler returned or client disconnected
+			}
+			buf.Reset()
+			if event != "" {
+				buf.WriteString("event: ")
+				buf.WriteString(event)
+				buf.WriteByte('\n')
+			}
+			for _, line := range strings.Split(data, "\n") {
+				buf.WriteString("data: ")
+				buf.WriteString(strings.TrimSuffix(line, "\r"))
+				buf.WriteByte('\n')
+			}
+			buf.WriteByte('\n')
+			w.Write(buf.Bytes())
+			flusher.Flush()
+		}
+
+		source(r.Context(), send)
+
+		// Sends from other goroutines must not write the response after the handler returned
+		mux.Lock()
+		done = true
+		mux.Unlock()
+	})
+}
+
 func (s *serverImpl) Theme() string {
 	return s.theme
 }
@@ -500,6 +1256,14 @@ func (s *serverImpl) Logger() *log.Logger {
 	return s.logger
 }
 
+func (s *serverImpl) SetErrorPresenter(presenter ErrorPresenterFunc) {
+	s.errorPresenter = presenter
+}
+
+func (s *serverImpl) SetEventAuditor(auditor EventAuditorFunc) {
+	s.eventAuditor = auditor
+}
+
 func (s *serverImpl) AddRootHeadHTML(html string) {
 	s.rootHeads = append(s.rootHeads, html)
 }
@@ -515,6 +1279,22 @@ func (s *serverImpl) RemoveRootHeadHTML(html string) {
 	}
 }
 
+func (s *serverImpl) SetWinListTitle(title string) {
+	s.winListTitle = title
+}
+
+func (s *serverImpl) SetWinListHeader(header Comp) {
+	s.winListHeader = header
+}
+
+func (s *serverImpl) SetWinListFilter(filter func(win Window) bool) {
+	s.winListFilter = filter
+}
+
+func (s *serverImpl) SetDefaultWin(name string) {
+	s.defaultWin = name
+}
+
 func (s *serverImpl) SetAppRootHandler(f AppRootHandlerFunc) {
 	s.appRootHandlerFunc = f
 }
@@ -527,6 +1307,142 @@ func (s *serverImpl) SetSessIDCookieName(name string) {
 	s.sessIDCookieName = name
 }
 
+func (s *serverImpl) HistoryNav() bool {
+	return s.historyNav
+}
+
+func (s *serverImpl) SetHistoryNav(enabled bool) {
+	s.historyNav = enabled
+}
+
+func (s *serverImpl) MultiTabPolicy() MultiTabPolicy {
+	return s.multiTabPolicy
+}
+
+func (s *serverImpl) SetMultiTabPolicy(policy MultiTabPolicy) {
+	s.multiTabPolicy = policy
+}
+
+func (s *serverImpl) OfflineText() string {
+	return s.offlineText
+}
+
+func (s *serverImpl) SetOfflineText(text string) {
+	s.offlineText = text
+}
+
+func (s *serverImpl) RenderPaths() bool {
+	return s.renderPaths
+}
+
+func (s *serverImpl) SetMaxSessions(max int, policy SessionOverflowPolicy) {
+	s.sessMux.Lock()
+	s.maxSessions, s.sessOverflow = max, policy
+	s.sessMux.Unlock()
+}
+
+func (s *serverImpl) SetMaxSessionsPerIP(max int) {
+	s.sessMux.Lock()
+	s.maxSessionsPerIP = max
+	s.sessMux.Unlock()
+}
+
+func (s *serverImpl) SetRenderPaths(renderPaths bool) {
+	s.renderPaths = renderPaths
+}
+
+func (s *serverImpl) OnStart(f func(addr net.Addr)) {
+	s.startFuncs = append(s.startFuncs, f)
+}
+
+func (s *serverImpl) Addr() net.Addr {
+	return s.listenAddr
+}
+
+func (s *serverImpl) SetOpener(opener func(url string) error) {
+	s.opener = opener
+}
+
+// started calls the registered start functions with the bound address.
+// If the port of the server address is 0, the application URL is updated
+// to contain the port chosen by the listener.
+func (s *serverImpl) started(addr net.Addr) {
+	s.listenAddr = addr
+	if addr != nil {
+		if host, port, err := net.SplitHostPort(s.addr); err == nil && port == "0" {
+			if _, port, err = net.SplitHostPort(addr.String()); err == nil {
+				s.setAppURL(net.JoinHostPort(host, port))
+			}
+		}
+	}
+	for _, f := range s.startFuncs {
+		f(addr)
+	}
+}
+
+func (s *serverImpl) EventRateLimit() (n int, per time.Duration) {
+	return s.rateLimitN, s.rateLimitPer
+}
+
+func (s *serverImpl) SetEventRateLimit(n int, per time.Duration, onViolation RateLimitFunc) {
+	if n <= 0 || per <= 0 {
+		n, per = 0, 0
+	}
+	s.rateLimitN, s.rateLimitPer, s.rateLimitFunc = n, per, onViolation
+}
+
+func (s *serverImpl) AccessFilter() AccessFilterFunc {
+	return s.accessFilter
+}
+
+func (s *serverImpl) SetAccessFilter(filter AccessFilterFunc) {
+	s.accessFilter = filter
+}
+
+func (s *serverImpl) SetTrustedProxies(proxies ...string) error {
+	nets, err := parseProxies(proxies)
+	if err != nil {
+		return err
+	}
+	s.trustedProxies = nets
+	return nil
+}
+
+func (s *serverImpl) MaxSessionComps() int {
+	return s.maxSessComps
+}
+
+func (s *serverImpl) SetMaxSessionComps(max int, onExceeded SessionStatsFunc) {
+	if max < 0 {
+		max = 0
+	}
+	s.maxSessComps, s.sessCompsExceeded = max, onExceeded
+}
+
+// newWriter returns a new Writer for rendering components,
+// configured according to the server settings.
+func (s *serverImpl) newWriter(w io.Writer, sess Session) Writer {
+	wi := NewWriter(w).(writerImpl)
+	wi.paths = s.renderPaths
+	wi.sess = sess
+	return wi
+}
+
+func (s *serverImpl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.handlers != nil {
+		if h, pattern := s.handlers.Handler(r); pattern != "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	if strings.HasPrefix(r.URL.Path, s.appPath+pathStatic) {
+		s.serveStatic(w, r)
+	} else {
+		s.serveHTTP(w, r)
+	}
+}
+
 // serveStatic handles the static contents of GWU.
 func (s *serverImpl) serveStatic(w http.ResponseWriter, r *http.Request) {
 	s.addHeaders(w)
@@ -574,6 +1490,19 @@ func (s *serverImpl) serveStatic(w http.ResponseWriter, r *http.Request) {
 	http.NotFound(w, r)
 }
 
+func (s *serverImpl) SessionFromRequest(r *http.Request) Session {
+	var sess Session
+	if c, err := r.Cookie(s.sessIDCookieName); err == nil {
+		s.sessMux.RLock()
+		sess = s.sessions[c.Value]
+		s.sessMux.RUnlock()
+	}
+	if sess == nil {
+		sess = &s.sessionImpl
+	}
+	return sess
+}
+
 // serveHTTP handles the incoming requests.
 // Renders of the URL-selected window,
 // and also handles event dispatching.
@@ -584,18 +1513,17 @@ func (s *serverImpl) serveHTTP(w http.ResponseWriter, r *http.Request) {
 
 	s.addHeaders(w)
 
-	// Check session
-	var sess Session
-	c, err := r.Cookie(s.sessIDCookieName)
-	if err == nil {
-		s.sessMux.RLock()
-		sess = s.sessions[c.Value]
-		s.sessMux.RUnlock()
-	}
-	if sess == nil {
-		sess = &s.sessionImpl
+	if s.accessFilter != nil && !s.accessFilter(r) {
+		if s.logger != nil {
+			s.logger.Println("\tAccess denied:", s.ClientIP(r))
+		}
+		http.Error(w, "Access denied!", http.StatusForbidden)
+		return
 	}
 
+	// Check session
+	sess := s.SessionFromRequest(r)
+
 	// Parts example: "/appname/winname/e?et=0&cid=1" => {"", "appname", "winname", "e"}
 	parts := strings.Split(r.URL.Path, "/")
 
@@ -628,7 +1556,15 @@ func (s *serverImpl) serveHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if len(parts) < 1 || parts[0] == "" {
-		// Missing window name, render window list
+		// Missing window name, redirect to the default window or render window list
+		if s.defaultWin != "" {
+			target := s.appPath + s.defaultWin
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusFound)
+			return
+		}
 		s.appRootHandlerFunc(w, r, sess)
 		return
 	}
@@ -645,10 +1581,25 @@ func (s *serverImpl) serveHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// If still not found on a private session, try the session window templates
+	if win == nil && sess.Private() {
+		if _, found := s.sessWinTemplates[winName]; found {
+			rwMutex := sess.rwMutex()
+			rwMutex.Lock()
+			if win = sess.WinByName(winName); win == nil {
+				win = s.buildSessWin(sess, winName)
+			}
+			rwMutex.Unlock()
+		}
+	}
+
 	// If still not found and no private session, try the session creator names
 	if win == nil && !sess.Private() {
 		if _, found := s.sessCreatorNames[winName]; found {
-			sess = s.newSession(nil)
+			if sess = s.newSession2(nil, s.ClientIP(r)); sess == nil {
+				http.Error(w, "Too many sessions, please try again later.", http.StatusServiceUnavailable)
+				return
+			}
 			s.addSessCookie(sess, w)
 			// Search again in the new session as SessionHandlers may have added windows.
 			win = sess.WinByName(winName)
@@ -656,6 +1607,11 @@ func (s *serverImpl) serveHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if win == nil {
+		if len(parts) >= 2 && parts[1] == pathEvent {
+			// Event from a window that no longer exists (e.g. a page restored from the browser cache)
+			writeWinExpired(w)
+			return
+		}
 		// Invalid window name, render an error message with a link to the window list
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		w.WriteHeader(http.StatusNotFound)
@@ -663,13 +1619,26 @@ func (s *serverImpl) serveHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sess.access()
-
 	var path string
 	if len(parts) >= 2 {
 		path = parts[1]
 	}
 
+	// Events register access when the event source is known, and re-renders
+	// following events which did not register access (e.g. timers with SetKeepAlive(false)) don't either
+	if path != pathEvent && !(path == pathRenderComp && r.FormValue(paramNoAccess) != "") {
+		sess.access()
+	}
+
+	if sess.Private() && sess.Locale() == "" {
+		sess.SetLocale(parseAcceptLanguage(r.Header.Get("Accept-Language")))
+	}
+	if sess.Private() {
+		sess.setRemoteAddr(s.ClientIP(r))
+	}
+
+	addWinHeaders(win, w)
+
 	rwMutex := sess.rwMutex()
 	switch path {
 	case pathEvent:
@@ -682,28 +1651,94 @@ func (s *serverImpl) serveHTTP(w http.ResponseWriter, r *http.Request) {
 		defer rwMutex.RUnlock()
 
 		// Render just a component
-		s.renderComp(win, w, r)
+		s.renderComp(sess, win, w, r)
+	case pathRenderWin:
+		rwMutex.RLock()
+		defer rwMutex.RUnlock()
+
+		// Render the content of the window (without the HTML document)
+		s.renderWin(sess, win, w)
+	case pathDownload:
+		if r.FormValue(paramDownloadToken) != "" {
+			// Pending downloads are not part of the session, no need to lock
+			s.servePendingDownload(w, r)
+			return
+		}
+
+		rwMutex.RLock()
+		defer rwMutex.RUnlock()
+
+		s.serveCompDownload(win, w, r)
 	default:
 		rwMutex.RLock()
 		defer rwMutex.RUnlock()
 
 		// Render the whole window
-		win.RenderWin(NewWriter(w), s)
+		if wi, ok := win.(*windowImpl); ok && wi.pageCache {
+			if s.serveCachedPage(wi, w, r) {
+				return
+			}
+		}
+		win.RenderWin(s.newWriter(w, sess), s)
 	}
 }
 
+// serveCachedPage serves the cached page of a window.
+// Returns false if the page can't be served from the cache.
+func (s *serverImpl) serveCachedPage(win *windowImpl, w http.ResponseWriter, r *http.Request) bool {
+	gzipped := strings.Contains(r.Header.Get("Accept-Encoding"), "gzip")
+	page := win.cachedPage(s, gzipped)
+	if page == nil {
+		return false
+	}
+
+	h := w.Header()
+	h.Set("ETag", page.etag)
+	h.Set("Vary", "Accept-Encoding")
+	if h.Get("Cache-Control") == "" {
+		h.Set("Cache-Control", "no-cache") // Browsers must revalidate their copy
+	}
+	if r.Header.Get("If-None-Match") == page.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	if gzipped {
+		h.Set("Content-Encoding", "gzip")
+		w.Write(page.gzip)
+	} else {
+		w.Write(page.html)
+	}
+	return true
+}
+
 // renderWinList builds a temporary Window, adds links to the windows of
 // a session, and renders the Window.
 func (s *serverImpl) renderWinList(wr http.ResponseWriter, r *http.Request, sess Session) {
 	if s.logger != nil {
 		s.logger.Println("\tRendering windows list.")
 	}
-	win := NewWindow("windowList", s.text+" - Window List")
+	title := s.winListTitle
+	if title == "" {
+		title = s.text + " - Window List"
+	}
+	win := NewWindow("windowList", title)
+	for _, head := range s.rootHeads {
+		win.AddHeadHTML(head)
+	}
 
-	titleLabel := NewLabel(s.text + " - Window List")
+	titleLabel := NewLabel(title)
 	titleLabel.Style().SetFontWeight(FontWeightBold).SetFontSize("1.3em")
 	win.Add(titleLabel)
 
+	if s.winListHeader != nil {
+		// The header is not added to our temporary window, it might be rendered concurrently
+		buf := &bytes.Buffer{}
+		s.winListHeader.Render(NewWriter(buf))
+		win.Add(NewHTML(buf.String()))
+	}
+
 	addLinks := func(title string, nameTexts [][2]string) {
 		if len(nameTexts) == 0 {
 			return
@@ -739,16 +1774,19 @@ func (s *serverImpl) renderWinList(wr http.ResponseWriter, r *http.Request, sess
 		}
 		nameTexts = nameTexts[:0]
 		for _, win := range session.SortedWins() {
+			if s.winListFilter != nil && !s.winListFilter(win) {
+				continue
+			}
 			nameTexts = append(nameTexts, [2]string{win.Name(), win.Text()})
 		}
 		addLinks(text, nameTexts)
 	}
 
-	win.RenderWin(NewWriter(wr), s)
+	win.RenderWin(s.newWriter(wr, sess), s)
 }
 
 // renderComp renders just a component.
-func (s *serverImpl) renderComp(win Window, w http.ResponseWriter, r *http.Request) {
+func (s *serverImpl) renderComp(sess Session, win Window, w http.ResponseWriter, r *http.Request) {
 	id, err := AtoID(r.FormValue(paramCompID))
 	if err != nil {
 		http.Error(w, "Invalid component id!", http.StatusBadRequest)
@@ -765,12 +1803,73 @@ func (s *serverImpl) renderComp(win Window, w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Render into a buffer, so a failing render does not send a broken partial response
+	buf := &bytes.Buffer{}
+	if err := renderSafe(comp, s.newWriter(buf, sess)); err != nil {
+		if s.logger != nil {
+			s.logger.Println("Comp render error:", err)
+		} else {
+			log.Println("Comp render error:", err)
+		}
+		// Tell the client to reload the whole window
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set(headerActions, "1")
+		w.WriteHeader(http.StatusInternalServerError)
+		NewWriter(w).Writevs(eraReloadWin, strComma)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8") // We send it as text!
+	w.Write(buf.Bytes())
+}
+
+// renderSafe renders a component, and recovers from a panic
+// that might occur during rendering, returning it as an error.
+func renderSafe(comp Comp, w Writer) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic rendering component %v: %v", comp.ID(), r)
+		}
+	}()
+
+	comp.Render(w)
+	return nil
+}
+
+// renderWin renders the content of a window without the enclosing HTML document.
+// The title of the window and the component to be focused are sent in response headers.
+func (s *serverImpl) renderWin(sess Session, win Window, w http.ResponseWriter) {
+	if s.logger != nil {
+		s.logger.Println("\tRendering win content:", win.Name())
+	}
+
+	w.Header().Set(headerWinTitle, url.PathEscape(win.Text()))
+	w.Header().Set(headerFocusCompID, win.FocusedCompID().String())
+	if wi, ok := win.(*windowImpl); ok {
+		w.Header().Set(headerWinNonce, wi.nonce)
+	}
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8") // We send it as text!
-	comp.Render(NewWriter(w))
+	wr := s.newWriter(w, sess)
+	win.Render(wr)
+	if wi, ok := win.(*windowImpl); ok {
+		wi.popup.Render(wr)
+	}
 }
 
 // handleEvent handles the event dispatching.
 func (s *serverImpl) handleEvent(sess Session, win Window, wr http.ResponseWriter, r *http.Request) {
+	// Stale pages (e.g. restored from the browser's back-forward cache) may refer
+	// to components of a previous window instance, do not dispatch their events.
+	if wi, ok := win.(*windowImpl); ok {
+		if nonce := r.FormValue(paramWinNonce); nonce != "" && nonce != wi.nonce {
+			if s.logger != nil {
+				s.logger.Println("\tWindow expired:", win.Name())
+			}
+			writeWinExpired(wr)
+			return
+		}
+	}
+
 	focCompID, err := AtoID(r.FormValue(paramFocusedCompID))
 	if err == nil {
 		win.SetFocusedCompID(focCompID)
@@ -791,13 +1890,19 @@ func (s *serverImpl) handleEvent(sess Session, win Window, wr http.ResponseWrite
 		return
 	}
 
+	if t, isTimer := comp.(Timer); !isTimer || t.KeepAlive() {
+		sess.access()
+	} else {
+		wr.Header().Set(headerNoAccess, "1") // Re-renders of dirty components must not register access either
+	}
+
 	etype := parseIntParam(r, paramEventType)
 	if etype < 0 {
 		http.Error(wr, "Invalid event type!", http.StatusBadRequest)
 		return
 	}
 	if s.logger != nil {
-		s.logger.Println("\tEvent from comp:", id, " event:", etype)
+		s.logger.Println("\tEvent from comp:", id, comp.Path(), " event:", etype)
 	}
 
 	event := newEventImpl(EventType(etype), comp, s, sess, wr, r)
@@ -808,18 +1913,101 @@ func (s *serverImpl) handleEvent(sess Session, win Window, wr http.ResponseWrite
 		event.y = parseIntParam(r, paramMouseY)
 		shared.wx = parseIntParam(r, paramMouseWX)
 		shared.wy = parseIntParam(r, paramMouseWY)
+		shared.px = parseIntParam(r, paramMousePX)
+		shared.py = parseIntParam(r, paramMousePY)
 		shared.mbtn = MouseBtn(parseIntParam(r, paramMouseBtn))
 	} else {
-		event.y, shared.wx, shared.wy, shared.mbtn = -1, -1, -1, -1
+		event.y, shared.wx, shared.wy, shared.px, shared.py, shared.mbtn = -1, -1, -1, -1, -1, -1
+	}
+	if event.rw = parseIntParam(r, paramSrcRectW); event.rw >= 0 {
+		event.rh = parseIntParam(r, paramSrcRectH)
+		// Coordinates of the rect may be negative (if scrolled out of the page)
+		event.rx, _ = strconv.Atoi(r.FormValue(paramSrcRectX))
+		event.ry, _ = strconv.Atoi(r.FormValue(paramSrcRectY))
 	}
 
 	shared.modKeys = parseIntParam(r, paramModKeys)
 	shared.keyCode = Key(parseIntParam(r, paramKeyCode))
+	shared.keyText = r.FormValue(paramKeyText)
+	shared.keyRep = r.FormValue(paramKeyRepeat) == "1"
+	event.selStart = parseIntParam(r, paramSelStart)
+	event.selEnd = parseIntParam(r, paramSelEnd)
+
+	switch event.etype {
+	case ETypeWheel:
+		shared.wheelDX, _ = strconv.Atoi(r.FormValue(paramWheelDX))
+		shared.wheelDY, _ = strconv.Atoi(r.FormValue(paramWheelDY))
+	case ETypeCut, ETypeCopy, ETypePaste:
+		shared.clip = r.FormValue(paramClipboard)
+	case ETypeScroll:
+		shared.scrollX, _ = strconv.Atoi(r.FormValue(paramScrollX))
+		shared.scrollY, _ = strconv.Atoi(r.FormValue(paramScrollY))
+		shared.scrollMaxX, _ = strconv.Atoi(r.FormValue(paramScrollMaxX))
+		shared.scrollMaxY, _ = strconv.Atoi(r.FormValue(paramScrollMaxY))
+	}
+
+	tabOK := true
+	if wi, ok := win.(*windowImpl); ok {
+		tabOK = s.checkTab(wi, event)
+	}
+
+	if tabOK {
+		tabOK = s.checkRateLimit(comp, event)
+	}
+
+	if tabOK {
+		tabOK = permitted(comp, sess)
+	}
+
+	// Scheduled tasks and broadcast refreshes of the window are sent as state change events of the window
+	var task func(e Event)
+	if wi, ok := comp.(*windowImpl); ok && tabOK && event.etype == ETypeStateChange {
+		if value := r.FormValue(paramCompValue); value == broadcastValue {
+			task = wi.takeBroadcast
+		} else {
+			task = wi.takeTask(value)
+		}
+	}
+
+	// Events refused by the multi-tab policy, a rate limit or a permission predicate are not dispatched
+	if tabOK {
+		// Label the work so it can be attributed to windows and sessions in profiles
+		labels := pprof.Labels("gwu.window", win.Name(), "gwu.session", sessLabel(sess))
+		start := time.Now()
+		pprof.Do(r.Context(), labels, func(context.Context) {
+			if task != nil {
+				task(event)
+			} else {
+				comp.preprocessEvent(event, r)
 
-	comp.preprocessEvent(event, r)
+				// Dispatch event...
+				comp.dispatchEvent(event)
 
-	// Dispatch event...
-	comp.dispatchEvent(event)
+				// ...and call the fallback handler of the window if the component has no handlers for it
+				if wi, ok := win.(*windowImpl); ok && wi.fallback != nil {
+					if hh, ok := comp.(interface{ hasHandlers(EventType) bool }); ok && !hh.hasHandlers(event.etype) {
+						wi.fallback.HandleEvent(event)
+					}
+				}
+			}
+		})
+		if s.eventAuditor != nil {
+			s.eventAuditor(shared.session, win, comp, event.etype, time.Since(start))
+		}
+	}
+
+	if s.maxSessComps > 0 && s.sessCompsExceeded != nil {
+		if stats := shared.session.Stats(); stats.Comps > s.maxSessComps {
+			s.sessCompsExceeded(shared.session, stats)
+		}
+	}
+
+	// Cached pages are outdated if components were changed
+	if shared.reload || len(shared.dirtyComps) > 0 {
+		if wi, ok := win.(*windowImpl); ok && wi.pageCache {
+			wi.InvalidatePageCache()
+		}
+	}
 
 	// Check if a new session was created during event dispatching
 	if shared.session.New() {
@@ -833,7 +2021,11 @@ func (s *serverImpl) handleEvent(sess Session, win Window, wr http.ResponseWrite
 	// If we reload, nothing else matters
 	if shared.reload {
 		hasAction = true
-		w.Writevs(eraReloadWin, strComma, shared.reloadWin)
+		if s.historyNav && shared.reloadWin != "" {
+			w.Writevs(eraSwitchWin, strComma, shared.reloadWin)
+		} else {
+			w.Writevs(eraReloadWin, strComma, shared.reloadWin)
+		}
 	} else {
 		if len(shared.dirtyComps) > 0 {
 			hasAction = true
@@ -853,12 +2045,210 @@ func (s *serverImpl) handleEvent(sess Session, win Window, wr http.ResponseWrite
 			// Also register focusable comp at window
 			win.SetFocusedCompID(shared.focusedComp.ID())
 		}
+		for _, token := range shared.downloads {
+			if hasAction {
+				w.Write(strSemicol)
+			} else {
+				hasAction = true
+			}
+			w.Writevs(eraDownload, strComma, token)
+		}
+		for _, anim := range shared.animations {
+			if hasAction {
+				w.Write(strSemicol)
+			} else {
+				hasAction = true
+			}
+			w.Writevs(eraAnimate, strComma, int(anim.comp.ID()), strComma, anim.name, strComma, int(anim.duration/time.Millisecond))
+		}
+		for _, t := range shared.toasts {
+			if hasAction {
+				w.Write(strSemicol)
+			} else {
+				hasAction = true
+			}
+			w.Writevs(eraToast, strComma, t.isError, strComma, url.PathEscape(t.message))
+		}
+		for _, a := range shared.announces {
+			if hasAction {
+				w.Write(strSemicol)
+			} else {
+				hasAction = true
+			}
+			w.Writevs(eraAnnounce, strComma, a.polite, strComma, url.PathEscape(a.text))
+		}
+		for _, m := range shared.masks {
+			if hasAction {
+				w.Write(strSemicol)
+			} else {
+				hasAction = true
+			}
+			w.Writevs(eraMask, strComma, int(m.comp.ID()), strComma, m.masked, strComma, url.PathEscape(m.label))
+		}
+		if shared.tabBadge >= 0 {
+			if hasAction {
+				w.Write(strSemicol)
+			} else {
+				hasAction = true
+			}
+			w.Writevs(eraTabBadge, strComma, shared.tabBadge)
+		}
+		if shared.attention {
+			if hasAction {
+				w.Write(strSemicol)
+			} else {
+				hasAction = true
+			}
+			w.Writev(eraAttention)
+		}
+		for _, o := range shared.openWins {
+			if hasAction {
+				w.Write(strSemicol)
+			} else {
+				hasAction = true
+			}
+			w.Writevs(eraOpenWin, strComma, url.PathEscape(o.name), strComma, url.PathEscape(o.features))
+		}
+		if len(shared.broadcast) > 0 {
+			if hasAction {
+				w.Write(strSemicol)
+			} else {
+				hasAction = true
+			}
+			w.Writev(eraBroadcast)
+			// IDs are only unique within a window, so windows are identified by their names too
+			for _, bw := range shared.broadcast {
+				w.Writevs(strComma, int(bw.id), strColon, url.PathEscape(bw.name))
+			}
+		}
+		if wi, ok := win.(*windowImpl); ok {
+			for id, t := range wi.tasks {
+				if t.sent {
+					continue
+				}
+				if hasAction {
+					w.Write(strSemicol)
+				} else {
+					hasAction = true
+				}
+				w.Writevs(eraSchedule, strComma, int(wi.id), strComma, id, strComma, t.delayMs())
+				t.sent = true
+			}
+		}
 	}
 	if !hasAction {
 		w.Writev(eraNoAction)
 	}
 }
 
+// checkTab checks the multi-tab policy for the event, and updates
+// the owner tab of the window. Returns false if the event must be refused.
+func (s *serverImpl) checkTab(w *windowImpl, e *eventImpl) bool {
+	tabID := e.TabID()
+	if s.multiTabPolicy == MultiTabAllow || tabID == "" {
+		return true
+	}
+
+	// Timers and scheduled tasks send state change events in the background,
+	// they do not take over windows.
+	background := e.etype == ETypeStateChange
+
+	now := time.Now()
+	if w.tabID == tabID || w.tabID == "" || now.Sub(w.tabSeen) >= tabOwnerTimeout {
+		if w.tabID == tabID || !background {
+			w.tabID, w.tabSeen = tabID, now
+		}
+		return true
+	}
+
+	// Another (active) tab owns the window
+	if s.multiTabPolicy == MultiTabRefuse {
+		if !background {
+			e.ShowToast("This window is open in another browser tab.", true)
+		}
+		return false
+	}
+	if !background {
+		w.tabID, w.tabSeen = tabID, now
+		e.ShowToast("This window is also open in another browser tab.", false)
+	}
+	return true
+}
+
+// permitted tells if events of the specified component are permitted for the session
+// according to the visibility predicates of the component and its ancestors,
+// and the enabled predicate of the component.
+func permitted(comp Comp, sess Session) bool {
+	if ef, ok := comp.(interface{ enabledFor(Session) bool }); ok && !ef.enabledFor(sess) {
+		return false
+	}
+	for c := comp; c != nil; c = c.Parent() {
+		if vt, ok := c.(interface{ visibleTo(Session) bool }); ok && !vt.visibleTo(sess) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkRateLimit checks the event against the event rate limit of its source
+// component or the server, and tells if the event is to be dispatched.
+func (s *serverImpl) checkRateLimit(comp Comp, e *eventImpl) bool {
+	if _, isTimer := comp.(Timer); isTimer {
+		return true
+	}
+
+	prefix := ""
+	n, per := comp.EventRateLimit()
+	if n > 0 {
+		// IDs are only unique within a window
+		if w := windowOf(comp); w != nil {
+			prefix = w.name + "/"
+		}
+		prefix += comp.ID().String() + "/"
+	} else {
+		n, per = s.rateLimitN, s.rateLimitPer
+	}
+	if n <= 0 {
+		return true
+	}
+
+	now := time.Now()
+	allowed := s.rateLimiter.allow(prefix+"ip:"+s.ClientIP(e.shared.req), n, per, now)
+	if sess := e.shared.session; sess.Private() {
+		// Always count it, even if the IP limit is already exceeded:
+		allowed = s.rateLimiter.allow(prefix+"sess:"+sess.ID(), n, per, now) && allowed
+	}
+	if allowed {
+		return true
+	}
+
+	if s.logger != nil {
+		s.logger.Println("\tEvent rate limit exceeded:", s.ClientIP(e.shared.req), comp.Path())
+	}
+	if s.rateLimitFunc != nil {
+		s.rateLimitFunc(e)
+	}
+	return false
+}
+
+// sessLabel returns a label identifying a session in profiles.
+// Session IDs are secrets, so a hash of the ID is used.
+func sessLabel(sess Session) string {
+	if !sess.Private() {
+		return "public"
+	}
+	h := fnv.New32a()
+	h.Write([]byte(sess.ID()))
+	return strconv.FormatUint(uint64(h.Sum32()), 36)
+}
+
+// writeWinExpired writes an event response telling the browser
+// that its window has expired and must be reloaded.
+func writeWinExpired(wr http.ResponseWriter) {
+	wr.Header().Set("Content-Type", "text/plain; charset=utf-8") // We send it as text
+	NewWriter(wr).Writev(eraWinExpired)
+}
+
 // parseIntParam parses an int param.
 // If error occurs, -1 will be returned.
 func parseIntParam(r *http.Request, paramName string) int {
@@ -20,10 +20,14 @@
 package gwu
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os/exec"
 	"runtime"
+	"strings"
 )
 
 // open opens the specified URL in the default browser of the user.
@@ -40,18 +44,57 @@ func open(url string) error {
 	default: // "linux", "freebsd", "openbsd", "netbsd"
 		cmd = "xdg-open"
 	}
+	if _, err := exec.LookPath(cmd); err != nil {
+		return fmt.Errorf("no browser found: %v", err)
+	}
 	args = append(args, url)
 	return exec.Command(cmd, args...).Start()
 }
 
 func (s *serverImpl) Start(openWins ...string) error {
-	http.HandleFunc(s.appPath, func(w http.ResponseWriter, r *http.Request) {
-		s.serveHTTP(w, r)
-	})
+	ln, err := s.listen(openWins)
+	if err != nil {
+		return err
+	}
+	return s.serve(ln)
+}
+
+func (s *serverImpl) StartAsync(openWins ...string) error {
+	ln, err := s.listen(openWins)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := s.serve(ln); err != nil {
+			log.Println("GUI server stopped:", err)
+			if s.logger != nil {
+				s.logger.Println("GUI server stopped:", err)
+			}
+		}
+	}()
+	return nil
+}
 
-	http.HandleFunc(s.appPath+pathStatic, func(w http.ResponseWriter, r *http.Request) {
-		s.serveStatic(w, r)
-	})
+// listen binds the listener, registers the server at its ServeMux
+// and opens the specified windows.
+// The server is only registered after the listener is bound successfully,
+// so listen can be called again if binding fails.
+func (s *serverImpl) listen(openWins []string) (net.Listener, error) {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.registered {
+		mux := s.mux
+		if mux == nil {
+			mux = http.DefaultServeMux
+		}
+		mux.Handle(s.appPath, s) // ServeHTTP also serves the static contents and the registered handlers
+		s.registered = true
+	}
+
+	s.started(ln.Addr())
 
 	appURL := s.AppURL()
 	log.Println("Starting GUI server on:", appURL)
@@ -59,25 +102,43 @@ func (s *serverImpl) Start(openWins ...string) error {
 		s.logger.Println("Starting GUI server on:", appURL)
 	}
 
-	for _, winName := range openWins {
-		if err := open(appURL + winName); err != nil {
-			if s.logger != nil {
-				s.logger.Printf("Opening window '%s' err: %v\n", appURL+winName, err)
-			}
-		}
+	if err := s.openWins(appURL, openWins); err != nil {
+		ln.Close()
+		return nil, err
 	}
 
 	go s.sessCleaner()
 
-	var err error
-	if s.secure {
-		err = http.ListenAndServeTLS(s.addr, s.certFile, s.keyFile, nil)
-	} else {
-		err = http.ListenAndServe(s.addr, nil)
+	return ln, nil
+}
+
+// openWins opens the specified windows with the opener of the server.
+// Errors of opening the windows are returned together.
+func (s *serverImpl) openWins(appURL string, winNames []string) error {
+	if s.opener == nil {
+		return nil
 	}
 
-	if err != nil {
-		return err
+	var errs []string
+	for _, winName := range winNames {
+		if err := s.opener(appURL + winName); err != nil {
+			errs = append(errs, fmt.Sprintf("opening window '%s' err: %v", appURL+winName, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
 	}
 	return nil
 }
+
+// serve serves incoming connections of the listener.
+func (s *serverImpl) serve(ln net.Listener) error {
+	var handler http.Handler // nil means http.DefaultServeMux
+	if s.mux != nil {
+		handler = s.mux
+	}
+	if s.secure {
+		return http.ServeTLS(ln, handler, s.certFile, s.keyFile)
+	}
+	return http.Serve(ln, handler)
+}
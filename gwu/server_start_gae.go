@@ -20,25 +20,32 @@
 package gwu
 
 import (
+	"errors"
 	"log"
 	"net/http"
 )
 
-func (s *serverImpl) Start(openWins ...string) error {
-	http.HandleFunc(s.appPath, func(w http.ResponseWriter, r *http.Request) {
-		s.serveHTTP(w, r)
-	})
+// open is not supported on Google App Engine.
+func open(url string) error {
+	return errors.New("opening a browser is not supported on GAE")
+}
 
-	http.HandleFunc(s.appPath+pathStatic, func(w http.ResponseWriter, r *http.Request) {
-		s.serveStatic(w, r)
-	})
+func (s *serverImpl) Start(openWins ...string) error {
+	// ServeHTTP also serves the static contents and the registered handlers
+	http.Handle(s.appPath, s)
 
 	log.Println("GAE - Starting GUI server on path:", s.appPath)
 	if s.logger != nil {
 		s.logger.Println("GAE - Starting GUI server on path:", s.appPath)
 	}
 
+	s.started(nil)
+
 	go s.sessCleaner()
 
 	return nil
 }
+
+func (s *serverImpl) StartAsync(openWins ...string) error {
+	return s.Start(openWins...)
+}
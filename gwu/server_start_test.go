@@ -0,0 +1,110 @@
+// +build !appengine
+
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Tests of the GUI server Start in standalone apps (non-GAE).
+
+package gwu
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStartAsync(t *testing.T) {
+	s := newServerImpl("startasync", "localhost:0", "", "")
+	s.mux = http.NewServeMux() // Don't register at http.DefaultServeMux, so the test can be repeated
+	var started net.Addr
+	s.OnStart(func(addr net.Addr) {
+		started = addr
+	})
+	var opened []string
+	s.SetOpener(func(url string) error {
+		opened = append(opened, url)
+		return nil
+	})
+	if s.Addr() != nil {
+		t.Errorf("Got addr %v before start, want: nil", s.Addr())
+	}
+	ln, err := s.listen([]string{""})
+	if err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	defer ln.Close()
+	go s.serve(ln)
+	if started == nil || s.Addr() != started {
+		t.Fatalf("Got started addr: %v, Addr(): %v", started, s.Addr())
+	}
+
+	if _, port, _ := net.SplitHostPort(s.Addr().String()); s.AppURL() != "http://localhost:"+port+"/startasync/" {
+		t.Errorf("Got app URL: %s, want chosen port: %s", s.AppURL(), port)
+	}
+
+	if len(opened) != 1 || opened[0] != s.AppURL() {
+		t.Errorf("Got opened: %v, want: [%s]", opened, s.AppURL())
+	}
+
+	resp, err := http.Get(s.AppURL())
+	if err != nil {
+		t.Fatalf("Server not accepting connections: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Got status: %d, want: %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestStartAsyncBusy(t *testing.T) {
+	busy, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer busy.Close()
+
+	s := newServerImpl("busy", busy.Addr().String(), "", "")
+	s.mux = http.NewServeMux()
+	for i := 0; i < 2; i++ {
+		if err := s.StartAsync(); err == nil {
+			t.Errorf("[%d] Expected error starting on a busy port", i)
+		}
+	}
+	if s.registered {
+		t.Errorf("Server registered despite failed listen")
+	}
+}
+
+func TestStartOpenerErr(t *testing.T) {
+	s := newServerImpl("openerr", "localhost:0", "", "")
+	s.mux = http.NewServeMux()
+	s.SetOpener(func(url string) error {
+		if strings.HasSuffix(url, "/bad") {
+			return errors.New("no browser found")
+		}
+		return nil
+	})
+
+	err := s.StartAsync("", "bad")
+	if err == nil || !strings.Contains(err.Error(), "no browser found") {
+		t.Fatalf("Got error: %v, want opener error", err)
+	}
+	if conn, err := net.Dial("tcp", s.Addr().String()); err == nil {
+		conn.Close()
+		t.Errorf("Listener not closed after opener error")
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultWin(t *testing.T) {
+	s := NewServer("app", "")
+	s.AddWin(NewWindow("main", "Main"))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	if rec := get("/app/"); rec.Code != http.StatusOK {
+		t.Errorf("Got status: %d, want window list: %d", rec.Code, http.StatusOK)
+	}
+
+	s.SetDefaultWin("main")
+	rec := get("/app/?lang=en")
+	if rec.Code != http.StatusFound {
+		t.Errorf("Got status: %d, want: %d", rec.Code, http.StatusFound)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/app/main?lang=en" {
+		t.Errorf("Got location: %s, want: %s", loc, "/app/main?lang=en")
+	}
+}
+
+func TestSessionExpiring(t *testing.T) {
+	s := newServerImpl("app", "", "", "")
+	sess := s.newSession(nil)
+	sess.SetTimeout(2 * time.Minute)
+	var calls []time.Duration
+	s.OnSessionExpiring(func(sess Session, remaining time.Duration) {
+		calls = append(calls, remaining)
+	})
+
+	start := sess.Accessed()
+	s.cleanSessions(start.Add(30 * time.Second))
+	if len(calls) != 0 {
+		t.Errorf("Called too early: %v", calls)
+	}
+	s.cleanSessions(start.Add(90 * time.Second))
+	s.cleanSessions(start.Add(100 * time.Second))
+	if len(calls) != 1 || calls[0] != 30*time.Second {
+		t.Errorf("Got calls: %v, want: [30s]", calls)
+	}
+	s.cleanSessions(start.Add(3 * time.Minute))
+	if s.sessions[sess.ID()] != nil {
+		t.Errorf("Expired session not removed")
+	}
+}
+
+func TestSessions(t *testing.T) {
+	s := newServerImpl("app", "", "", "")
+	sess1 := s.newSession(nil)
+	sess1.AddWin(NewWindow("main", "Main"))
+	sess1.AddWin(NewWindow("cart", "Cart"))
+	sess2 := s.newSession(nil)
+
+	infos := s.Sessions()
+	if len(infos) != 2 {
+		t.Fatalf("Got sessions: %v", infos)
+	}
+	if infos[0].ID != sess1.ID() {
+		infos[0], infos[1] = infos[1], infos[0] // Creation times may be equal
+	}
+	if names := infos[0].WinNames; len(names) != 2 || names[0] != "cart" || names[1] != "main" {
+		t.Errorf("Got window names: %v", names)
+	}
+
+	if !s.RemoveSession(sess1.ID()) || s.RemoveSession(sess1.ID()) {
+		t.Errorf("Unexpected results removing session")
+	}
+	if infos := s.Sessions(); len(infos) != 1 || infos[0].ID != sess2.ID() {
+		t.Errorf("Got sessions after removal: %v", infos)
+	}
+}
+
+func TestSessionsFromEvent(t *testing.T) {
+	s := newServerImpl("app", "", "", "")
+	sess := s.newSession(nil)
+	win := NewWindow("main", "Main")
+	b := NewButton("List")
+	var infos []SessionInfo
+	b.AddEHandlerFunc(func(e Event) {
+		infos = s.Sessions()
+	}, ETypeClick)
+	win.Add(b)
+	sess.AddWin(win)
+
+	done := make(chan struct{})
+	go func() {
+		body := fmt.Sprintf("%s=%s&%s=%d", paramCompID, b.ID(), paramEventType, ETypeClick)
+		r := httptest.NewRequest("POST", "/app/main/"+pathEvent, strings.NewReader(body))
+		r.Header.Set("Content-type", "application/x-www-form-urlencoded")
+		r.AddCookie(&http.Cookie{Name: s.SessIDCookieName(), Value: sess.ID()})
+		s.ServeHTTP(httptest.NewRecorder(), r)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Sessions() called from an event handler did not return")
+	}
+	if len(infos) != 1 || infos[0].ID != sess.ID() {
+		t.Errorf("Got sessions: %v", infos)
+	}
+}
+
+func TestMaxSessions(t *testing.T) {
+	s := newServerImpl("app", "", "", "")
+	s.AddSessCreatorName("login", "Login")
+	s.SetMaxSessions(2, SessOverflowReject)
+	s.SetMaxSessionsPerIP(1)
+
+	get := func(ip string) int {
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/app/login", nil)
+		r.RemoteAddr = ip + ":1234"
+		s.ServeHTTP(rec, r)
+		return rec.Code
+	}
+
+	for i, c := range []struct {
+		ip   string
+		code int
+	}{
+		{"10.0.0.1", http.StatusNotFound}, // Session created, but no login window
+		{"10.0.0.1", http.StatusServiceUnavailable},
+		{"10.0.0.2", http.StatusNotFound},
+		{"10.0.0.3", http.StatusServiceUnavailable},
+	} {
+		if code := get(c.ip); code != c.code {
+			t.Errorf("[%d] Got status: %d, want: %d", i, code, c.code)
+		}
+	}
+
+	s.SetMaxSessions(2, SessOverflowEvict)
+	if code := get("10.0.0.3"); code != http.StatusNotFound || len(s.sessions) != 2 {
+		t.Errorf("Got status: %d, sessions: %d; want eviction", code, len(s.sessions))
+	}
+}
+
+func TestMaxSessionsLockedSess(t *testing.T) {
+	s := newServerImpl("app", "", "", "")
+	s.AddSessCreatorName("login", "Login")
+	s.SetMaxSessions(1, SessOverflowEvict)
+	sess := s.newSession(nil)
+
+	// Event handlers hold the lock of their session while creating new sessions
+	sess.rwMutex().Lock()
+	defer sess.rwMutex().Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/app/login", nil))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Admitting a new session blocked on a locked session")
+	}
+}
+
+func TestHandleFunc(t *testing.T) {
+	s := newServerImpl("app", "", "", "")
+	s.AddWin(NewWindow("main", "Main"))
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}
+
+	if err := s.HandleFunc("api/health", h); err != nil {
+		t.Errorf("HandleFunc failed: %v", err)
+	}
+	for _, path := range []string{"api/health", "/api/health", "main", "main/api"} {
+		if err := s.HandleFunc(path, h); err == nil {
+			t.Errorf("Expected error registering path: %s", path)
+		}
+	}
+
+	for _, c := range []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/app/api/health", http.StatusOK, "OK"},
+		{"/app/main", http.StatusOK, ""},
+		{"/app/api", http.StatusNotFound, ""},
+	} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest("GET", c.path, nil))
+		if rec.Code != c.code || c.body != "" && rec.Body.String() != c.body {
+			t.Errorf("[%s] Got status: %d, body: %q; want: %d, %q", c.path, rec.Code, rec.Body.String(), c.code, c.body)
+		}
+	}
+}
+
+func TestAddSSE(t *testing.T) {
+	s := newServerImpl("sse", "", "", "")
+	s.SetHeaders(map[string][]string{"X-Test": {"1"}})
+	err := s.AddSSE("feed", func(ctx context.Context, send func(event, data string)) {
+		send("", "hello")
+		send("update", "line1\nline2")
+	})
+	if err != nil {
+		t.Fatalf("AddSSE failed: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("GET", "/sse/feed", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Got content type: %s, want: text/event-stream", ct)
+	}
+	if rec.Header().Get("X-Test") != "1" {
+		t.Errorf("Extra headers are missing")
+	}
+	if got, want := rec.Body.String(), "data: hello\n\nevent: update\ndata: line1\ndata: line2\n\n"; got != want {
+		t.Errorf("Got stream: %q, want: %q", got, want)
+	}
+}
+
+func TestRenderNoAccess(t *testing.T) {
+	s := newServerImpl("app", "", "", "")
+	win := NewWindow("main", "Main")
+	l := NewLabel("x")
+	win.Add(l)
+	s.AddWin(win)
+
+	render := func(noAccess bool) time.Time {
+		before := s.sessionImpl.Accessed()
+		time.Sleep(time.Millisecond)
+		body := "cid=" + l.ID().String()
+		if noAccess {
+			body += "&na=1"
+		}
+		r := httptest.NewRequest("POST", "/app/main/rc", strings.NewReader(body))
+		r.Header.Set("Content-type", "application/x-www-form-urlencoded")
+		s.ServeHTTP(httptest.NewRecorder(), r)
+		return before
+	}
+
+	if before := render(true); !s.sessionImpl.Accessed().Equal(before) {
+		t.Errorf("Re-render with no access registered access")
+	}
+	if before := render(false); s.sessionImpl.Accessed().Equal(before) {
+		t.Errorf("Re-render did not register access")
+	}
+}
@@ -86,11 +86,16 @@ func (c *sessMonitorImpl) Render(w Writer) {
 
 	w.Write(strEmptySpan) // Placeholder for session timeout value
 
+	if reportMode(w) {
+		w.Write(strSpanCl)
+		return
+	}
+
 	w.Write(strScriptOp)
-	c.renderSetupTimerJs(w, strJsCheckSessOp, int(c.id), strParenCl)
+	c.renderSetupTimerJs(w, strJsCheckSessOp, c.id, strParenCl)
 	// Call sess check right away:
 	w.Write(strJsCheckSessOp)
-	w.Writev(int(c.id))
+	writeID(w, c.id)
 	w.Write(strJsFuncCl)
 	w.Write(strScriptCl)
 
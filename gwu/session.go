@@ -48,9 +48,12 @@ type Session interface {
 
 	// RemoveWin removes a window from the session.
 	// Returns if the window was removed from the session.
+	// The removed window is disposed (see Window.Dispose()),
+	// unless this is disabled by Window.SetDisposeOnRemove(false).
 	RemoveWin(w Window) bool
 
-	// SortedWins returns a sorted slice of windows.
+	// SortedWins returns a sorted slice of the listed windows
+	// (unlisted windows are excluded, see Window.SetListed()).
 	// The slice is sorted by window text (title).
 	SortedWins() []Window
 
@@ -59,12 +62,35 @@ type Session interface {
 
 	// Attr returns the value of an attribute stored in the session.
 	// TODO use an interface type something like "serializable".
+	//
+	// Session attribute methods are safe for concurrent use, they can be called
+	// from other goroutines (e.g. background jobs or custom HTTP handlers) too.
 	Attr(name string) interface{}
 
+	// AttrOk returns the value of an attribute stored in the session,
+	// and tells if the attribute exists.
+	AttrOk(name string) (value interface{}, ok bool)
+
+	// AttrString returns the value of an attribute stored in the session as a string.
+	// An empty string is returned if the attribute does not exist or is not a string.
+	AttrString(name string) string
+
+	// AttrInt returns the value of an attribute stored in the session as an int.
+	// 0 is returned if the attribute does not exist or is not an int.
+	AttrInt(name string) int
+
 	// SetAttr sets the value of an attribute stored in the session.
 	// Pass the nil value to delete the attribute.
 	SetAttr(name string, value interface{})
 
+	// CompareAndSetAttr sets the value of an attribute to newValue
+	// only if its current value equals to oldValue, atomically.
+	// A nil oldValue means the attribute must not exist; a nil newValue
+	// deletes the attribute.
+	// Returns true if the value was set.
+	// oldValue and the current value of the attribute must be comparable (see the Go spec).
+	CompareAndSetAttr(name string, oldValue, newValue interface{}) bool
+
 	// Created returns the time when the session was created.
 	Created() time.Time
 
@@ -77,8 +103,40 @@ type Session interface {
 	// SetTimeout sets the session timeout.
 	SetTimeout(timeout time.Duration)
 
+	// Locale returns the locale of the session (a language tag like "en-US" or "de").
+	// The locale of private sessions is initialized from the Accept-Language
+	// header of the browser. See ParseNumber() and ParseDate().
+	Locale() string
+
+	// SetLocale sets the locale of the session (a language tag like "en-US" or "de").
+	SetLocale(locale string)
+
+	// Roles returns the roles of the session (e.g. the roles of the logged in user).
+	// The returned slice is a copy, modifying it does not affect the session.
+	Roles() []string
+
+	// SetRoles sets the roles of the session, replacing the previous ones.
+	// Pass nil to clear the roles (e.g. on logout).
+	// Roles are the standard place to store authorization state,
+	// see HasAnyRole() to gate components by roles.
+	// Roles of the public session are shared between all sessionless users!
+	SetRoles(roles []string)
+
+	// HasRole tells if the session has the specified role.
+	HasRole(role string) bool
+
+	// Stats returns statistics of the session: the number of windows,
+	// components and event handlers, which helps to catch UI memory leaks
+	// (e.g. component trees growing indefinitely).
+	// See Server.SetMaxSessionComps().
+	//
+	// Stats walks the component trees of all windows of the session,
+	// it must be called while the session is locked (e.g. from event handlers).
+	Stats() SessionStats
+
 	// access registers an access to the session.
-	// Implementation locks or the sessions RW mutex.
+	// Implementation does not lock the sessions RW mutex,
+	// so this can also be called while the session is locked.
 	access()
 
 	// ClearNew clears the new flag.
@@ -87,19 +145,55 @@ type Session interface {
 
 	// rwMutex returns the RW mutex of the session.
 	rwMutex() *sync.RWMutex
+
+	// remoteAddr returns the client IP address of the last request of the session.
+	remoteAddr() string
+
+	// setRemoteAddr sets the client IP address of the last request of the session.
+	setRemoteAddr(addr string)
+
+	// info returns information about the session.
+	// Implementation only locks the attributes mutex for reading (not the sessions RW mutex),
+	// so it can also be called while the session is locked.
+	info() SessionInfo
+}
+
+// SessionStats holds statistics of a session, see Session.Stats().
+type SessionStats struct {
+	Wins      int // Number of windows
+	Comps     int // Number of components (including windows)
+	Handlers  int // Number of event handlers
+	AttrBytes int // Total size of the HTML attributes of the components (names and values)
 }
 
+// SessionInfo holds information about a session, see Server.Sessions().
+type SessionInfo struct {
+	ID         string    // ID of the session
+	Created    time.Time // Creation time
+	Accessed   time.Time // Last accessed time
+	WinNames   []string  // Names of the windows of the session, sorted
+	RemoteAddr string    // Client IP address of the last request of the session
+	Roles      []string  // Roles of the session
+}
+
+// SessionStatsFunc is a function which receives a session and its statistics.
+type SessionStatsFunc func(sess Session, stats SessionStats)
+
 // Session implementation.
 type sessionImpl struct {
 	id       string                 // ID of the session
 	isNew    bool                   // Tells if the session is new
 	created  time.Time              // Creation time
-	accessed time.Time              // Last accessed time
-	windows  map[string]Window      // Windows of the session
+	accessed time.Time              // Last accessed time, protected by attrsMux
+	windows  map[string]Window      // Windows of the session, modifications also lock attrsMux
 	attrs    map[string]interface{} // Attributes stored in the session
 	timeout  time.Duration          // Session timeout
+	locale   string                 // Locale of the session, protected by attrsMux
+	addr     string                 // Client IP address of the last request, protected by attrsMux
+	roles    []string               // Roles of the session, protected by attrsMux
 
 	rwMutexF *sync.RWMutex // RW mutex to synchronize session (and related Window and component) access
+	attrsMux *sync.RWMutex // RW mutex to synchronize access to the session attributes
 }
 
 // newSessionImpl creates a new sessionImpl.
@@ -115,7 +209,7 @@ func newSessionImpl(private bool) sessionImpl {
 
 	// Initialzie private sessions as new, but not the public session
 	return sessionImpl{id: id, isNew: private, created: now, accessed: now, windows: make(map[string]Window),
-		attrs: make(map[string]interface{}), timeout: 30 * time.Minute, rwMutexF: &sync.RWMutex{}}
+		attrs: make(map[string]interface{}), timeout: 30 * time.Minute, rwMutexF: &sync.RWMutex{}, attrsMux: &sync.RWMutex{}}
 }
 
 // Valid characters (bytes) to be used in session IDs
@@ -165,27 +259,37 @@ func (s *sessionImpl) AddWin(w Window) error {
 		return errors.New("A window with the same name has already been added: " + w.Name())
 	}
 
+	s.attrsMux.Lock()
 	s.windows[w.Name()] = w
+	s.attrsMux.Unlock()
+	if s.Private() {
+		restorePanelLayouts(s, w)
+	}
 
 	return nil
 }
 
 func (s *sessionImpl) RemoveWin(w Window) bool {
 	win := s.windows[w.Name()]
-	if win != nil && win.ID() == w.ID() {
+	if win != nil && win.Equals(w) {
+		s.attrsMux.Lock()
 		delete(s.windows, w.Name())
+		s.attrsMux.Unlock()
+		if win.DisposeOnRemove() {
+			win.Dispose()
+		}
 		return true
 	}
 	return false
 }
 
 func (s *sessionImpl) SortedWins() []Window {
-	wins := make(WinSlice, len(s.windows))
+	wins := make(WinSlice, 0, len(s.windows))
 
-	i := 0
 	for _, win := range s.windows {
-		wins[i] = win
-		i++
+		if win.Listed() {
+			wins = append(wins, win)
+		}
 	}
 
 	sort.Sort(wins)
@@ -198,10 +302,37 @@ func (s *sessionImpl) WinByName(name string) Window {
 }
 
 func (s *sessionImpl) Attr(name string) interface{} {
+	s.attrsMux.RLock()
+	defer s.attrsMux.RUnlock()
 	return s.attrs[name]
 }
 
+func (s *sessionImpl) AttrOk(name string) (value interface{}, ok bool) {
+	s.attrsMux.RLock()
+	defer s.attrsMux.RUnlock()
+	value, ok = s.attrs[name]
+	return
+}
+
+func (s *sessionImpl) AttrString(name string) string {
+	v, _ := s.Attr(name).(string)
+	return v
+}
+
+func (s *sessionImpl) AttrInt(name string) int {
+	v, _ := s.Attr(name).(int)
+	return v
+}
+
 func (s *sessionImpl) SetAttr(name string, value interface{}) {
+	s.attrsMux.Lock()
+	s.setAttr(name, value)
+	s.attrsMux.Unlock()
+}
+
+// setAttr sets the value of an attribute.
+// attrsMux must be locked when this is called.
+func (s *sessionImpl) setAttr(name string, value interface{}) {
 	if value == nil {
 		delete(s.attrs, name)
 	} else {
@@ -209,13 +340,24 @@ func (s *sessionImpl) SetAttr(name string, value interface{}) {
 	}
 }
 
+func (s *sessionImpl) CompareAndSetAttr(name string, oldValue, newValue interface{}) bool {
+	s.attrsMux.Lock()
+	defer s.attrsMux.Unlock()
+
+	if s.attrs[name] != oldValue {
+		return false
+	}
+	s.setAttr(name, newValue)
+	return true
+}
+
 func (s *sessionImpl) Created() time.Time {
 	return s.created
 }
 
 func (s *sessionImpl) Accessed() time.Time {
-	s.rwMutexF.RLock()
-	defer s.rwMutexF.RUnlock()
+	s.attrsMux.RLock()
+	defer s.attrsMux.RUnlock()
 	return s.accessed
 }
 
@@ -227,10 +369,112 @@ func (s *sessionImpl) SetTimeout(timeout time.Duration) {
 	s.timeout = timeout
 }
 
+func (s *sessionImpl) Locale() string {
+	s.attrsMux.RLock()
+	defer s.attrsMux.RUnlock()
+	return s.locale
+}
+
+func (s *sessionImpl) SetLocale(locale string) {
+	s.attrsMux.Lock()
+	s.locale = locale
+	s.attrsMux.Unlock()
+}
+
+func (s *sessionImpl) Roles() []string {
+	s.attrsMux.RLock()
+	defer s.attrsMux.RUnlock()
+	return append([]string(nil), s.roles...)
+}
+
+func (s *sessionImpl) SetRoles(roles []string) {
+	roles = append([]string(nil), roles...)
+	s.attrsMux.Lock()
+	s.roles = roles
+	s.attrsMux.Unlock()
+}
+
+func (s *sessionImpl) HasRole(role string) bool {
+	s.attrsMux.RLock()
+	defer s.attrsMux.RUnlock()
+	for _, r := range s.roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAnyRole returns a predicate which tells if a session has any of the specified roles.
+// The predicate can be used to gate components, e.g. with Comp.SetVisibleIf()
+// and HasEnabled.SetEnabledIf(), and it returns false for a nil session.
+//
+// Example: only show a button to administrators and auditors:
+//
+//	b.SetVisibleIf(gwu.HasAnyRole("admin", "auditor"))
+func HasAnyRole(roles ...string) func(sess Session) bool {
+	return func(sess Session) bool {
+		if sess == nil {
+			return false
+		}
+		for _, role := range roles {
+			if sess.HasRole(role) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (s *sessionImpl) remoteAddr() string {
+	s.attrsMux.RLock()
+	defer s.attrsMux.RUnlock()
+	return s.addr
+}
+
+func (s *sessionImpl) setRemoteAddr(addr string) {
+	s.attrsMux.Lock()
+	s.addr = addr
+	s.attrsMux.Unlock()
+}
+
+// info returns the info of the session.
+// Only attrsMux is locked, so this can be called while the session is locked
+// (e.g. from an event handler).
+func (s *sessionImpl) info() SessionInfo {
+	s.attrsMux.RLock()
+	info := SessionInfo{ID: s.id, Created: s.created, Accessed: s.accessed, WinNames: make([]string, 0, len(s.windows))}
+	for name := range s.windows {
+		info.WinNames = append(info.WinNames, name)
+	}
+	info.RemoteAddr = s.addr
+	info.Roles = append([]string(nil), s.roles...)
+	s.attrsMux.RUnlock()
+
+	sort.Strings(info.WinNames)
+	return info
+}
+
+func (s *sessionImpl) Stats() SessionStats {
+	stats := SessionStats{Wins: len(s.windows)}
+	for _, win := range s.windows {
+		Walk(win, func(c Comp) bool {
+			stats.Comps++
+			if cs, ok := c.(interface{ stats() (int, int) }); ok {
+				handlers, attrBytes := cs.stats()
+				stats.Handlers += handlers
+				stats.AttrBytes += attrBytes
+			}
+			return true
+		})
+	}
+	return stats
+}
+
 func (s *sessionImpl) access() {
-	s.rwMutexF.Lock()
+	s.attrsMux.Lock()
 	s.accessed = time.Now()
-	s.rwMutexF.Unlock()
+	s.attrsMux.Unlock()
 }
 
 func (s *sessionImpl) clearNew() {
@@ -0,0 +1,34 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+//go:build go1.18
+// +build go1.18
+
+// Generic helpers (requires Go 1.18 or newer).
+
+package gwu
+
+// SrcAs returns the source of the event as a T, and tells if the source is a T.
+// The zero value of T (nil) and false are returned if the source is not a T.
+//
+// Example:
+//
+//	if tb, ok := gwu.SrcAs[gwu.TextBox](e); ok {
+//		log.Println("New text:", tb.Text())
+//	}
+func SrcAs[T Comp](e Event) (T, bool) {
+	c, ok := e.Src().(T)
+	return c, ok
+}
@@ -52,11 +52,18 @@ type CheckBox interface {
 // SwitchButton interface defines a button which can be switched
 // ON and OFF.
 //
-// Suggested event type to handle changes: ETypeClick
+// Suggested event type to handle changes: ETypeChange
+//
+// ETypeChange events are generated when the state of the switch button
+// actually changes. The event will have a parent event whose type is ETypeClick
+// and which will contain the mouse coordinates.
+//
+// The switch button can be operated with the keyboard too: pressing SPACE
+// (or ENTER) on a focused switch button toggles its state.
 //
 // Default style classes: "gwu-SwitchButton", "gwu-SwitchButton-On-Active"
 // "gwu-SwitchButton-On-Inactive", "gwu-SwitchButton-Off-Active",
-// "gwu-SwitchButton-Off-Inactive"
+// "gwu-SwitchButton-Off-Inactive", "gwu-SwitchButton-Toggle"
 type SwitchButton interface {
 	// SwitchButton is a component.
 	Comp
@@ -78,6 +85,14 @@ type SwitchButton interface {
 
 	// SetOnOff sets the texts of the ON and OFF sides.
 	SetOnOff(on, off string)
+
+	// ToggleOnly tells if the switch button is in toggle only mode.
+	ToggleOnly() bool
+
+	// SetToggleOnly sets the toggle only mode.
+	// In toggle only mode only the side of the current state is displayed
+	// (as a single pill), and clicking on it toggles the state.
+	SetToggleOnly(toggleOnly bool)
 }
 
 // RadioGroup interface defines the group for grouping radio buttons.
@@ -143,6 +158,8 @@ type switchButtonImpl struct {
 
 	onButton, offButton *buttonImpl // ON and OFF button implementations
 	state               bool        // State of the switch
+	toggleOnly          bool        // Tells if the switch is in toggle only mode
+	changed             bool        // Tells if the state was changed by the last event
 }
 
 // NewRadioGroup creates a new RadioGroup.
@@ -171,20 +188,34 @@ func NewSwitchButton() SwitchButton {
 	onButton := newButtonImpl(nil, "ON")
 	offButton := newButtonImpl(nil, "OFF")
 
-	// We only want to switch the state if the opposite button is pressed
-	// (e.g. OFF is pressed when switch is ON and vice versa;
-	// if ON is pressed when switch is ON, do not switch to OFF):
-	valueProviderJs := []byte("sbtnVal(event,'" + onButton.ID().String() + "','" + offButton.ID().String() + "')")
-
-	c := &switchButtonImpl{newCompImpl(valueProviderJs), &onButton, &offButton, true} // Note the "true" state, so the following SetState(false) will be executed (different states)!
+	c := &switchButtonImpl{compImpl: newCompImpl(nil), onButton: &onButton, offButton: &offButton, state: true} // Note the "true" state, so the following SetState(false) will be executed (different states)!
+	c.valueProviderJs = c.sbtnValJs()
 	c.AddSyncOnETypes(ETypeClick)
 	c.SetAttr("cellspacing", "0")
 	c.SetAttr("cellpadding", "0")
 	c.Style().AddClass("gwu-SwitchButton")
 	c.SetState(false)
+
+	c.AddEHandler(internalHandler{c.base(), func(e Event) {
+		if c.changed {
+			c.changed = false
+			if c.handlers[ETypeChange] != nil {
+				c.dispatchEvent(e.forkEvent(ETypeChange, c))
+			}
+		}
+	}}, ETypeClick)
 	return c
 }
 
+// allocExtraIDs allocates new IDs for the ON and OFF buttons
+// when IDs are allocated to the switch button (see allocIDs()).
+func (c *switchButtonImpl) allocExtraIDs(next func() ID) {
+	for _, b := range []*buttonImpl{c.onButton, c.offButton} {
+		b.id = next()
+		b.attrs["id"] = b.id.String()
+	}
+}
+
 // NewRadioButton creates a new radio button.
 // The initial state is false.
 func NewRadioButton(text string, group RadioGroup) RadioButton {
@@ -195,12 +226,18 @@ func NewRadioButton(text string, group RadioGroup) RadioButton {
 
 // newStateButtonImpl creates a new stateButtonImpl.
 func newStateButtonImpl(text string, inputType []byte, group RadioGroup, disabledClass string) *stateButtonImpl {
-	c := &stateButtonImpl{newButtonImpl(strThisChecked, text), false, inputType, group, nextCompID(), disabledClass}
+	c := &stateButtonImpl{newButtonImpl(strThisChecked, text), false, inputType, group, nextFreeID(), disabledClass}
 	// Use ETypeClick because IE fires onchange only when focus is lost...
 	c.AddSyncOnETypes(ETypeClick)
 	return c
 }
 
+// allocExtraIDs allocates a new ID for the rendered input tag
+// when IDs are allocated to the button (see allocIDs()).
+func (c *stateButtonImpl) allocExtraIDs(next func() ID) {
+	c.inputID = next()
+}
+
 func (r *radioGroupImpl) Name() string {
 	return r.name
 }
@@ -307,7 +344,7 @@ func (c *stateButtonImpl) Render(w Writer) {
 	w.Write(strInput)
 	w.Write(c.inputType)
 	w.Write(strID)
-	w.Writev(int(c.inputID))
+	w.Writev(c.inputID)
 	w.Write(strQuote)
 	if c.group != nil {
 		w.Write(strName)
@@ -321,7 +358,7 @@ func (c *stateButtonImpl) Render(w Writer) {
 	c.renderEHandlers(w)
 
 	w.Write(strLabelFor)
-	w.Writev(int(c.inputID))
+	w.Writev(c.inputID)
 	w.Write(strQuote)
 	// TODO readding click handler here causes double event sending...
 	// But we might add mouseover and other handlers still...
@@ -341,6 +378,11 @@ func (c *switchButtonImpl) SetEnabled(enabled bool) {
 	c.offButton.SetEnabled(enabled)
 }
 
+func (c *switchButtonImpl) SetEnabledIf(f func(sess Session) bool) {
+	c.onButton.SetEnabledIf(f)
+	c.offButton.SetEnabledIf(f)
+}
+
 func (c *switchButtonImpl) State() bool {
 	return c.state
 }
@@ -352,7 +394,28 @@ func (c *switchButtonImpl) SetState(state bool) {
 	}
 
 	c.state = state
+	c.updateStyles()
+}
+
+// updateStyles updates the styles of the ON and OFF buttons
+// according to the state and the toggle only mode.
+func (c *switchButtonImpl) updateStyles() {
+	if c.toggleOnly {
+		// Only the side of the current state is displayed
+		c.onButton.Style().SetClass("gwu-SwitchButton-On-Active")
+		c.offButton.Style().SetClass("gwu-SwitchButton-Off-Active")
+		if c.state {
+			c.onButton.Style().SetDisplay("")
+			c.offButton.Style().SetDisplay(DisplayNone)
+		} else {
+			c.onButton.Style().SetDisplay(DisplayNone)
+			c.offButton.Style().SetDisplay("")
+		}
+		return
+	}
 
+	c.onButton.Style().SetDisplay("")
+	c.offButton.Style().SetDisplay("")
 	if c.state {
 		c.onButton.Style().SetClass("gwu-SwitchButton-On-Active")
 		c.offButton.Style().SetClass("gwu-SwitchButton-Off-Inactive")
@@ -374,6 +437,45 @@ func (c *switchButtonImpl) SetOnOff(on, off string) {
 	c.offButton.SetText(off)
 }
 
+func (c *switchButtonImpl) ToggleOnly() bool {
+	return c.toggleOnly
+}
+
+func (c *switchButtonImpl) SetToggleOnly(toggleOnly bool) {
+	if c.toggleOnly == toggleOnly {
+		return
+	}
+
+	c.toggleOnly = toggleOnly
+	if toggleOnly {
+		c.Style().AddClass("gwu-SwitchButton-Toggle")
+	} else {
+		c.Style().RemoveClass("gwu-SwitchButton-Toggle")
+	}
+	c.valueProviderJs = c.sbtnValJs()
+	c.updateStyles()
+}
+
+var (
+	strSbtnTglJs = []byte("sbtnTgl(this)")       // "sbtnTgl(this)"
+	strSbtnValJs = []byte("sbtnVal(event,this)") // "sbtnVal(event,this)"
+)
+
+// sbtnValJs returns the JavaScript code which provides (and updates)
+// the value of the switch button at the client side.
+// The on and off buttons are looked up inside the wrapper tag (this).
+func (c *switchButtonImpl) sbtnValJs() []byte {
+	if c.toggleOnly {
+		// Any click toggles the state:
+		return strSbtnTglJs
+	}
+
+	// We only want to switch the state if the opposite button is pressed
+	// (e.g. OFF is pressed when switch is ON and vice versa;
+	// if ON is pressed when switch is ON, do not switch to OFF):
+	return strSbtnValJs
+}
+
 func (c *switchButtonImpl) preprocessEvent(event Event, r *http.Request) {
 	value := r.FormValue(paramCompValue)
 	if len(value) == 0 {
@@ -381,6 +483,7 @@ func (c *switchButtonImpl) preprocessEvent(event Event, r *http.Request) {
 	}
 
 	if v, err := strconv.ParseBool(value); err == nil {
+		c.changed = c.state != v
 		// Call SetState instead of assigning to the state property
 		// because SetState properly changes style classes.
 		c.SetState(v)
@@ -406,11 +509,18 @@ func (c *switchButtonImpl) Render(w Writer) {
 	c.onButton.renderEnabled(w)
 	w.Write(strClTr)
 
-	w.Write(strTD50)
-	c.onButton.Render(w)
+	if c.toggleOnly {
+		// Both buttons are rendered in the same cell, but only one of them is displayed.
+		w.Write(strTD)
+		c.onButton.Render(w)
+		c.offButton.Render(w)
+	} else {
+		w.Write(strTD50)
+		c.onButton.Render(w)
 
-	w.Write(strTD50)
-	c.offButton.Render(w)
+		w.Write(strTD50)
+		c.offButton.Render(w)
+	}
 
 	w.Write(strTableCl)
 }
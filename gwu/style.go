@@ -48,6 +48,24 @@ const (
 	StPaddingBottom = "padding-bottom" // Bottom padding
 	StWhiteSpace    = "white-space"    // White-space
 	StWidth         = "width"          // Width
+
+	StPosition       = "position"              // Position
+	StZIndex         = "z-index"               // Z-index (stack order)
+	StTop            = "top"                   // Top (position)
+	StLeft           = "left"                  // Left (position)
+	StRight          = "right"                 // Right (position)
+	StBottom         = "bottom"                // Bottom (position)
+	StBoxShadow      = "box-shadow"            // Box shadow
+	StBorderRadius   = "border-radius"         // Border radius
+	StOpacity        = "opacity"               // Opacity
+	StOverflow       = "overflow"              // Overflow
+	StTransition     = "transition"            // Transition
+	StFlexDirection  = "flex-direction"        // Flex direction
+	StFlexWrap       = "flex-wrap"             // Flex wrap
+	StJustifyContent = "justify-content"       // Justify content (flex and grid)
+	StAlignItems     = "align-items"           // Align items (flex and grid)
+	StGridTemplCols  = "grid-template-columns" // Grid template columns
+	StGap            = "gap"                   // Gap (flex and grid)
 )
 
 // The 17 standard color constants.
@@ -119,6 +137,64 @@ const (
 	DisplayInherit = "inherit" // The display property value will be inherited from the parent element.
 )
 
+// Additional display mode constants.
+const (
+	DisplayFlex        = "flex"         // The element is displayed as a block-level flex container.
+	DisplayGrid        = "grid"         // The element is displayed as a block-level grid container.
+	DisplayInlineBlock = "inline-block" // The element is displayed as an inline-level block container.
+)
+
+// Position constants.
+const (
+	PositionStatic   = "static"   // Elements render in order, as they appear in the document flow. This is the default.
+	PositionRelative = "relative" // The element is positioned relative to its normal position.
+	PositionAbsolute = "absolute" // The element is positioned relative to its first positioned (not static) ancestor element.
+	PositionFixed    = "fixed"    // The element is positioned relative to the browser window.
+	PositionSticky   = "sticky"   // The element is positioned based on the scroll position.
+)
+
+// Overflow constants.
+const (
+	OverflowVisible = "visible" // The overflow is not clipped, it renders outside the element's box. This is the default.
+	OverflowHidden  = "hidden"  // The overflow is clipped, and the rest of the content will be invisible.
+	OverflowScroll  = "scroll"  // The overflow is clipped, but a scroll-bar is added to see the rest of the content.
+	OverflowAuto    = "auto"    // If overflow is clipped, a scroll-bar is added to see the rest of the content.
+)
+
+// Flex direction constants.
+const (
+	FlexDirRow       = "row"            // Items are placed in a row. This is the default.
+	FlexDirRowRev    = "row-reverse"    // Items are placed in a row, in reverse order.
+	FlexDirColumn    = "column"         // Items are placed in a column.
+	FlexDirColumnRev = "column-reverse" // Items are placed in a column, in reverse order.
+)
+
+// Flex wrap constants.
+const (
+	FlexWrapNowrap  = "nowrap"       // Items are placed in a single line. This is the default.
+	FlexWrapWrap    = "wrap"         // Items wrap onto multiple lines.
+	FlexWrapWrapRev = "wrap-reverse" // Items wrap onto multiple lines, in reverse order.
+)
+
+// Justify content constants.
+const (
+	JustifyStart        = "flex-start"    // Items are packed toward the start. This is the default.
+	JustifyEnd          = "flex-end"      // Items are packed toward the end.
+	JustifyCenter       = "center"        // Items are centered.
+	JustifySpaceBetween = "space-between" // Items are evenly distributed, the first item is at the start, the last is at the end.
+	JustifySpaceAround  = "space-around"  // Items are evenly distributed with equal space around them.
+	JustifySpaceEvenly  = "space-evenly"  // Items are distributed so that the spacing between any two items is equal.
+)
+
+// Align items constants.
+const (
+	AlignItemsStretch  = "stretch"    // Items are stretched to fill the container. This is the default.
+	AlignItemsStart    = "flex-start" // Items are placed at the start of the cross axis.
+	AlignItemsEnd      = "flex-end"   // Items are placed at the end of the cross axis.
+	AlignItemsCenter   = "center"     // Items are centered in the cross axis.
+	AlignItemsBaseline = "baseline"   // Items are aligned such as their baselines align.
+)
+
 // White space constants.
 const (
 	WhiteSpaceNormal  = "normal"   // Sequences of white spaces are collapsed into a single whitespace. Text will wrap when necessary. This is the default.
@@ -147,6 +223,9 @@ type Style interface {
 	// If the specified class is not found, this is a no-op.
 	RemoveClass(class string) Style
 
+	// HasClass tells if the specified style class name is added.
+	HasClass(class string) bool
+
 	// Get returns the explicitly set value of the specified style attribute.
 	// Explicitly set style attributes will be concatenated and rendered
 	// as the "style" HTML attribute of the component.
@@ -418,6 +497,88 @@ type Style interface {
 	// SetWhiteSpace sets the white space attribute value.
 	SetWhiteSpace(value string) Style
 
+	// SetFlex sets flexbox layout (display:flex) with the specified
+	// direction, wrap, justify content and align items values.
+	// Empty string parameters delete the corresponding style attributes.
+	// See the FlexDirXXX, FlexWrapXXX, JustifyXXX and AlignItemsXXX constants.
+	SetFlex(direction, wrap, justify, align string) Style
+
+	// SetGrid sets grid layout (display:grid) with the specified
+	// template columns (e.g. "1fr 2fr" or "repeat(3, 100px)") and gap.
+	// Empty string parameters delete the corresponding style attributes.
+	SetGrid(templateCols, gap string) Style
+
+	// Position returns the position.
+	Position() string
+
+	// SetPosition sets the position.
+	// See the PositionXXX constants.
+	SetPosition(value string) Style
+
+	// ZIndex returns the z-index (stack order).
+	ZIndex() string
+
+	// SetZIndex sets the z-index (stack order).
+	SetZIndex(z int) Style
+
+	// SetTopLeft sets the top and left position.
+	SetTopLeft(top, left string) Style
+
+	// SetTopLeftPx sets the top and left position, in pixels.
+	SetTopLeftPx(top, left int) Style
+
+	// BoxShadow returns the box shadow.
+	BoxShadow() string
+
+	// SetBoxShadow sets the box shadow, e.g. "2px 2px 5px #888".
+	SetBoxShadow(value string) Style
+
+	// BorderRadius returns the border radius.
+	BorderRadius() string
+
+	// SetBorderRadius sets the border radius.
+	SetBorderRadius(value string) Style
+
+	// SetBorderRadiusPx sets the border radius, in pixels.
+	SetBorderRadiusPx(radius int) Style
+
+	// Opacity returns the opacity.
+	Opacity() string
+
+	// SetOpacity sets the opacity, 0.0 (fully transparent) .. 1.0 (fully opaque).
+	SetOpacity(opacity float64) Style
+
+	// Overflow returns the overflow.
+	Overflow() string
+
+	// SetOverflow sets the overflow.
+	// See the OverflowXXX constants.
+	SetOverflow(value string) Style
+
+	// Transition returns the transition.
+	Transition() string
+
+	// SetTransition sets the transition, e.g. "background 0.5s".
+	SetTransition(value string) Style
+
+	// Hover returns the Style builder of the :hover pseudo-class
+	// (style applied when the mouse is over the component), e.g.
+	//     b.Style().Hover().SetBackground(gwu.ClrYellow)
+	// Pseudo-class styles are only supported for the styles of components
+	// (not for cell formatters), and they take precedence over the
+	// normal style attributes.
+	Hover() Style
+
+	// Active returns the Style builder of the :active pseudo-class
+	// (style applied while the component is being activated, e.g. clicked).
+	// See Hover() for details.
+	Active() Style
+
+	// Focus returns the Style builder of the :focus pseudo-class
+	// (style applied while the component has the focus).
+	// See Hover() for details.
+	Focus() Style
+
 	// render renders all style information (style class names
 	// and style attributes).
 	render(w Writer)
@@ -432,8 +593,19 @@ type Style interface {
 type styleImpl struct {
 	classes []string          // Style classes.
 	attrs   map[string]string // Explicitly set style attributes. Lazily initialized.
+	pseudos [3]*styleImpl     // Styles of pseudo-classes, indexed by pseudoXXX constants. Lazily initialized.
 }
 
+// Supported pseudo-classes, used as indices of styleImpl.pseudos
+const (
+	pseudoHover = iota
+	pseudoActive
+	pseudoFocus
+)
+
+// Names of the supported pseudo-classes.
+var pseudoNames = [...]string{pseudoHover: "hover", pseudoActive: "active", pseudoFocus: "focus"}
+
 // newStyleImpl creates a new styleImpl.
 func newStyleImpl() *styleImpl {
 	return &styleImpl{}
@@ -452,6 +624,15 @@ func (s *styleImpl) SetClass(class string) Style {
 	return s
 }
 
+func (s *styleImpl) HasClass(class string) bool {
+	for _, cl := range s.classes {
+		if cl == class {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *styleImpl) RemoveClass(class string) Style {
 	for i, cl := range s.classes {
 		if cl == class {
@@ -783,6 +964,151 @@ func (s *styleImpl) SetWhiteSpace(value string) Style {
 	return s.Set(StWhiteSpace, value)
 }
 
+func (s *styleImpl) SetFlex(direction, wrap, justify, align string) Style {
+	s.Set(StDisplay, DisplayFlex)
+	s.Set(StFlexDirection, direction)
+	s.Set(StFlexWrap, wrap)
+	s.Set(StJustifyContent, justify)
+	return s.Set(StAlignItems, align)
+}
+
+func (s *styleImpl) SetGrid(templateCols, gap string) Style {
+	s.Set(StDisplay, DisplayGrid)
+	s.Set(StGridTemplCols, templateCols)
+	return s.Set(StGap, gap)
+}
+
+func (s *styleImpl) Position() string {
+	return s.Get(StPosition)
+}
+
+func (s *styleImpl) SetPosition(value string) Style {
+	return s.Set(StPosition, value)
+}
+
+func (s *styleImpl) ZIndex() string {
+	return s.Get(StZIndex)
+}
+
+func (s *styleImpl) SetZIndex(z int) Style {
+	return s.Set(StZIndex, strconv.Itoa(z))
+}
+
+func (s *styleImpl) SetTopLeft(top, left string) Style {
+	s.Set(StTop, top)
+	return s.Set(StLeft, left)
+}
+
+func (s *styleImpl) SetTopLeftPx(top, left int) Style {
+	return s.SetTopLeft(strconv.Itoa(top)+"px", strconv.Itoa(left)+"px")
+}
+
+func (s *styleImpl) BoxShadow() string {
+	return s.Get(StBoxShadow)
+}
+
+func (s *styleImpl) SetBoxShadow(value string) Style {
+	return s.Set(StBoxShadow, value)
+}
+
+func (s *styleImpl) BorderRadius() string {
+	return s.Get(StBorderRadius)
+}
+
+func (s *styleImpl) SetBorderRadius(value string) Style {
+	return s.Set(StBorderRadius, value)
+}
+
+func (s *styleImpl) SetBorderRadiusPx(radius int) Style {
+	return s.SetBorderRadius(strconv.Itoa(radius) + "px")
+}
+
+func (s *styleImpl) Opacity() string {
+	return s.Get(StOpacity)
+}
+
+func (s *styleImpl) SetOpacity(opacity float64) Style {
+	return s.Set(StOpacity, strconv.FormatFloat(opacity, 'f', -1, 64))
+}
+
+func (s *styleImpl) Overflow() string {
+	return s.Get(StOverflow)
+}
+
+func (s *styleImpl) SetOverflow(value string) Style {
+	return s.Set(StOverflow, value)
+}
+
+func (s *styleImpl) Transition() string {
+	return s.Get(StTransition)
+}
+
+func (s *styleImpl) SetTransition(value string) Style {
+	return s.Set(StTransition, value)
+}
+
+func (s *styleImpl) Hover() Style {
+	return s.pseudo(pseudoHover)
+}
+
+func (s *styleImpl) Active() Style {
+	return s.pseudo(pseudoActive)
+}
+
+func (s *styleImpl) Focus() Style {
+	return s.pseudo(pseudoFocus)
+}
+
+// pseudo returns the style of the specified pseudo-class, creating it if needed.
+func (s *styleImpl) pseudo(idx int) *styleImpl {
+	if s.pseudos[idx] == nil {
+		s.pseudos[idx] = newStyleImpl()
+	}
+	return s.pseudos[idx]
+}
+
+// pseudoCSS returns the style attributes of the pseudo-classes
+// in the form of "name{attr:value !important;...}name2{...}".
+// An empty string is returned if no pseudo-class style attributes are set.
+func (s *styleImpl) pseudoCSS() string {
+	var buf []byte
+	for i, ps := range s.pseudos {
+		if ps == nil || len(ps.attrs) == 0 {
+			continue
+		}
+		buf = append(buf, pseudoNames[i]...)
+		buf = append(buf, '{')
+		for _, name := range sortedKeys(ps.attrs) {
+			buf = append(buf, name...)
+			buf = append(buf, ':')
+			buf = append(buf, ps.attrs[name]...)
+			buf = append(buf, " !important;"...)
+		}
+		buf = append(buf, '}')
+	}
+	return string(buf)
+}
+
+// clone returns a deep copy of the style.
+// Returns nil if s is nil.
+func (s *styleImpl) clone() *styleImpl {
+	if s == nil {
+		return nil
+	}
+
+	s2 := &styleImpl{classes: append([]string(nil), s.classes...)}
+	if s.attrs != nil {
+		s2.attrs = make(map[string]string, len(s.attrs))
+		for name, value := range s.attrs {
+			s2.attrs[name] = value
+		}
+	}
+	for i, ps := range s.pseudos {
+		s2.pseudos[i] = ps.clone()
+	}
+	return s2
+}
+
 func (s *styleImpl) render(w Writer) {
 	s.renderClasses(w)
 
@@ -807,10 +1133,10 @@ func (s *styleImpl) renderClasses(w Writer) {
 }
 
 func (s *styleImpl) renderAttrs(w Writer) {
-	for name, value := range s.attrs {
+	rangeAttrs(w, s.attrs, func(name, value string) {
 		w.Writes(name)
 		w.Write(strColon)
 		w.Writes(value)
 		w.Write(strSemicol)
-	}
+	})
 }
@@ -50,10 +50,24 @@ type Table interface {
 	// (-1, -1) is returned if the component is not added to the table.
 	CompIdx(c Comp) (row, col int)
 
+	// Cells returns the non-empty cells of the table (the cells having a component),
+	// row by row. See Walk() to traverse component trees.
+	Cells() []Cell
+
 	// RowFmt returns the row formatter of the specified table row.
 	// If the table does not have a row specified by row, nil is returned.
 	RowFmt(row int) CellFmt
 
+	// ColFmt returns the column formatter of the specified table column.
+	// Column formatters are rendered as col tags (in a colgroup), so
+	// the width and background of a column can be set once per column,
+	// for example:
+	//     t.ColFmt(0).Style().SetWidthPx(100)
+	// Note that browsers only support a limited set of style attributes
+	// for columns (e.g. width, background, border, visibility).
+	// If no row of the table has a column specified by col, nil is returned.
+	ColFmt(col int) CellFmt
+
 	// CellFmt returns the cell formatter of the specified table cell.
 	// If the table does not have a cell specified by row and col,
 	// nil is returned.
@@ -91,6 +105,44 @@ type Table interface {
 	// TrimRow trims the specified row: removes trailing cells that has nil value
 	// by making the row shorter.
 	TrimRow(row int)
+
+	// HeaderRows returns the number of header rows.
+	HeaderRows() int
+
+	// SetHeaderRows sets the number of header rows.
+	// The first rows rows of the table are rendered in the header section
+	// of the table (thead), using header cells (th).
+	SetHeaderRows(rows int)
+
+	// AddHeaderRow adds a new header row after the existing header rows
+	// holding the specified components in its columns.
+	// Existing non-header rows are shifted down by one (including their
+	// row and cell formatters).
+	AddHeaderRow(comps ...Comp)
+
+	// FooterRows returns the number of footer rows.
+	FooterRows() int
+
+	// SetFooterRows sets the number of footer rows.
+	// The last rows rows of the table are rendered in the footer
+	// section of the table (tfoot).
+	SetFooterRows(rows int)
+
+	// StickyHeader tells if the header rows are sticky.
+	StickyHeader() bool
+
+	// SetStickyHeader sets whether the header rows are sticky:
+	// if the table is inside a scrollable container (e.g. a Panel
+	// with "overflow:auto" style and a fixed height), the header rows
+	// remain visible when the table body is scrolled.
+	// Sticky header is implemented by the "gwu-Table-Sticky" style class.
+	SetStickyHeader(sticky bool)
+}
+
+// Cell is a non-empty cell of a Table, see Table.Cells().
+type Cell struct {
+	Row, Col int  // Row and col indices of the cell
+	Comp     Comp // Component of the cell
 }
 
 // cellIdx type specifies a cell by its row and col indices.
@@ -102,9 +154,12 @@ type cellIdx struct {
 type tableImpl struct {
 	tableViewImpl // TableView implementation
 
-	comps    [][]Comp                 // Components added to the table. Structure: comps[rowIdx][colIdx]
-	rowFmts  map[int]*cellFmtImpl     // Lazily initialized row formatters of the rows
-	cellFmts map[cellIdx]*cellFmtImpl // Lazily initialized cell formatters of the cells
+	comps      [][]Comp                 // Components added to the table. Structure: comps[rowIdx][colIdx]
+	rowFmts    map[int]*cellFmtImpl     // Lazily initialized row formatters of the rows
+	colFmts    map[int]*cellFmtImpl     // Lazily initialized column formatters of the columns
+	cellFmts   map[cellIdx]*cellFmtImpl // Lazily initialized cell formatters of the cells
+	headerRows int                      // Number of header rows
+	footerRows int                      // Number of footer rows
 }
 
 // NewTable creates a new Table.
@@ -124,9 +179,12 @@ func (c *tableImpl) Remove(c2 Comp) bool {
 		return false
 	}
 
-	c2.setParent(nil)
+	c2.onRemoved(c)
 	c.comps[row][col] = nil
 
+	// Remove associated cell formatter
+	delete(c.cellFmts, cellIdx{row, col})
+
 	return true
 }
 
@@ -154,6 +212,39 @@ func (c *tableImpl) ByID(id ID) Comp {
 	return nil
 }
 
+func (c *tableImpl) childComps() []Comp {
+	var comps []Comp
+	for _, rowComps := range c.comps {
+		for _, c2 := range rowComps {
+			if c2 != nil {
+				comps = append(comps, c2)
+			}
+		}
+	}
+	return comps
+}
+
+func (c *tableImpl) dispose() {
+	c.compImpl.dispose()
+	c.comps = nil
+	c.rowFmts, c.colFmts, c.cellFmts = nil, nil, nil
+}
+
+func (c *tableImpl) childPath(id ID) string {
+	for row, rowComps := range c.comps {
+		for col, c2 := range rowComps {
+			if c2 != nil && c2.ID() == id {
+				return pathSeg(c2, row, col)
+			}
+		}
+	}
+	return ""
+}
+
+func (c *tableImpl) SetEnabledRecursive(enabled bool) {
+	setEnabledRecursive(c, enabled)
+}
+
 func (c *tableImpl) Clear() {
 	// Clear row formatters
 	if c.rowFmts != nil {
@@ -163,11 +254,15 @@ func (c *tableImpl) Clear() {
 	if c.cellFmts != nil {
 		c.cellFmts = nil
 	}
+	// Clear column formatters
+	if c.colFmts != nil {
+		c.colFmts = nil
+	}
 
 	for _, rowComps := range c.comps {
 		for _, c2 := range rowComps {
 			if c2 != nil {
-				c2.setParent(nil)
+				c2.onRemoved(c)
 			}
 		}
 	}
@@ -199,6 +294,33 @@ func (c *tableImpl) ensureRows(rows int) {
 	}
 }
 
+// truncateRows removes the rows starting at the specified row
+// (including their components and formatters).
+func (c *tableImpl) truncateRows(rows int) {
+	if rows >= len(c.comps) {
+		return
+	}
+	for _, rowComps := range c.comps[rows:] {
+		for _, c2 := range rowComps {
+			if c2 != nil {
+				c2.onRemoved(c)
+			}
+		}
+	}
+	c.comps = c.comps[:rows]
+
+	for row := range c.rowFmts {
+		if row >= rows {
+			delete(c.rowFmts, row)
+		}
+	}
+	for ci := range c.cellFmts {
+		if ci.row >= rows {
+			delete(c.cellFmts, ci)
+		}
+	}
+}
+
 func (c *tableImpl) CompsCount() (count int) {
 	for _, rowComps := range c.comps {
 		for _, c2 := range rowComps {
@@ -223,6 +345,18 @@ func (c *tableImpl) CompAt(row, col int) Comp {
 	return rowComps[col]
 }
 
+func (c *tableImpl) Cells() []Cell {
+	var cells []Cell
+	for row, rowComps := range c.comps {
+		for col, c2 := range rowComps {
+			if c2 != nil {
+				cells = append(cells, Cell{Row: row, Col: col, Comp: c2})
+			}
+		}
+	}
+	return cells
+}
+
 func (c *tableImpl) CompIdx(c2 Comp) (int, int) {
 	for row, rowComps := range c.comps {
 		for col, c3 := range rowComps {
@@ -256,6 +390,35 @@ func (c *tableImpl) RowFmt(row int) CellFmt {
 	return rf
 }
 
+func (c *tableImpl) ColFmt(col int) CellFmt {
+	if col < 0 || col >= c.colsCount() {
+		return nil
+	}
+
+	if c.colFmts == nil {
+		c.colFmts = make(map[int]*cellFmtImpl)
+	}
+
+	cf := c.colFmts[col]
+	if cf == nil {
+		cf = newCellFmtImpl()
+		c.colFmts[col] = cf
+	}
+
+	return cf
+}
+
+// colsCount returns the number of columns of the table
+// (the number of columns of the longest row).
+func (c *tableImpl) colsCount() (cols int) {
+	for _, rowComps := range c.comps {
+		if len(rowComps) > cols {
+			cols = len(rowComps)
+		}
+	}
+	return
+}
+
 func (c *tableImpl) CellFmt(row, col int) CellFmt {
 	if row < 0 || col < 0 || row >= len(c.comps) || col >= len(c.comps[row]) {
 		return nil
@@ -294,11 +457,11 @@ func (c *tableImpl) Add(c2 Comp, row, col int) bool {
 
 	// Remove component if there is already one at the specified row and column:
 	if rowComps[col] != nil {
-		rowComps[col].setParent(nil)
+		rowComps[col].onRemoved(c)
 	}
 
 	rowComps[col] = c2
-	c2.setParent(c)
+	setParent(c2, c)
 
 	return true
 }
@@ -369,27 +532,186 @@ func (c *tableImpl) TrimRow(row int) {
 	c.comps[row] = rowComps[:ci.col+1]
 }
 
+func (c *tableImpl) HeaderRows() int {
+	return c.headerRows
+}
+
+func (c *tableImpl) SetHeaderRows(rows int) {
+	if rows < 0 {
+		rows = 0
+	}
+	c.headerRows = rows
+}
+
+func (c *tableImpl) AddHeaderRow(comps ...Comp) {
+	row := c.headerRows
+	c.insertRow(row)
+	c.headerRows++
+
+	for col, c2 := range comps {
+		if c2 != nil {
+			c.Add(c2, row, col)
+		}
+	}
+}
+
+// insertRow inserts an empty row at the specified index,
+// shifting the rows (and their formatters) at and after it down by one.
+func (c *tableImpl) insertRow(row int) {
+	if row >= len(c.comps) {
+		c.ensureRows(row + 1)
+		return
+	}
+
+	c.comps = append(c.comps, nil)
+	copy(c.comps[row+1:], c.comps[row:])
+	c.comps[row] = nil
+
+	if c.rowFmts != nil {
+		rowFmts := make(map[int]*cellFmtImpl, len(c.rowFmts))
+		for r, rf := range c.rowFmts {
+			if r >= row {
+				r++
+			}
+			rowFmts[r] = rf
+		}
+		c.rowFmts = rowFmts
+	}
+	if c.cellFmts != nil {
+		cellFmts := make(map[cellIdx]*cellFmtImpl, len(c.cellFmts))
+		for ci, cf := range c.cellFmts {
+			if ci.row >= row {
+				ci.row++
+			}
+			cellFmts[ci] = cf
+		}
+		c.cellFmts = cellFmts
+	}
+}
+
+func (c *tableImpl) FooterRows() int {
+	return c.footerRows
+}
+
+func (c *tableImpl) SetFooterRows(rows int) {
+	if rows < 0 {
+		rows = 0
+	}
+	c.footerRows = rows
+}
+
+func (c *tableImpl) StickyHeader() bool {
+	for _, class := range c.styleImpl.classes {
+		if class == "gwu-Table-Sticky" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *tableImpl) SetStickyHeader(sticky bool) {
+	if sticky == c.StickyHeader() {
+		return
+	}
+	if sticky {
+		c.Style().AddClass("gwu-Table-Sticky")
+	} else {
+		c.Style().RemoveClass("gwu-Table-Sticky")
+	}
+}
+
+var (
+	strTHeadOp = []byte("<thead>")  // "<thead>"
+	strTHeadCl = []byte("</thead>") // "</thead>"
+	strTBodyOp = []byte("<tbody>")  // "<tbody>"
+	strTBodyCl = []byte("</tbody>") // "</tbody>"
+	strTFootOp = []byte("<tfoot>")  // "<tfoot>"
+	strTFootCl = []byte("</tfoot>") // "</tfoot>"
+	strTH      = []byte("<th>")     // "<th>"
+	strTHOp    = []byte("<th")      // "<th"
+)
+
 func (c *tableImpl) Render(w Writer) {
 	w.Write(strTableOp)
 	c.renderAttrsAndStyle(w)
 	c.renderEHandlers(w)
 	w.Write(strGT)
 
+	c.renderColGroup(w)
+
+	rows := len(c.comps)
+	headerRows := c.headerRows
+	if headerRows > rows {
+		headerRows = rows
+	}
+	footerRows := c.footerRows
+	if footerRows > rows-headerRows {
+		footerRows = rows - headerRows
+	}
+
+	if headerRows == 0 && footerRows == 0 {
+		c.renderRows(0, rows, strTD, strTDOp, w)
+	} else {
+		if headerRows > 0 {
+			w.Write(strTHeadOp)
+			c.renderRows(0, headerRows, strTH, strTHOp, w)
+			w.Write(strTHeadCl)
+		}
+		w.Write(strTBodyOp)
+		c.renderRows(headerRows, rows-footerRows, strTD, strTDOp, w)
+		w.Write(strTBodyCl)
+		if footerRows > 0 {
+			w.Write(strTFootOp)
+			c.renderRows(rows-footerRows, rows, strTD, strTDOp, w)
+			w.Write(strTFootCl)
+		}
+	}
+
+	w.Write(strTableCl)
+}
+
+// renderColGroup renders the column formatters in a colgroup tag
+// (if there are column formatters).
+func (c *tableImpl) renderColGroup(w Writer) {
+	if len(c.colFmts) == 0 {
+		return
+	}
+
+	// Render col tags up to the last formatted column
+	last := -1
+	for col := range c.colFmts {
+		if col > last {
+			last = col
+		}
+	}
+
+	w.Write(strColGroupOp)
+	for col := 0; col <= last; col++ {
+		if cf := c.colFmts[col]; cf == nil {
+			w.Write(strCol)
+		} else {
+			cf.render(strColOp, w)
+		}
+	}
+	w.Write(strColGroupCl)
+}
+
+// renderRows renders the rows in the range [from, to) using the specified
+// cell tag (e.g. "<td>") and cell tag opening (e.g. "<td").
+func (c *tableImpl) renderRows(from, to int, cellTag, cellTagOp []byte, w Writer) {
 	// Create a reusable cell index
 	ci := cellIdx{}
 
-	for row, rowComps := range c.comps {
+	for row := from; row < to; row++ {
 		c.renderRowTr(row, w)
-		for col, c2 := range rowComps {
+		for col, c2 := range c.comps[row] {
 			ci.row, ci.col = row, col
-			c.renderTd(ci, w)
+			c.renderCell(ci, cellTag, cellTagOp, w)
 			if c2 != nil {
 				c2.Render(w)
 			}
 		}
 	}
-
-	w.Write(strTableCl)
 }
 
 // renderRowTr renders the formatted HTML TR tag for the specified row.
@@ -414,11 +736,11 @@ func (c *tableImpl) renderRowTr(row int, w Writer) {
 	}
 }
 
-// renderTd renders the formatted HTML TD tag for the specified cell.
-func (c *tableImpl) renderTd(ci cellIdx, w Writer) {
+// renderCell renders the formatted HTML cell tag (TD or TH) for the specified cell.
+func (c *tableImpl) renderCell(ci cellIdx, cellTag, cellTagOp []byte, w Writer) {
 	if cf := c.cellFmts[ci]; cf == nil {
-		w.Write(strTD)
+		w.Write(cellTag)
 	} else {
-		cf.render(strTDOp, w)
+		cf.render(cellTagOp, w)
 	}
 }
@@ -154,8 +154,10 @@ type tabPanelImpl struct {
 // default vertical alignment is VADefault.
 func NewTabPanel() TabPanel {
 	c := &tabPanelImpl{panelImpl: newPanelImpl(), tabBarImpl: newTabBarImpl(), tabBarFmt: newCellFmtImpl(), selected: -1, prevSelected: -1}
+	c.outer = c
 	c.tabBarFmt.Style().AddClass("gwu-TabBar")
-	c.tabBarImpl.setParent(c)
+	setParent(c.tabBarImpl, c)
+	c.tabBarImpl.SetAttr("role", "tablist")
 	c.SetTabBarPlacement(TbPlacementTop)
 	c.tabBarFmt.SetAlign(HALeft, VATop)
 	c.Style().AddClass("gwu-TabPanel")
@@ -176,7 +178,12 @@ func (c *tabPanelImpl) Remove(c2 Comp) bool {
 	}
 
 	// It's a content component
-	c.tabBarImpl.panelImpl.Remove(c.tabBarImpl.CompAt(i))
+	tab := c.tabBarImpl.CompAt(i)
+	c.tabBarImpl.panelImpl.Remove(tab)
+	tab.onRemoved(c) // Also remove our internal handler
+	tab.SetAttr("role", "")
+	tab.SetAttr("tabindex", "")
+	tab.SetAttr("aria-selected", "")
 	c.panelImpl.Remove(c2)
 
 	// Update the previous selected
@@ -194,6 +201,7 @@ func (c *tabPanelImpl) Remove(c2 Comp) bool {
 	} else if i == c.selected { // Selected tab was removed...
 		// Store previous selected as it will be implicitly changed here
 		prevSelected := c.prevSelected
+		c.selected = -1 // The selected tab is already removed, there's nothing to deselect
 		if i < c.CompsCount() {
 			c.SetSelected(i) // There is next tab, select it
 		} else if i > 0 { // Last was selected and removed but there are previous tabs...
@@ -223,7 +231,21 @@ func (c *tabPanelImpl) ByID(id ID) Comp {
 	return nil
 }
 
+func (c *tabPanelImpl) childComps() []Comp {
+	return append([]Comp{c.tabBarImpl}, c.comps...)
+}
+
+func (c *tabPanelImpl) childPath(id ID) string {
+	if id == c.tabBarImpl.id {
+		return "tabbar"
+	}
+	return c.panelImpl.childPath(id)
+}
+
 func (c *tabPanelImpl) Clear() {
+	for _, tab := range c.tabBarImpl.comps {
+		tab.onRemoved(c) // Remove our internal handlers
+	}
 	c.tabBarImpl.Clear()
 	c.panelImpl.Clear()
 
@@ -284,19 +306,23 @@ func (c *tabPanelImpl) Add(tab, content Comp) {
 	c.panelImpl.Add(content)
 	c.tabBarImpl.CellFmt(tab).Style().AddClass("gwu-TabBar-NotSelected")
 	c.CellFmt(content).Style().AddClass("gwu-TabPanel-Content")
+	// Tabs are focusable with a roving tabindex: only the selected one is in the tab order,
+	// arrow keys move between tabs, Enter and Space click them (see js.go)
+	tab.SetAttr("role", "tab")
+	setTabSelectedAttrs(tab, false)
 
 	if c.CompsCount() == 1 {
 		c.SetSelected(0)
 	}
 
-	// TODO would be nice to remove this internal handler func when the tab is removed!
-	tab.AddEHandlerFunc(func(e Event) {
+	// This internal handler is removed when the tab is removed (see Remove())
+	tab.AddEHandler(internalHandler{c.base(), func(e Event) {
 		c.SetSelected(c.CompIdx(content))
 		e.MarkDirty(c)
 		if c.handlers[ETypeStateChange] != nil {
 			c.dispatchEvent(e.forkEvent(ETypeStateChange, c))
 		}
-	}, ETypeClick)
+	}}, ETypeClick)
 }
 
 func (c *tabPanelImpl) AddString(tab string, content Comp) {
@@ -320,9 +346,11 @@ func (c *tabPanelImpl) SetSelected(idx int) {
 
 	if c.selected >= 0 {
 		// Deselect current selected
-		style := c.tabBarImpl.CellFmt(c.tabBarImpl.CompAt(c.selected)).Style()
+		tab := c.tabBarImpl.CompAt(c.selected)
+		style := c.tabBarImpl.CellFmt(tab).Style()
 		style.RemoveClass("gwu-TabBar-Selected")
 		style.AddClass("gwu-TabBar-NotSelected")
+		setTabSelectedAttrs(tab, false)
 	}
 
 	c.prevSelected = c.selected
@@ -330,9 +358,22 @@ func (c *tabPanelImpl) SetSelected(idx int) {
 
 	if c.selected >= 0 {
 		// Select new selected
-		style := c.tabBarImpl.CellFmt(c.tabBarImpl.CompAt(c.selected)).Style()
+		tab := c.tabBarImpl.CompAt(c.selected)
+		style := c.tabBarImpl.CellFmt(tab).Style()
 		style.RemoveClass("gwu-TabBar-NotSelected")
 		style.AddClass("gwu-TabBar-Selected")
+		setTabSelectedAttrs(tab, true)
+	}
+}
+
+// setTabSelectedAttrs sets the tabindex and ARIA selected attributes of a tab.
+func setTabSelectedAttrs(tab Comp, selected bool) {
+	if selected {
+		tab.SetAttr("tabindex", "0")
+		tab.SetAttr("aria-selected", "true")
+	} else {
+		tab.SetAttr("tabindex", "-1")
+		tab.SetAttr("aria-selected", "false")
 	}
 }
 
@@ -342,6 +383,12 @@ func (c *tabPanelImpl) Render(w Writer) {
 	c.renderEHandlers(w)
 	w.Write(strGT)
 
+	if reportMode(w) {
+		c.renderReport(w)
+		w.Write(strTableCl)
+		return
+	}
+
 	switch c.tabBarPlacement {
 	case TbPlacementTop:
 		w.Write(strTR)
@@ -370,6 +417,18 @@ func (c *tabPanelImpl) Render(w Writer) {
 	w.Write(strTableCl)
 }
 
+// renderReport renders all tabs stacked, each tab component followed by its content component.
+func (c *tabPanelImpl) renderReport(w Writer) {
+	for i, c2 := range c.comps {
+		w.Write(strTR)
+		c.tabBarFmt.render(strTDOp, w)
+		c.tabBarImpl.CompAt(i).Render(w)
+		c.renderTr(w)
+		c.renderTd(c2, w)
+		c2.Render(w)
+	}
+}
+
 // renderContent renders the selected content component.
 func (c *tabPanelImpl) renderContent(w Writer) {
 	// Render only the selected content component
@@ -18,8 +18,10 @@
 package gwu
 
 import (
+	"html"
 	"net/http"
 	"strconv"
+	"unicode/utf8"
 )
 
 // TextBox interface defines a component for text input purpose.
@@ -74,6 +76,83 @@ type TextBox interface {
 	// allowed in the text box.
 	// Pass -1 to not limit the maximum length.
 	SetMaxLength(maxLength int)
+
+	// Placeholder returns the placeholder text of the text box
+	// (hint displayed when the text box is empty).
+	Placeholder() string
+
+	// SetPlaceholder sets the placeholder text of the text box
+	// (hint displayed when the text box is empty).
+	// Pass an empty string to remove the placeholder.
+	SetPlaceholder(placeholder string)
+
+	// AutoComplete returns the autocomplete hint of the text box,
+	// empty string if not set.
+	AutoComplete() string
+
+	// SetAutoComplete sets the autocomplete hint of the text box,
+	// e.g. "off", "on", "username", "current-password", "new-password", "email".
+	// Pass an empty string to remove the hint.
+	SetAutoComplete(autoComplete string)
+
+	// InputMode returns the input mode hint of the text box,
+	// empty string if not set.
+	InputMode() string
+
+	// SetInputMode sets the input mode hint of the text box which tells
+	// what kind of virtual keyboard to display, e.g. "numeric", "decimal",
+	// "email", "tel", "url", "search", "none".
+	// Pass an empty string to remove the hint.
+	SetInputMode(inputMode string)
+
+	// Pattern returns the regular expression the value of the text box
+	// has to match, empty string if not set.
+	Pattern() string
+
+	// SetPattern sets the regular expression the value of the text box
+	// has to match (checked by the browser only, values are not validated at the server side).
+	// Only applies to one-line text boxes.
+	// Pass an empty string to remove the pattern.
+	SetPattern(pattern string)
+
+	// SpellCheck tells if spell checking is enabled for the text box.
+	// By default spell checking is decided by the browser (usually enabled for text areas).
+	SpellCheck() bool
+
+	// SetSpellCheck sets whether spell checking is enabled for the text box.
+	SetSpellCheck(spellCheck bool)
+
+	// SetSelection sets the selection of the text box, applied in the browser
+	// when the text box is rendered next time (so it has to be marked dirty).
+	// Positions are character (rune) indices in the text; start == end
+	// sets the caret position. A negative end means the end of the text.
+	// Usually you also want to focus the text box with Event.SetFocusedComp().
+	//
+	// The current selection / caret position can be read from events
+	// of the text box with Event.Selection().
+	SetSelection(start, end int)
+
+	// SelectAll selects all the text of the text box when it is rendered next time.
+	// See SetSelection() for details.
+	SelectAll()
+
+	// EnableCounter enables a character counter displayed after the text box,
+	// updated at the client side while typing (without server round-trips).
+	// format may contain the placeholders "{n}" (number of characters),
+	// "{max}" (maximum length, see SetMaxLength()) and "{left}" (characters left).
+	// If format is empty, "{n}/{max}" is used if there is a maximum length, else "{n}".
+	// The counter has the style class "gwu-TextBox-Counter", and also
+	// "gwu-TextBox-Counter-Full" when the maximum length is reached.
+	//
+	// Note: the maximum length is also enforced at the server side:
+	// longer values sent by the client are truncated.
+	EnableCounter(format string)
+
+	// DisableCounter disables the character counter.
+	DisableCounter()
+
+	// CounterEnabled tells if the character counter is enabled.
+	CounterEnabled() bool
 }
 
 // PasswBox interface defines a text box for password input purpose.
@@ -102,6 +181,14 @@ type textBoxImpl struct {
 
 	isPassw    bool // Tells if the text box is a password box
 	rows, cols int  // Number of displayed rows and columns.
+
+	selPending       bool // Tells if there is a selection to be applied at the next rendering
+	selStart, selEnd int  // Selection to be applied
+
+	counter    bool   // Tells if the character counter is enabled
+	counterFmt string // Format of the character counter
+
+	inputType []byte // Type of the input tag if other than text or password (e.g. "time")
 }
 
 var (
@@ -124,7 +211,8 @@ func NewPasswBox(text string) TextBox {
 
 // newTextBoxImpl creates a new textBoxImpl.
 func newTextBoxImpl(valueProviderJs []byte, text string, isPassw bool) textBoxImpl {
-	c := textBoxImpl{newCompImpl(valueProviderJs), newHasTextImpl(text), newHasEnabledImpl(), isPassw, 1, 20}
+	c := textBoxImpl{compImpl: newCompImpl(valueProviderJs), hasTextImpl: newHasTextImpl(text), hasEnabledImpl: newHasEnabledImpl(),
+		isPassw: isPassw, rows: 1, cols: 20}
 	c.AddSyncOnETypes(ETypeChange)
 	return c
 }
@@ -175,6 +263,91 @@ func (c *textBoxImpl) SetMaxLength(maxLength int) {
 	}
 }
 
+func (c *textBoxImpl) Placeholder() string {
+	return html.UnescapeString(c.Attr("placeholder"))
+}
+
+func (c *textBoxImpl) SetPlaceholder(placeholder string) {
+	c.SetAttr("placeholder", html.EscapeString(placeholder))
+}
+
+func (c *textBoxImpl) AutoComplete() string {
+	return html.UnescapeString(c.Attr("autocomplete"))
+}
+
+func (c *textBoxImpl) SetAutoComplete(autoComplete string) {
+	c.SetAttr("autocomplete", html.EscapeString(autoComplete))
+}
+
+func (c *textBoxImpl) InputMode() string {
+	return html.UnescapeString(c.Attr("inputmode"))
+}
+
+func (c *textBoxImpl) SetInputMode(inputMode string) {
+	c.SetAttr("inputmode", html.EscapeString(inputMode))
+}
+
+func (c *textBoxImpl) Pattern() string {
+	return html.UnescapeString(c.Attr("pattern"))
+}
+
+func (c *textBoxImpl) SetPattern(pattern string) {
+	c.SetAttr("pattern", html.EscapeString(pattern))
+}
+
+func (c *textBoxImpl) SpellCheck() bool {
+	if sc := c.Attr("spellcheck"); len(sc) > 0 {
+		return sc == "true"
+	}
+	return c.rows > 1 && !c.isPassw
+}
+
+func (c *textBoxImpl) SetSpellCheck(spellCheck bool) {
+	c.SetAttr("spellcheck", strconv.FormatBool(spellCheck))
+}
+
+func (c *textBoxImpl) SetSelection(start, end int) {
+	c.selPending, c.selStart, c.selEnd = true, start, end
+}
+
+func (c *textBoxImpl) SelectAll() {
+	c.SetSelection(0, -1)
+}
+
+var strSelAttrOp = []byte(" " + attrSelection + `="`) // ` data-gwu-sel="`
+
+// renderSelection renders the pending selection (which is applied by the client only once).
+func (c *textBoxImpl) renderSelection(w Writer) {
+	if !c.selPending {
+		return
+	}
+	c.selPending = false
+	w.Write(strSelAttrOp)
+	w.Writev(c.selStart)
+	w.Write(strComma)
+	w.Writev(c.selEnd)
+	w.Write(strQuote)
+}
+
+func (c *textBoxImpl) EnableCounter(format string) {
+	c.counter, c.counterFmt = true, format
+}
+
+func (c *textBoxImpl) DisableCounter() {
+	c.counter, c.counterFmt = false, ""
+}
+
+func (c *textBoxImpl) CounterEnabled() bool {
+	return c.counter
+}
+
+// renderCounter renders the format of the character counter if it is enabled.
+func (c *textBoxImpl) renderCounter(w Writer) {
+	if c.counter {
+		w.WriteAttr(attrCounter, html.EscapeString(c.counterFmt))
+	}
+}
+
 func (c *textBoxImpl) preprocessEvent(event Event, r *http.Request) {
 	// Empty string for text box is a valid value.
 	// So we have to check whether it is supplied, not just whether its len() > 0
@@ -188,10 +361,15 @@ func (c *textBoxImpl) preprocessEvent(event Event, r *http.Request) {
 			c.text = values[0]
 		}
 	}
+
+	// Enforce the maximum length (the client can't be trusted)
+	if max := c.MaxLength(); max >= 0 && utf8.RuneCountInString(c.text) > max {
+		c.text = string([]rune(c.text)[:max])
+	}
 }
 
 func (c *textBoxImpl) Render(w Writer) {
-	if c.rows <= 1 || c.isPassw {
+	if c.rows <= 1 || c.isPassw || c.inputType != nil {
 		c.renderInput(w)
 	} else {
 		c.renderTextArea(w)
@@ -209,8 +387,17 @@ var (
 
 // renderInput renders the component as an input HTML tag.
 func (c *textBoxImpl) renderInput(w Writer) {
+	c.renderInputOp(w)
+	c.renderInputCl(w)
+}
+
+// renderInputOp renders the opening of the input HTML tag with all its attributes
+// except the value, so embedding components may render additional attributes.
+func (c *textBoxImpl) renderInputOp(w Writer) {
 	w.Write(strInputOp)
-	if c.isPassw {
+	if c.inputType != nil {
+		w.Write(c.inputType)
+	} else if c.isPassw {
 		w.Write(strPassword)
 	} else {
 		w.Write(strText)
@@ -220,8 +407,13 @@ func (c *textBoxImpl) renderInput(w Writer) {
 	w.Write(strQuote)
 	c.renderAttrsAndStyle(w)
 	c.renderEnabled(w)
+	c.renderSelection(w)
+	c.renderCounter(w)
 	c.renderEHandlers(w)
+}
 
+// renderInputCl renders the value attribute and closes the input HTML tag.
+func (c *textBoxImpl) renderInputCl(w Writer) {
 	w.Write(strValue)
 	c.renderText(w)
 	w.Write(strInputCl)
@@ -240,6 +432,8 @@ func (c *textBoxImpl) renderTextArea(w Writer) {
 	w.Write(strTextareaOp)
 	c.renderAttrsAndStyle(w)
 	c.renderEnabled(w)
+	c.renderSelection(w)
+	c.renderCounter(w)
 	c.renderEHandlers(w)
 
 	// New line char after the <textarea> tag is ignored.
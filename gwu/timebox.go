@@ -0,0 +1,210 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// TimeBox and DurationBox component interfaces and implementations.
+
+package gwu
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TimeBox interface defines a component for entering a time of day
+// (rendered as an input tag of type "time"). The browser displays
+// the time according to the locale of the user, e.g. using 12-hour or 24-hour clock.
+//
+// Suggested event type to handle changes: ETypeChange
+//
+// Default style class: "gwu-TimeBox"
+type TimeBox interface {
+	// TimeBox is a component.
+	Comp
+
+	// TimeBox can be enabled/disabled.
+	HasEnabled
+
+	// Time returns the time of day entered, as a time.Time on January 1, year 0 in UTC.
+	// The zero time is returned if no time is entered (it can be tested with Time.IsZero()).
+	Time() time.Time
+
+	// SetTime sets the time of day (only the hour and minute of t are used).
+	// Pass the zero time to clear the time box.
+	SetTime(t time.Time)
+
+	// HourMin returns the hour and minute entered,
+	// (-1, -1) is returned if no time is entered.
+	HourMin() (hour, min int)
+
+	// SetHourMin sets the hour and minute.
+	SetHourMin(hour, min int)
+}
+
+// TimeBox implementation.
+type timeBoxImpl struct {
+	textBoxImpl // TextBox implementation: TimeBox is a TextBox, but only TimeBox's methods are exported.
+}
+
+var strTime = []byte("time") // "time"
+
+// timeLayout is the layout of the value of time input tags.
+const timeLayout = "15:04"
+
+// NewTimeBox creates a new, empty TimeBox.
+func NewTimeBox() TimeBox {
+	c := &timeBoxImpl{newTextBoxImpl(strEncURIThisV, "", false)}
+	c.inputType = strTime
+	c.Style().AddClass("gwu-TimeBox")
+	return c
+}
+
+func (c *timeBoxImpl) Time() time.Time {
+	// Seconds are sent by some browsers if a step less than 1 minute is set
+	text := c.text
+	if len(text) > len(timeLayout) {
+		text = text[:len(timeLayout)]
+	}
+	t, err := time.Parse(timeLayout, text)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func (c *timeBoxImpl) SetTime(t time.Time) {
+	if t.IsZero() {
+		c.text = ""
+		return
+	}
+	c.text = t.Format(timeLayout)
+}
+
+func (c *timeBoxImpl) HourMin() (hour, min int) {
+	t := c.Time()
+	if t.IsZero() {
+		return -1, -1
+	}
+	return t.Hour(), t.Minute()
+}
+
+func (c *timeBoxImpl) SetHourMin(hour, min int) {
+	c.text = fmt.Sprintf("%02d:%02d", hour, min)
+}
+
+func (c *timeBoxImpl) preprocessEvent(event Event, r *http.Request) {
+	c.textBoxImpl.preprocessEvent(event, r)
+	// Values come from the client, invalid values are dropped
+	if c.Time().IsZero() {
+		c.text = ""
+	}
+}
+
+// DurationBox interface defines a text box for entering a duration
+// in the format accepted by time.ParseDuration(), e.g. "1h30m" or "45s".
+//
+// Invalid values are kept (so the user can correct them), but the duration box
+// gets the "gwu-DurationBox-Invalid" style class, and Duration() returns 0.
+// The duration box marks itself dirty when its validity changes.
+//
+// Suggested event type to handle changes: ETypeChange
+//
+// Default style class: "gwu-DurationBox"
+type DurationBox interface {
+	// DurationBox is a TextBox.
+	TextBox
+
+	// Duration returns the duration entered.
+	// 0 is returned if the text box is empty or the text is not a valid duration.
+	Duration() time.Duration
+
+	// SetDuration sets the duration.
+	SetDuration(d time.Duration)
+
+	// Valid tells if the text of the duration box is empty or a valid duration.
+	Valid() bool
+}
+
+// DurationBox implementation.
+type durationBoxImpl struct {
+	textBoxImpl // TextBox implementation
+
+	valid bool // Tells if the text is a valid duration
+}
+
+// NewDurationBox creates a new DurationBox with the specified duration.
+func NewDurationBox(d time.Duration) DurationBox {
+	c := &durationBoxImpl{textBoxImpl: newTextBoxImpl(strEncURIThisV, "", false)}
+	c.Style().AddClass("gwu-DurationBox")
+	c.SetDuration(d)
+
+	c.AddEHandler(internalHandler{c.base(), func(e Event) {
+		if valid := c.valid; c.validate() != valid {
+			e.MarkDirty(c)
+		}
+	}}, ETypeChange)
+	return c
+}
+
+// validate validates the text, updates the valid flag and style class accordingly,
+// and returns the valid flag.
+func (c *durationBoxImpl) validate() bool {
+	c.valid = true
+	if c.text != "" {
+		_, err := time.ParseDuration(c.text)
+		c.valid = err == nil
+	}
+	if c.valid {
+		c.Style().RemoveClass("gwu-DurationBox-Invalid")
+	} else {
+		c.Style().AddClass("gwu-DurationBox-Invalid")
+	}
+	return c.valid
+}
+
+func (c *durationBoxImpl) Duration() time.Duration {
+	d, err := time.ParseDuration(c.text)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+func (c *durationBoxImpl) SetDuration(d time.Duration) {
+	c.SetText(formatDuration(d))
+}
+
+func (c *durationBoxImpl) SetText(text string) {
+	c.text = text
+	c.validate()
+}
+
+func (c *durationBoxImpl) Valid() bool {
+	return c.valid
+}
+
+// formatDuration formats a duration like Duration.String(),
+// but omits the trailing zero units, e.g. "1h30m" instead of "1h30m0s".
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
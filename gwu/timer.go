@@ -30,7 +30,7 @@ import (
 // Note that receiving an event from a Timer (like from any other components)
 // updates the last accessed property of the associated session, causing
 // a session never to expire if there are active timers on repeat at the
-// client side.
+// client side. This can be disabled with SetKeepAlive(false).
 //
 // Also note that the Timer component operates at the client side meaning
 // if the client is closed (or navigates away), events will not be generated.
@@ -73,6 +73,35 @@ type Timer interface {
 	// By calling Reset() the countdown will reset when the timer is
 	// re-rendered.
 	Reset()
+
+	// SkipIfPending tells if ticks are skipped while the event of a previous tick is pending.
+	SkipIfPending() bool
+
+	// SetSkipIfPending sets whether ticks are skipped (no event is sent)
+	// while the event of a previous tick is still being processed (its response
+	// has not yet arrived), so slow event processing does not pile up requests.
+	// The default is false.
+	SetSkipIfPending(skip bool)
+
+	// MaxTicks returns the maximum number of ticks (generated events) of the timer.
+	// 0 means no limit.
+	MaxTicks() int
+
+	// SetMaxTicks sets the maximum number of ticks (generated events) of the timer,
+	// after which the timer stops at the client side. Only applies to timers on repeat.
+	// The tick counter is reset when the timer config is changed or Reset() is called.
+	// Pass 0 for no limit. This is the default.
+	SetMaxTicks(n int)
+
+	// KeepAlive tells if the events of the timer update the last accessed
+	// property of the associated session.
+	KeepAlive() bool
+
+	// SetKeepAlive sets whether the events of the timer update the last accessed
+	// property of the associated session (see Session.Accessed()).
+	// Timers of monitoring dashboards should set this to false,
+	// else their sessions never expire. The default is true.
+	SetKeepAlive(keepAlive bool)
 }
 
 // Timer implementation
@@ -83,6 +112,10 @@ type timerImpl struct {
 	repeat  bool          // Tells if timer is on repeat
 	active  bool          // Tells if the timer is active
 	reset   int           // Reset counter
+
+	skipIfPending bool // Tells if ticks are skipped while a previous event is pending
+	maxTicks      int  // Max number of ticks, 0 means no limit
+	noKeepAlive   bool // Tells if timer events do not update the session's last accessed time
 }
 
 // NewTimer creates a new Timer.
@@ -122,17 +155,45 @@ func (c *timerImpl) Reset() {
 	c.reset++
 }
 
+func (c *timerImpl) SkipIfPending() bool {
+	return c.skipIfPending
+}
+
+func (c *timerImpl) SetSkipIfPending(skip bool) {
+	c.skipIfPending = skip
+}
+
+func (c *timerImpl) MaxTicks() int {
+	return c.maxTicks
+}
+
+func (c *timerImpl) SetMaxTicks(n int) {
+	if n < 0 {
+		n = 0
+	}
+	c.maxTicks = n
+}
+
+func (c *timerImpl) KeepAlive() bool {
+	return !c.noKeepAlive
+}
+
+func (c *timerImpl) SetKeepAlive(keepAlive bool) {
+	c.noKeepAlive = !keepAlive
+}
+
 var (
-	strSetupTimerOp = []byte("setupTimer(") // "setupTimer("
-	strJsSendEvtOp  = []byte("se(null,")    // "se(null,"
+	strSetupTimerOp  = []byte("setupTimer(") // "setupTimer("
+	strJsSendEvtOp   = []byte("se(null,")    // "se(null,"
+	strJsSendEvtSfOp = []byte("sesf(null,")  // "sesf(null,"
 )
 
 // renderSetupTimerJs renders the Javascript code which sets up the timer.
 // jsVs param holds the values which render Javascript code to be scheduled:
-//     setupTimer(compId,"jscode",timeout,repeat,active,reset);
+//     setupTimer(compId,"jscode",timeout,repeat,active,reset,maxTicks);
 func (c *timerImpl) renderSetupTimerJs(w Writer, jsVs ...interface{}) {
 	w.Write(strSetupTimerOp)
-	w.Writev(int(c.id))
+	writeID(w, c.id)
 	w.Write(strComma)
 	// js param
 	w.Write(strQuote)
@@ -147,6 +208,8 @@ func (c *timerImpl) renderSetupTimerJs(w Writer, jsVs ...interface{}) {
 	w.Writev(c.active)
 	w.Write(strComma)
 	w.Writev(c.reset)
+	w.Write(strComma)
+	w.Writev(c.maxTicks)
 	w.Write(strJsFuncCl)
 }
 
@@ -156,8 +219,17 @@ func (c *timerImpl) Render(w Writer) {
 	c.renderEHandlers(w)
 	w.Write(strGT)
 
+	if reportMode(w) {
+		w.Write(strSpanCl)
+		return
+	}
+
 	w.Write(strScriptOp)
-	c.renderSetupTimerJs(w, strJsSendEvtOp, int(ETypeStateChange), strComma, int(c.id), strJsFuncCl)
+	sendEvtOp := strJsSendEvtOp
+	if c.skipIfPending {
+		sendEvtOp = strJsSendEvtSfOp
+	}
+	c.renderSetupTimerJs(w, sendEvtOp, int(ETypeStateChange), strComma, c.id, strJsFuncCl)
 	w.Write(strScriptCl)
 
 	w.Write(strSpanCl)
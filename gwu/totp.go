@@ -0,0 +1,137 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// TOTP (time-based one-time password) helpers.
+
+package gwu
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters (the defaults of authenticator apps, see RFC 6238).
+const (
+	totpStep       = 30 * time.Second // Time step
+	totpSecretSize = 20               // Size of generated secrets in bytes
+)
+
+// GenerateTOTPSecret generates a new random TOTP secret, base32 encoded
+// (without padding) as expected by authenticator apps.
+// Store it with the user, and show it to the user (e.g. as a QR code of TOTPURI()).
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(base32.StdEncoding.EncodeToString(secret), "="), nil
+}
+
+// TOTPURI returns the otpauth URI of a TOTP secret, which can be rendered as a QR code
+// to be scanned by authenticator apps. issuer is the name of the application,
+// account is the name of the user's account (e.g. email address).
+func TOTPURI(issuer, account, secret string) string {
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	return "otpauth://totp/" + url.PathEscape(issuer+":"+account) + "?" + q.Encode()
+}
+
+// decodeTOTPSecret decodes a base32 encoded TOTP secret.
+// Spaces are ignored, and padding and lower case letters are accepted.
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.Replace(secret, " ", "", -1))
+	if n := len(secret) % 8; n != 0 && !strings.HasSuffix(secret, "=") {
+		secret += strings.Repeat("=", 8-n)
+	}
+	key, err := base32.StdEncoding.DecodeString(secret)
+	if err == nil && len(key) == 0 {
+		err = errors.New("empty TOTP secret")
+	}
+	return key, err
+}
+
+// TOTPCode returns the 6-digit TOTP code of the base32 encoded secret at the specified time
+// (HMAC-SHA1 with 30 second time steps, see RFC 6238).
+func TOTPCode(secret string, t time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return totpCode(key, t.Unix()/int64(totpStep/time.Second)), nil
+}
+
+// totpCode returns the 6-digit TOTP code of the key for the specified time step counter.
+func totpCode(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation (RFC 4226)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+
+	code := make([]byte, twoFactorDigits)
+	for i := len(code) - 1; i >= 0; i-- {
+		code[i] = byte('0' + value%10)
+		value /= 10
+	}
+	return string(code)
+}
+
+// VerifyTOTP tells if the code is a valid TOTP code of the base32 encoded secret
+// at the specified time. skew is the number of time steps (of 30 seconds) accepted
+// before and after the current one, to tolerate clock drift and typing delays
+// (1 is a good value).
+//
+// Note that a code remains valid for the whole accepted window: to prevent replay attacks,
+// remember the last accepted code (or time step) of the user, and refuse reusing it.
+//
+// Example: verify the code entered into a TwoFactorBox:
+//
+//	tfb.AddEHandlerFunc(func(e gwu.Event) {
+//		if gwu.VerifyTOTP(user.secret, tfb.Code(), time.Now(), 1) {
+//			e.Session().SetRoles(user.roles)
+//		}
+//	}, gwu.ETypeChange)
+func VerifyTOTP(secret, code string, t time.Time, skew int) bool {
+	if !validTwoFactorCode(code) {
+		return false
+	}
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return false
+	}
+
+	counter := t.Unix() / int64(totpStep/time.Second)
+	valid := false
+	for i := -skew; i <= skew; i++ {
+		// Constant time comparison, and no early return to not leak timing information
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, counter+int64(i))), []byte(code)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
@@ -0,0 +1,56 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTOTP(t *testing.T) {
+	// Test vectors of RFC 6238 (truncated to 6 digits), secret is "12345678901234567890"
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	cases := []struct {
+		t    int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{20000000000, "353130"},
+	}
+	for _, c := range cases {
+		tm := time.Unix(c.t, 0)
+		if code, err := TOTPCode(secret, tm); err != nil || code != c.code {
+			t.Errorf("[t: %d] Got: %s, %v, want: %s", c.t, code, err, c.code)
+		}
+		if !VerifyTOTP(secret, c.code, tm.Add(30*time.Second), 1) {
+			t.Errorf("[t: %d] Code not accepted in the next time step", c.t)
+		}
+		if VerifyTOTP(secret, c.code, tm.Add(90*time.Second), 1) {
+			t.Errorf("[t: %d] Code accepted outside of the skew", c.t)
+		}
+	}
+
+	gen, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("Failed to generate secret: %v", err)
+	}
+	code, _ := TOTPCode(gen, time.Now())
+	if !VerifyTOTP(gen, code, time.Now(), 0) || VerifyTOTP(gen, "12345", time.Now(), 1) {
+		t.Errorf("Verification failed with generated secret: %s", gen)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// TransferList component interface and implementation.
+
+package gwu
+
+// TransferList interface defines a dual-list selector: a PanelView which holds
+// a list box of the available values, a list box of the chosen values,
+// and buttons between them to move the selected (or all) values from one list to the other.
+// Values keep their original order in both lists.
+//
+// You can register ETypeChange event handlers which will be called when the user
+// changes the chosen values. The event source will be the transfer list.
+// The event will have a parent event whose source will be the clicked button.
+//
+// Default style class: "gwu-TransferList"
+type TransferList interface {
+	// TransferList is a PanelView.
+	PanelView
+
+	// Values returns all the values (both available and chosen).
+	Values() []string
+
+	// SetValues sets the values. All values will be available (none chosen).
+	SetValues(values []string)
+
+	// Chosen returns the chosen values.
+	Chosen() []string
+
+	// SetChosen sets the chosen values. Values not contained in
+	// the values of the transfer list are ignored.
+	SetChosen(chosen []string)
+
+	// AvailableBox returns the list box of the available values,
+	// e.g. to set its rows or style.
+	AvailableBox() ListBox
+
+	// ChosenBox returns the list box of the chosen values,
+	// e.g. to set its rows or style.
+	ChosenBox() ListBox
+}
+
+// TransferList implementation.
+type transferListImpl struct {
+	panelImpl // panel implementation: TransferList is a Panel, but only PanelView's methods are exported.
+
+	values    []string // All values
+	chosen    []bool   // Chosen states of the values
+	availBox  ListBox  // List box of the available values
+	chosenBox ListBox  // List box of the chosen values
+	availIdx  []int    // Value indices of the items of the available list box
+	chosenIdx []int    // Value indices of the items of the chosen list box
+}
+
+// NewTransferList creates a new TransferList with the specified values,
+// all values being available (none chosen).
+// Default layout strategy is LayoutHorizontal.
+func NewTransferList(values []string) TransferList {
+	c := &transferListImpl{panelImpl: newPanelImpl()}
+	c.outer = c
+	c.SetLayout(LayoutHorizontal)
+	c.Style().AddClass("gwu-TransferList")
+
+	c.availBox, c.chosenBox = NewListBox(nil), NewListBox(nil)
+	for _, lb := range []ListBox{c.availBox, c.chosenBox} {
+		lb.SetMulti(true)
+		lb.SetRows(10)
+		lb.Style().AddClass("gwu-TransferList-List")
+	}
+
+	btns := NewVerticalPanel()
+	btns.Style().AddClass("gwu-TransferList-Buttons")
+	c.addButton(btns, ">", "Add selected", func() { c.move(c.availBox, c.availIdx, true) })
+	c.addButton(btns, ">>", "Add all", func() { c.moveAll(true) })
+	c.addButton(btns, "<", "Remove selected", func() { c.move(c.chosenBox, c.chosenIdx, false) })
+	c.addButton(btns, "<<", "Remove all", func() { c.moveAll(false) })
+
+	c.panelImpl.Add(c.availBox)
+	c.panelImpl.Add(btns)
+	c.panelImpl.Add(c.chosenBox)
+
+	c.SetValues(values)
+	return c
+}
+
+// addButton adds a button to the specified panel which calls the move function
+// when clicked, and fires an ETypeChange event if the chosen values changed.
+func (c *transferListImpl) addButton(p Panel, text, toolTip string, move func()) {
+	b := NewButton(text)
+	b.SetToolTip(toolTip)
+	b.AddEHandler(internalHandler{c.base(), func(e Event) {
+		old := append([]bool(nil), c.chosen...)
+		move()
+		for i, ch := range c.chosen {
+			if ch != old[i] {
+				e.MarkDirty(c)
+				if c.handlers[ETypeChange] != nil {
+					c.dispatchEvent(e.forkEvent(ETypeChange, c))
+				}
+				return
+			}
+		}
+	}}, ETypeClick)
+	p.Add(b)
+}
+
+// move sets the chosen state of the values selected in the specified list box.
+func (c *transferListImpl) move(lb ListBox, idxs []int, chosen bool) {
+	for _, i := range lb.SelectedIndices() {
+		c.chosen[idxs[i]] = chosen
+	}
+	c.refresh()
+}
+
+// moveAll sets the chosen state of all values.
+func (c *transferListImpl) moveAll(chosen bool) {
+	for i := range c.chosen {
+		c.chosen[i] = chosen
+	}
+	c.refresh()
+}
+
+// refresh rebuilds the values of the list boxes from the chosen states.
+func (c *transferListImpl) refresh() {
+	var avail, chosen []string
+	c.availIdx, c.chosenIdx = nil, nil
+	for i, v := range c.values {
+		if c.chosen[i] {
+			chosen = append(chosen, v)
+			c.chosenIdx = append(c.chosenIdx, i)
+		} else {
+			avail = append(avail, v)
+			c.availIdx = append(c.availIdx, i)
+		}
+	}
+	c.availBox.SetValues(avail)
+	c.chosenBox.SetValues(chosen)
+}
+
+func (c *transferListImpl) Values() []string {
+	return c.values
+}
+
+func (c *transferListImpl) SetValues(values []string) {
+	c.values = values
+	c.chosen = make([]bool, len(values))
+	c.refresh()
+}
+
+func (c *transferListImpl) Chosen() []string {
+	return c.chosenBox.Values()
+}
+
+func (c *transferListImpl) SetChosen(chosen []string) {
+	m := make(map[string]bool, len(chosen))
+	for _, v := range chosen {
+		m[v] = true
+	}
+	for i, v := range c.values {
+		c.chosen[i] = m[v]
+	}
+	c.refresh()
+}
+
+func (c *transferListImpl) AvailableBox() ListBox {
+	return c.availBox
+}
+
+func (c *transferListImpl) ChosenBox() ListBox {
+	return c.chosenBox
+}
@@ -0,0 +1,128 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// TwoFactorBox component interface and implementation.
+
+package gwu
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// TwoFactorBox interface defines a component for entering the 6-digit codes
+// of two-factor authentication (e.g. TOTP codes, see VerifyTOTP()) in login windows.
+//
+// The code is entered into a segmented input of 6 digit boxes: the focus advances
+// to the next box as digits are typed, backspace steps back, and pasting
+// (or autofilling) a code fills all the boxes.
+//
+// You can register ETypeChange event handlers which will be called
+// when all 6 digits are entered. Code() returns the entered code.
+//
+// Default style classes: "gwu-TwoFactorBox", "gwu-TwoFactorBox-Digit"
+type TwoFactorBox interface {
+	// TwoFactorBox is a component.
+	Comp
+
+	// TwoFactorBox can be enabled/disabled.
+	HasEnabled
+
+	// Code returns the entered code, an empty string if no complete code was entered.
+	Code() string
+
+	// Clear clears the entered code (the component has to be marked dirty).
+	Clear()
+}
+
+// TwoFactorBox implementation.
+type twoFactorBoxImpl struct {
+	compImpl       // Component implementation
+	hasEnabledImpl // Has enabled implementation
+
+	code string // The entered code
+}
+
+// twoFactorDigits is the number of digits of two-factor codes.
+const twoFactorDigits = 6
+
+var strEncTfaCode = []byte("encodeURIComponent(tfaCode(this))") // "encodeURIComponent(tfaCode(this))"
+
+// NewTwoFactorBox creates a new TwoFactorBox.
+func NewTwoFactorBox() TwoFactorBox {
+	c := &twoFactorBoxImpl{compImpl: newCompImpl(strEncTfaCode), hasEnabledImpl: newHasEnabledImpl()}
+	c.AddSyncOnETypes(ETypeChange)
+	c.SetAttr(attrTwoFactor, "1")
+	c.SetAttr("role", "group")
+	c.Style().AddClass("gwu-TwoFactorBox")
+	return c
+}
+
+func (c *twoFactorBoxImpl) Code() string {
+	return c.code
+}
+
+func (c *twoFactorBoxImpl) Clear() {
+	c.code = ""
+}
+
+// validTwoFactorCode tells if the specified code is a valid two-factor code (6 digits).
+func validTwoFactorCode(code string) bool {
+	if len(code) != twoFactorDigits {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < '0' || code[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *twoFactorBoxImpl) preprocessEvent(event Event, r *http.Request) {
+	// The client can't be trusted, only accept complete codes
+	if code := r.FormValue(paramCompValue); validTwoFactorCode(code) {
+		c.code = code
+	} else {
+		c.code = ""
+	}
+}
+
+var (
+	strTfaDigitOp = []byte(`<input type="text" inputmode="numeric" autocomplete="one-time-code" size="1" class="gwu-TwoFactorBox-Digit" aria-label="Digit `) // `<input type="text" ... aria-label="Digit `
+	strTfaValue   = []byte(`" value="`)                                                                                                                      // `" value="`
+	strTfaDigitCl = []byte("/>")                                                                                                                             // "/>"
+)
+
+func (c *twoFactorBoxImpl) Render(w Writer) {
+	w.Write(strSpanOp)
+	c.renderAttrsAndStyle(w)
+	c.renderEHandlers(w)
+	w.Write(strGT)
+
+	for i := 0; i < twoFactorDigits; i++ {
+		w.Write(strTfaDigitOp)
+		w.Writes(strconv.Itoa(i + 1))
+		w.Write(strTfaValue)
+		if i < len(c.code) {
+			w.Writes(c.code[i : i+1])
+		}
+		w.Write(strQuote)
+		c.renderEnabled(w)
+		w.Write(strTfaDigitCl)
+	}
+
+	w.Write(strSpanCl)
+}
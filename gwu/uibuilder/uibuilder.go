@@ -0,0 +1,372 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package uibuilder builds Gowut component trees from declarative (JSON) definitions.
+
+A definition describes a component: its type, properties, style, event handlers
+(referenced by name) and child components. Example:
+
+	{
+		"type": "window", "name": "main", "text": "Dashboard",
+		"children": [
+			{"type": "label", "text": "Name:", "style": {"font-weight": "bold"}},
+			{"type": "textbox", "key": "nameBox", "cols": 20},
+			{"type": "button", "text": "Save", "handlers": {"click": "save"}}
+		]
+	}
+
+Event handlers are resolved against the handlers registered
+with Builder.AddHandler():
+
+	b := uibuilder.New()
+	b.AddHandler("save", func(e gwu.Event) {
+		// ...
+	})
+	ui, err := b.Build(data)
+	if err != nil {
+		// Handle error
+	}
+	nameBox := ui.ByKey["nameBox"].(gwu.TextBox)
+
+YAML definitions are not supported directly (that would require an external
+dependency), but they can be converted to JSON prior to building.
+*/
+package uibuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/icza/gowut/gwu"
+)
+
+// Def is the definition of a component.
+type Def struct {
+	// Type is the component type, e.g. "label", "button", "panel".
+	// Component types are resolved with gwu.NewCompOfType(), custom component
+	// types can be registered with gwu.RegisterCompType().
+	Type string `json:"type"`
+
+	// Key is an optional key to look up the built component from UI.ByKey.
+	Key string `json:"key,omitempty"`
+
+	// Name is the name of the component (see gwu.Comp.SetName()),
+	// for windows it is the window name.
+	Name string `json:"name,omitempty"`
+
+	// Text is the text of components having a text.
+	Text *string `json:"text,omitempty"`
+
+	// URL is the URL of components having a URL.
+	URL *string `json:"url,omitempty"`
+
+	// HTML is the HTML text of the "html" component type.
+	HTML *string `json:"html,omitempty"`
+
+	// Values are the values of list boxes.
+	Values []string `json:"values,omitempty"`
+
+	// Enabled is the enabled state of components that can be enabled / disabled.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Visible is the visibility of the component.
+	Visible *bool `json:"visible,omitempty"`
+
+	// State is the state of state buttons (e.g. check box).
+	State *bool `json:"state,omitempty"`
+
+	// ReadOnly is the read-only property of text boxes.
+	ReadOnly *bool `json:"readOnly,omitempty"`
+
+	// Multi is the multi-selection property of list boxes.
+	Multi *bool `json:"multi,omitempty"`
+
+	// Rows is the number of rows of text boxes and list boxes.
+	Rows *int `json:"rows,omitempty"`
+
+	// Cols is the number of columns of text boxes.
+	Cols *int `json:"cols,omitempty"`
+
+	// Placeholder is the placeholder text of text boxes.
+	Placeholder string `json:"placeholder,omitempty"`
+
+	// ToolTip is the tool tip of the component.
+	ToolTip string `json:"toolTip,omitempty"`
+
+	// Class is a space separated list of style classes to add.
+	Class string `json:"class,omitempty"`
+
+	// Style holds the style attributes to set.
+	Style map[string]string `json:"style,omitempty"`
+
+	// Attrs holds the HTML attributes to set.
+	Attrs map[string]string `json:"attrs,omitempty"`
+
+	// Handlers maps event type names (e.g. "click", "change") to handler names.
+	Handlers map[string]string `json:"handlers,omitempty"`
+
+	// Children are the child components, added in order.
+	// Only allowed for component types implementing gwu.Panel (e.g. "panel", "window").
+	Children []*Def `json:"children,omitempty"`
+}
+
+// UI is the result of building a component tree.
+type UI struct {
+	// Root is the root component.
+	Root gwu.Comp
+
+	// ByKey holds the built components having a key, mapped from their keys.
+	ByKey map[string]gwu.Comp
+}
+
+// etypeNames maps event type names to event types.
+var etypeNames = map[string]gwu.EventType{
+	"click":       gwu.ETypeClick,
+	"dblclick":    gwu.ETypeDblClick,
+	"mousedown":   gwu.ETypeMouseDown,
+	"mousemove":   gwu.ETypeMouseMove,
+	"mouseover":   gwu.ETypeMouseOver,
+	"mouseout":    gwu.ETypeMouseOut,
+	"mouseup":     gwu.ETypeMouseUp,
+	"keydown":     gwu.ETypeKeyDown,
+	"keypress":    gwu.ETypeKeyPress,
+	"keyup":       gwu.ETypeKeyUp,
+	"blur":        gwu.ETypeBlur,
+	"change":      gwu.ETypeChange,
+	"focus":       gwu.ETypeFocus,
+	"wheel":       gwu.ETypeWheel,
+	"scroll":      gwu.ETypeScroll,
+	"touchstart":  gwu.ETypeTouchStart,
+	"touchmove":   gwu.ETypeTouchMove,
+	"touchend":    gwu.ETypeTouchEnd,
+	"swipeleft":   gwu.ETypeSwipeLeft,
+	"swiperight":  gwu.ETypeSwipeRight,
+	"longpress":   gwu.ETypeLongPress,
+	"winload":     gwu.ETypeWinLoad,
+	"winunload":   gwu.ETypeWinUnload,
+	"reconnect":   gwu.ETypeReconnect,
+	"statechange": gwu.ETypeStateChange,
+}
+
+// Builder builds component trees from definitions.
+type Builder struct {
+	handlers map[string]func(e gwu.Event) // Registered event handlers mapped from their names
+}
+
+// New creates a new Builder.
+func New() *Builder {
+	return &Builder{handlers: map[string]func(e gwu.Event){}}
+}
+
+// AddHandler registers an event handler function under the specified name.
+// Definitions refer to event handlers by these names.
+func (b *Builder) AddHandler(name string, hf func(e gwu.Event)) {
+	b.handlers[name] = hf
+}
+
+// Build builds the component tree from the specified JSON definition.
+func (b *Builder) Build(data []byte) (*UI, error) {
+	def := new(Def)
+	if err := json.Unmarshal(data, def); err != nil {
+		return nil, err
+	}
+	return b.BuildDef(def)
+}
+
+// BuildFrom builds the component tree from the JSON definition read from r.
+func (b *Builder) BuildFrom(r io.Reader) (*UI, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return b.Build(data)
+}
+
+// BuildDef builds the component tree from the specified definition.
+func (b *Builder) BuildDef(def *Def) (*UI, error) {
+	ui := &UI{ByKey: map[string]gwu.Comp{}}
+
+	root, err := b.build(def, ui, def.Type)
+	if err != nil {
+		return nil, err
+	}
+	ui.Root = root
+
+	return ui, nil
+}
+
+// build builds the component of the specified definition recursively.
+// path identifies the definition in error messages.
+func (b *Builder) build(def *Def, ui *UI, path string) (gwu.Comp, error) {
+	c := gwu.NewCompOfType(def.Type)
+	if c == nil {
+		return nil, fmt.Errorf("%s: unknown component type: %q", path, def.Type)
+	}
+
+	if def.Key != "" {
+		if _, found := ui.ByKey[def.Key]; found {
+			return nil, fmt.Errorf("%s: duplicate key: %q", path, def.Key)
+		}
+		ui.ByKey[def.Key] = c
+	}
+
+	if err := b.applyProps(c, def, path); err != nil {
+		return nil, err
+	}
+
+	if len(def.Children) > 0 {
+		p, ok := c.(gwu.Panel)
+		if !ok {
+			return nil, fmt.Errorf("%s: component type %q cannot have children", path, def.Type)
+		}
+		for i, childDef := range def.Children {
+			child, err := b.build(childDef, ui, fmt.Sprintf("%s.children[%d](%s)", path, i, childDef.Type))
+			if err != nil {
+				return nil, err
+			}
+			p.Add(child)
+		}
+	}
+
+	return c, nil
+}
+
+// applyProps applies the properties of the definition to the component.
+func (b *Builder) applyProps(c gwu.Comp, def *Def, path string) error {
+	propErr := func(prop string) error {
+		return fmt.Errorf("%s: property %q is not supported by component type %q", path, prop, def.Type)
+	}
+
+	if def.Name != "" {
+		c.SetName(def.Name)
+	}
+	if def.Text != nil {
+		ht, ok := c.(gwu.HasText)
+		if !ok {
+			return propErr("text")
+		}
+		ht.SetText(*def.Text)
+	}
+	if def.URL != nil {
+		hu, ok := c.(gwu.HasURL)
+		if !ok {
+			return propErr("url")
+		}
+		hu.SetURL(*def.URL)
+	}
+	if def.HTML != nil {
+		h, ok := c.(gwu.HTML)
+		if !ok {
+			return propErr("html")
+		}
+		h.SetHTML(*def.HTML)
+	}
+	if def.Values != nil {
+		lb, ok := c.(gwu.ListBox)
+		if !ok {
+			return propErr("values")
+		}
+		lb.SetValues(def.Values)
+	}
+	if def.Enabled != nil {
+		he, ok := c.(gwu.HasEnabled)
+		if !ok {
+			return propErr("enabled")
+		}
+		he.SetEnabled(*def.Enabled)
+	}
+	if def.Visible != nil {
+		c.SetVisible(*def.Visible)
+	}
+	if def.State != nil {
+		switch sb := c.(type) {
+		case gwu.StateButton:
+			sb.SetState(*def.State)
+		case gwu.SwitchButton:
+			sb.SetState(*def.State)
+		default:
+			return propErr("state")
+		}
+	}
+	if def.ReadOnly != nil {
+		tb, ok := c.(gwu.TextBox)
+		if !ok {
+			return propErr("readOnly")
+		}
+		tb.SetReadOnly(*def.ReadOnly)
+	}
+	if def.Multi != nil {
+		lb, ok := c.(gwu.ListBox)
+		if !ok {
+			return propErr("multi")
+		}
+		lb.SetMulti(*def.Multi)
+	}
+	if def.Rows != nil {
+		switch rc := c.(type) {
+		case gwu.TextBox:
+			rc.SetRows(*def.Rows)
+		case gwu.ListBox:
+			rc.SetRows(*def.Rows)
+		default:
+			return propErr("rows")
+		}
+	}
+	if def.Cols != nil {
+		tb, ok := c.(gwu.TextBox)
+		if !ok {
+			return propErr("cols")
+		}
+		tb.SetCols(*def.Cols)
+	}
+	if def.Placeholder != "" {
+		tb, ok := c.(gwu.TextBox)
+		if !ok {
+			return propErr("placeholder")
+		}
+		tb.SetPlaceholder(def.Placeholder)
+	}
+
+	if def.ToolTip != "" {
+		c.SetToolTip(def.ToolTip)
+	}
+	for _, class := range strings.Fields(def.Class) {
+		c.Style().AddClass(class)
+	}
+	for name, value := range def.Style {
+		c.Style().Set(name, value)
+	}
+	for name, value := range def.Attrs {
+		c.SetAttr(name, value)
+	}
+
+	for etypeName, handlerName := range def.Handlers {
+		etype, ok := etypeNames[etypeName]
+		if !ok {
+			return fmt.Errorf("%s: unknown event type: %q", path, etypeName)
+		}
+		hf := b.handlers[handlerName]
+		if hf == nil {
+			return fmt.Errorf("%s: unknown handler: %q", path, handlerName)
+		}
+		c.AddEHandlerFunc(hf, etype)
+	}
+
+	return nil
+}
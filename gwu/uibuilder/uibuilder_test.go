@@ -0,0 +1,101 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package uibuilder
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/icza/gowut/gwu"
+)
+
+func TestBuild(t *testing.T) {
+	b := New()
+	b.AddHandler("save", func(e gwu.Event) {})
+
+	ui, err := b.Build([]byte(`{
+		"type": "window", "name": "main", "text": "Dashboard",
+		"children": [
+			{"type": "label", "text": "Name:", "style": {"font-weight": "bold"}},
+			{"type": "textbox", "key": "nameBox", "cols": 20},
+			{"type": "button", "key": "saveBtn", "text": "Save", "handlers": {"click": "save"}}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	win, ok := ui.Root.(gwu.Window)
+	if !ok {
+		t.Fatalf("Expected Window root, got: %T", ui.Root)
+	}
+	if win.Name() != "main" || win.Text() != "Dashboard" || win.CompsCount() != 3 {
+		t.Errorf("Unexpected window: name=%q, text=%q, comps=%d", win.Name(), win.Text(), win.CompsCount())
+	}
+	if tb, ok := ui.ByKey["nameBox"].(gwu.TextBox); !ok || tb.Cols() != 20 {
+		t.Errorf("Unexpected nameBox: %v", ui.ByKey["nameBox"])
+	}
+	if btn := ui.ByKey["saveBtn"]; btn == nil || btn.HandlersCount(gwu.ETypeClick) != 1 {
+		t.Errorf("Expected click handler on saveBtn")
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	cases := []string{
+		`{"type": "nosuchtype"}`,
+		`{"type": "label", "url": "x"}`,
+		`{"type": "label", "children": [{"type": "label"}]}`,
+		`{"type": "button", "handlers": {"click": "nosuchhandler"}}`,
+		`{"type": "button", "handlers": {"nosuchevent": "h"}}`,
+		`{"type": "panel", "children": [{"type": "label", "key": "k"}, {"type": "label", "key": "k"}]}`,
+		`{invalid json`,
+	}
+
+	b := New()
+	b.AddHandler("h", func(e gwu.Event) {})
+	for _, c := range cases {
+		if _, err := b.Build([]byte(c)); err == nil {
+			t.Errorf("Expected error for: %s", c)
+		}
+	}
+}
+
+type customComp struct {
+	gwu.HTML
+}
+
+func (c *customComp) Render(w gwu.Writer) {
+	w.Writes("<span")
+	gwu.RenderAttrs(w, c)
+	w.Writes(">custom</span>")
+}
+
+func TestCustomCompType(t *testing.T) {
+	gwu.RegisterCompType("custom", func() gwu.Comp { return &customComp{gwu.NewHTML("")} })
+	defer gwu.RegisterCompType("custom", nil)
+
+	ui, err := New().Build([]byte(`{"type": "panel", "children": [{"type": "custom", "key": "c", "toolTip": "tip"}]}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	ui.ByKey["c"].Render(gwu.NewWriter(buf))
+	if s := buf.String(); !strings.Contains(s, `id="`+ui.ByKey["c"].ID().String()+`"`) || !strings.Contains(s, `title="tip"`) {
+		t.Errorf("Unexpected render output: %s", s)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// ValueComp component interface and implementation.
+
+package gwu
+
+import (
+	"html"
+	"net/http"
+)
+
+// ValueComp interface defines a component having a string value which is
+// synchronized from the browser to the server like the values of built-in
+// input components.
+//
+// ValueComp is the base of custom value-synced components implemented outside
+// of the gwu package (e.g. color wheels, maps): embed a ValueComp and override
+// its Render() method (see the Custom Components section of the package doc).
+//
+// The value is synchronized on ETypeChange events by default; more event types
+// can be added with AddSyncOnETypes(). Note that event handlers must be
+// registered to the sync event types, else the client does not send the events.
+//
+// Default style class: "gwu-ValueComp"
+type ValueComp interface {
+	// ValueComp is a component.
+	Comp
+
+	// Value returns the value of the component.
+	Value() string
+
+	// SetValue sets the value of the component.
+	SetValue(value string)
+
+	// OnValue sets a function to be called when a new value arrives
+	// from the browser, before the event handlers are called.
+	// Pass nil to remove a previously set function.
+	OnValue(f func(value string))
+}
+
+// ValueComp implementation.
+type valueCompImpl struct {
+	compImpl // Component implementation
+
+	value   string             // Value of the component
+	onValue func(value string) // Function to be called when a new value arrives
+}
+
+// NewValueComp creates a new ValueComp.
+//
+// valueProviderJS is a JavaScript expression which provides the value
+// of the component in the browser. It is evaluated in the context of the
+// event handler of the wrapper HTML tag, so "this" refers to the HTML element,
+// e.g. "this.value" or "this.getAttribute('data-color')".
+// The value is URI-encoded automatically.
+//
+// The default Render() renders the HTML-escaped value in a span.
+func NewValueComp(valueProviderJS string) ValueComp {
+	c := &valueCompImpl{compImpl: newCompImpl([]byte("encodeURIComponent(" + html.EscapeString(valueProviderJS) + ")"))}
+	c.AddSyncOnETypes(ETypeChange)
+	c.Style().AddClass("gwu-ValueComp")
+	return c
+}
+
+func (c *valueCompImpl) Value() string {
+	return c.value
+}
+
+func (c *valueCompImpl) SetValue(value string) {
+	c.value = value
+}
+
+func (c *valueCompImpl) OnValue(f func(value string)) {
+	c.onValue = f
+}
+
+func (c *valueCompImpl) preprocessEvent(event Event, r *http.Request) {
+	// Empty string might be a valid value, so check if the component value param is present:
+	r.FormValue(paramCompValue) // Make sure Form is parsed
+	values, present := r.Form[paramCompValue]
+	if !present || len(values) == 0 {
+		return
+	}
+
+	c.value = values[0]
+	if c.onValue != nil {
+		c.onValue(c.value)
+	}
+}
+
+func (c *valueCompImpl) Render(w Writer) {
+	w.Write(strSpanOp)
+	c.renderAttrsAndStyle(w)
+	c.renderEHandlers(w)
+	w.Write(strGT)
+
+	w.Writees(c.value)
+
+	w.Write(strSpanCl)
+}
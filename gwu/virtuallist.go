@@ -0,0 +1,234 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// VirtualList component interface and implementation.
+
+package gwu
+
+import (
+	"net/http"
+)
+
+// VirtualListRowFunc is a function which creates the component of the row at index idx
+// of a VirtualList.
+type VirtualListRowFunc func(idx int) Comp
+
+// VirtualList interface defines a scrollable list which only renders the visible
+// rows (and some more around them) of a potentially large list, so lists with tens
+// of thousands of rows don't have to be rendered as a whole (as a Table would).
+//
+// Rows have a fixed height. Row components are created on demand by the row function
+// when they come into view, and are dropped when they are far out of view.
+// Row components may have event handlers.
+//
+// The rendered rows are updated when the user scrolls the list
+// (scroll events are sent after the scrolling stops).
+//
+// Default style classes: "gwu-VirtualList", "gwu-VirtualList-Row"
+type VirtualList interface {
+	// VirtualList is a Container (of the rendered rows).
+	Container
+
+	// Count returns the number of rows.
+	Count() int
+
+	// SetCount sets the number of rows, and drops the rendered rows
+	// so they are created again by the row function (see Refresh()).
+	SetCount(count int)
+
+	// RowHeight returns the height of the rows in pixels.
+	RowHeight() int
+
+	// SetRowHeight sets the height of the rows in pixels. Default is 24.
+	SetRowHeight(height int)
+
+	// VisibleRows returns the number of visible rows (that determines the height of the list).
+	VisibleRows() int
+
+	// SetVisibleRows sets the number of visible rows (that determines the height of the list).
+	// Default is 10.
+	SetVisibleRows(rows int)
+
+	// RenderedRange returns the range of the rendered rows: first is the index of
+	// the first rendered row, end is the index after the last rendered row.
+	RenderedRange() (first, end int)
+
+	// Refresh drops the rendered rows so they are created again by the row function,
+	// e.g. after the data of the rows has changed.
+	// The virtual list has to be marked dirty for the change to take effect.
+	Refresh()
+}
+
+// VirtualList implementation.
+type virtualListImpl struct {
+	panelImpl // Panel implementation (holding the rendered rows)
+
+	count       int                // Number of rows
+	rowHeight   int                // Height of the rows in pixels
+	visibleRows int                // Number of visible rows
+	rowFunc     VirtualListRowFunc // Function creating the row components
+	first       int                // Index of the first rendered row
+}
+
+// NewVirtualList creates a new VirtualList with the specified number of rows
+// and the row function creating the row components.
+func NewVirtualList(count int, rowFunc VirtualListRowFunc) VirtualList {
+	c := &virtualListImpl{panelImpl: newPanelImpl(), count: count, rowHeight: 24, visibleRows: 10, rowFunc: rowFunc}
+	c.outer = c
+	c.Style().AddClass("gwu-VirtualList").Set("overflow-y", "auto").SetHeightPx(c.visibleRows * c.rowHeight)
+	// Keep the scroll position when re-rendered
+	c.SetPreserveState(true)
+	c.SetAttr(attrVirtualList, "1")
+	c.setRange(0)
+	return c
+}
+
+func (c *virtualListImpl) Count() int {
+	return c.count
+}
+
+func (c *virtualListImpl) SetCount(count int) {
+	c.count = count
+	c.Refresh()
+}
+
+func (c *virtualListImpl) RowHeight() int {
+	return c.rowHeight
+}
+
+func (c *virtualListImpl) SetRowHeight(height int) {
+	if height < 1 {
+		height = 1
+	}
+	c.rowHeight = height
+	c.Style().SetHeightPx(c.visibleRows * c.rowHeight)
+}
+
+func (c *virtualListImpl) VisibleRows() int {
+	return c.visibleRows
+}
+
+func (c *virtualListImpl) SetVisibleRows(rows int) {
+	if rows < 1 {
+		rows = 1
+	}
+	c.visibleRows = rows
+	c.Style().SetHeightPx(c.visibleRows * c.rowHeight)
+	c.Refresh()
+}
+
+func (c *virtualListImpl) RenderedRange() (first, end int) {
+	return c.first, c.first + len(c.comps)
+}
+
+func (c *virtualListImpl) Refresh() {
+	c.panelImpl.Clear()
+	c.setRange(c.first)
+}
+
+// setRange sets the rendered rows to start at the specified first row.
+// Rendered rows are kept if they remain in range, missing rows are created.
+// The number of rendered rows is 3 times the visible rows (if there are that many).
+func (c *virtualListImpl) setRange(first int) {
+	end := first + 3*c.visibleRows
+	if end > c.count {
+		end = c.count
+		first = end - 3*c.visibleRows
+	}
+	if first < 0 {
+		first = 0
+	}
+
+	// Rows to keep mapped from row index
+	kept := make(map[int]Comp)
+	for i, row := range append([]Comp(nil), c.comps...) {
+		if idx := c.first + i; idx >= first && idx < end {
+			kept[idx] = row
+		} else {
+			c.panelImpl.Remove(row)
+		}
+	}
+
+	rows := make([]Comp, 0, end-first)
+	for idx := first; idx < end; idx++ {
+		row := kept[idx]
+		if row == nil {
+			if c.rowFunc == nil {
+				break
+			}
+			row = c.rowFunc(idx)
+			c.panelImpl.Add(row)
+		}
+		rows = append(rows, row)
+	}
+	c.comps = rows
+	c.first = first
+}
+
+func (c *virtualListImpl) preprocessEvent(event Event, r *http.Request) {
+	if event.Type() != ETypeScroll {
+		return
+	}
+	_, y, _, _ := event.Scroll()
+	if y < 0 {
+		return
+	}
+
+	// Only update the rendered rows if the visible rows are not all rendered
+	top := y / c.rowHeight
+	if first, end := c.RenderedRange(); top >= first && top+c.visibleRows <= end {
+		return
+	}
+	// Center the visible rows in the rendered range:
+	c.setRange(top - c.visibleRows)
+	event.MarkDirty(c)
+}
+
+var (
+	strDivOp      = []byte("<div")                                            // "<div"
+	strDivCl      = []byte("</div>")                                          // "</div>"
+	strVlSpacerOp = []byte(`<div style="height:`)                             // `<div style="height:`
+	strVlRowOp    = []byte(`<div class="gwu-VirtualList-Row" style="height:`) // `<div class="gwu-VirtualList-Row" style="height:`
+	strVlPxCl     = []byte(`px">`)                                            // `px">`
+)
+
+func (c *virtualListImpl) Render(w Writer) {
+	w.Write(strDivOp)
+	c.renderAttrsAndStyle(w)
+	c.renderEHandlers(w)
+	w.Write(strGT)
+
+	first, end := c.RenderedRange()
+
+	w.Write(strVlSpacerOp)
+	w.Writev(first * c.rowHeight)
+	w.Write(strVlPxCl)
+	w.Write(strDivCl)
+
+	for _, row := range c.comps {
+		w.Write(strVlRowOp)
+		w.Writev(c.rowHeight)
+		w.Write(strVlPxCl)
+		row.Render(w)
+		w.Write(strDivCl)
+	}
+
+	w.Write(strVlSpacerOp)
+	w.Writev((c.count - end) * c.rowHeight)
+	w.Write(strVlPxCl)
+	w.Write(strDivCl)
+
+	w.Write(strDivCl)
+}
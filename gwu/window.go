@@ -17,6 +17,17 @@
 
 package gwu
 
+import (
+	"bytes"
+	"compress/gzip"
+	"hash/fnv"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
 // The Window interface is the top of the component hierarchy.
 // A Window defines the content seen in the browser window.
 // Multiple windows can be created, but only one is visible
@@ -39,6 +50,31 @@ type Window interface {
 	// SetName sets the name of the window.
 	SetName(name string)
 
+	// Listed tells if the window is listed.
+	// Windows are listed by default.
+	Listed() bool
+
+	// SetListed sets whether the window is listed.
+	// Unlisted windows (e.g. popup targets, internal views) do not appear
+	// in the window list page (the app root) and in Session.SortedWins(),
+	// but they are still accessible by their URL (and by Session.WinByName()).
+	SetListed(listed bool)
+
+	// SetHeaders sets extra HTTP response headers that are added to the responses
+	// served for the window (including the responses of AJAX requests, e.g. events).
+	// Window headers are merged with the headers of the server (see Server.SetHeaders()):
+	// values of a window header replace the values of the server header having the same name.
+	// Supplied values are copied, so changes to the passed map afterwards have no effect.
+	//
+	// For example to disable caching of a window with sensitive content:
+	//     win.SetHeaders(map[string][]string{
+	//         "Cache-Control": {"no-store"},
+	//     })
+	SetHeaders(headers map[string][]string)
+
+	// Headers returns the extra HTTP response headers of the window.
+	Headers() map[string][]string
+
 	// AddHeadHTML adds an HTML text which will be included
 	// in the HTML <head> section.
 	AddHeadHTML(html string)
@@ -47,9 +83,42 @@ type Window interface {
 	// that was previously added with AddHeadHtml().
 	RemoveHeadHTML(html string)
 
+	// Schedule schedules f to be called at the server side after the specified delay,
+	// with an event whose source is the window (so components can be marked dirty).
+	// The event type is ETypeStateChange, but the event is not dispatched to the
+	// event handlers of the window.
+	//
+	// The delay is measured at the client side (using the Timer machinery), and
+	// the scheduled action is delivered to the browser with the next response
+	// of the window (page load, window re-render or event response), so it is
+	// best called from event handlers of the window. f is called only if the
+	// window is open in the browser when the delay expires.
+	// If the window is reloaded, remaining delays are preserved.
+	//
+	// Schedule must be called while the session is locked (e.g. from event handlers).
+	Schedule(delay time.Duration, f func(e Event))
+
 	// SetFocusedCompID sets the ID of the currently focused component.
 	SetFocusedCompID(id ID)
 
+	// FocusedCompID returns the ID of the currently focused component.
+	FocusedCompID() ID
+
+	// ByName finds a component (recursively) by its name (see Comp.SetName()) and returns it.
+	// nil is returned if no component is found with the specified name.
+	// If multiple components have the same name, the first one
+	// found by a depth-first traversal is returned.
+	ByName(name string) Comp
+
+	// ByClass returns the components (recursively) having the specified style class,
+	// in depth-first order. For example ByClass("gwu-TextBox") returns all text boxes.
+	ByClass(class string) []Comp
+
+	// FindAll returns the components (recursively) for which the specified function
+	// returns true, in depth-first order. This can be used for cross-cutting operations,
+	// e.g. to disable all input components while saving.
+	FindAll(f func(c Comp) bool) []Comp
+
 	// Theme returns the CSS theme of the window.
 	// If an empty string is returned, the server's theme will be used.
 	Theme() string
@@ -60,6 +129,93 @@ type Window interface {
 
 	// RenderWin renders the window as a complete HTML document.
 	RenderWin(w Writer, s Server)
+
+	// PageCache tells if whole page caching is enabled for the window.
+	PageCache() bool
+
+	// SetPageCache sets whether whole page caching is enabled for the window.
+	// This is useful for read-mostly public windows (e.g. dashboards) visited
+	// by many users: the rendered HTML document is kept and served (gzipped
+	// if the browser accepts it, and with an ETag so browsers can revalidate
+	// their copy) until a component of the window is marked dirty by an event.
+	//
+	// Changes of the component tree made outside of event handlers,
+	// or without marking components dirty, are not detected;
+	// call InvalidatePageCache() after such changes.
+	// Pages are not cached while the window has scheduled tasks.
+	SetPageCache(enabled bool)
+
+	// InvalidatePageCache discards the cached page of the window
+	// (see SetPageCache()), so it will be rendered again when requested.
+	InvalidatePageCache()
+
+	// RenderReport renders a simplified, interaction-free version of the window
+	// as a complete HTML document, suitable for printing or emailing snapshots
+	// (e.g. of a dashboard).
+	// Scripts, event handlers and the popup are not rendered, the CSS theme
+	// of the window is embedded, collapsed expanders and panels are rendered expanded,
+	// and the tabs of tab panels are rendered stacked, each followed by its content.
+	// Visibility and enabled predicates are evaluated with the session
+	// of the writer (if it was created by the server), nil otherwise.
+	RenderReport(w Writer)
+
+	// RenderToString renders the window as a complete HTML document
+	// and returns it, e.g. for golden-file tests or server-side prerendering.
+	// Attributes, styles and event handlers are rendered in a deterministic order.
+	//
+	// If stableIDs is true, component IDs are mapped to 1, 2, 3... in the order
+	// they appear in the document, so the output does not depend on how many
+	// components were created before. Documents rendered with stable IDs can't
+	// be used to send events to the server.
+	RenderToString(s ServerConfig, stableIDs bool) string
+
+	// Clone returns a deep copy of the window and its component tree.
+	// The cloned components get new ids, and their properties, styles,
+	// HTML attributes and event handlers are copied.
+	//
+	// Event handlers are copied by reference, so handlers referencing
+	// components of the original window (e.g. by closures) will still refer
+	// to the original components. Handlers to be shared by clones should
+	// access components via Event.Src() (and its parents) instead.
+	//
+	// Cloning is supported for the built-in components only; nil is returned
+	// if the window contains a custom component (implemented outside of the gwu package).
+	Clone() Window
+
+	// Dispose clears the component tree of the window: it removes all
+	// components (recursively), and clears their parents, event handlers and
+	// cell formatters, so the components can be garbage collected promptly even if
+	// references to some of them are kept (e.g. by closures of event handlers).
+	// The window and its components must not be used after Dispose().
+	//
+	// Windows are disposed automatically when they are removed from
+	// a session, unless this is disabled by SetDisposeOnRemove(false).
+	Dispose()
+
+	// DisposeOnRemove tells if the window is disposed when it is removed from a session.
+	DisposeOnRemove() bool
+
+	// SetDisposeOnRemove sets whether the window is disposed (see Dispose())
+	// when it is removed from a session (see Session.RemoveWin()).
+	// Disable it if the window is to be added again after removal.
+	// The default is true.
+	SetDisposeOnRemove(dispose bool)
+
+	// FallbackEHandler returns the fallback event handler of the window,
+	// set by SetFallbackEHandler().
+	FallbackEHandler() EventHandler
+
+	// SetFallbackEHandler sets an event handler which is called for events
+	// targeting components of the window which have no event handlers for
+	// the event type (other than the ones registered internally by components),
+	// e.g. events sent only to synchronize component values (see Comp.AddSyncOnETypes()).
+	// The source of the event is the targeted component.
+	//
+	// This is useful for analytics (e.g. tracking clicks), debugging, or implementing
+	// global behaviors like marking a form dirty on any change.
+	// Note that browsers only send events which are handled or synchronized
+	// by components. Pass nil to remove the fallback handler.
+	SetFallbackEHandler(h EventHandler)
 }
 
 // WinSlice is a slice of windows which implements sort.Interface so it
@@ -83,24 +239,280 @@ type windowImpl struct {
 	panelImpl   // Panel implementation
 	hasTextImpl // Has text implementation
 
-	name          string   // Window name
-	heads         []string // Additional head HTML texts
-	focusedCompID ID       // ID of the last reported focused component
-	theme         string   // CSS theme of the window
+	name          string              // Window name
+	heads         []string            // Additional head HTML texts
+	focusedCompID ID                  // ID of the last reported focused component
+	theme         string              // CSS theme of the window
+	unlisted      bool                // Tells if the window is unlisted
+	noDispose     bool                // Tells if the window is not to be disposed when removed from a session
+	fallback      EventHandler        // Fallback event handler of the window
+	headers       map[string][]string // Extra headers that will be added to the responses of the window
+	nonce         string              // Nonce of the window instance, used to detect events of stale pages
+	popup         *popupImpl          // Popup layer of the window
+	ids           idGen               // Generator of the component IDs of the window
+
+	tasks   map[int]*schedTask // Scheduled tasks mapped from task ID. Lazily initialized.
+	taskSeq int                // Task ID sequence
+
+	broadcast map[ID]Comp // Components marked dirty by events of other windows (see Event.BroadcastDirty()). Lazily initialized.
+
+	tabID   string    // ID of the browser tab owning the window (see Server.SetMultiTabPolicy())
+	tabSeen time.Time // Time of the last event from the owner tab
+
+	pageCache bool        // Tells if whole page caching is enabled
+	cacheMux  sync.Mutex  // Mutex to protect the cached page
+	page      *cachedPage // Cached page, nil if not yet rendered or invalidated
+}
+
+// cachedPage is a cached, rendered HTML document of a window.
+type cachedPage struct {
+	html []byte // The rendered HTML document
+	gzip []byte // Gzipped HTML document, lazily initialized
+	etag string // ETag of the HTML document
+}
+
+// schedTask is a scheduled server-side task of a window.
+type schedTask struct {
+	due  time.Time     // Time when the task is due
+	f    func(e Event) // The task function
+	sent bool          // Tells if the task has been sent in an event response
 }
 
 // NewWindow creates a new window.
 // The default layout strategy is LayoutVertical.
 func NewWindow(name, text string) Window {
-	c := &windowImpl{panelImpl: newPanelImpl(), hasTextImpl: newHasTextImpl(text), name: name}
+	c := &windowImpl{panelImpl: newPanelImpl(), hasTextImpl: newHasTextImpl(text), name: name, nonce: genID(), popup: newPopupImpl()}
+	c.outer = c
+	allocIDs(c, c)
+	setParent(c.popup, c)
 	c.Style().AddClass("gwu-Window")
 	return c
 }
 
+func (w *windowImpl) Clone() Window {
+	if c := newCloner(true).cloneTree(w); c != nil {
+		return c.(Window)
+	}
+	return nil
+}
+
+func (w *windowImpl) SetHeaders(headers map[string][]string) {
+	w.headers = make(map[string][]string, len(headers))
+	for k, v := range headers {
+		// Also copy value which is a slice
+		w.headers[k] = append(make([]string, 0, len(v)), v...)
+	}
+}
+
+func (w *windowImpl) Headers() map[string][]string {
+	headers := make(map[string][]string, len(w.headers))
+	for k, v := range w.headers {
+		// Also copy value which is a slice
+		headers[k] = append(make([]string, 0, len(v)), v...)
+	}
+	return headers
+}
+
+func (w *windowImpl) Schedule(delay time.Duration, f func(e Event)) {
+	if w.tasks == nil {
+		w.tasks = make(map[int]*schedTask)
+	}
+	w.taskSeq++
+	w.tasks[w.taskSeq] = &schedTask{due: time.Now().Add(delay), f: f}
+	w.InvalidatePageCache()
+}
+
+func (w *windowImpl) PageCache() bool {
+	return w.pageCache
+}
+
+func (w *windowImpl) SetPageCache(enabled bool) {
+	w.pageCache = enabled
+	if !enabled {
+		w.InvalidatePageCache()
+	}
+}
+
+func (w *windowImpl) InvalidatePageCache() {
+	w.cacheMux.Lock()
+	w.page = nil
+	w.cacheMux.Unlock()
+}
+
+// cachedPage returns the cached page of the window, rendering it if needed.
+// If gzipped is true, the gzipped HTML document is also prepared.
+// nil is returned if the page can't be cached currently.
+func (w *windowImpl) cachedPage(s *serverImpl, gzipped bool) *cachedPage {
+	if len(w.tasks) > 0 {
+		return nil
+	}
+
+	w.cacheMux.Lock()
+	defer w.cacheMux.Unlock()
+
+	if w.page == nil {
+		buf := &bytes.Buffer{}
+		w.RenderWin(s.newWriter(buf, &s.sessionImpl), s)
+		h := fnv.New64a()
+		h.Write(buf.Bytes())
+		w.page = &cachedPage{html: buf.Bytes(), etag: `"` + strconv.FormatUint(h.Sum64(), 36) + `"`}
+	}
+
+	if gzipped && w.page.gzip == nil {
+		buf := &bytes.Buffer{}
+		gw := gzip.NewWriter(buf)
+		gw.Write(w.page.html)
+		gw.Close()
+		w.page.gzip = buf.Bytes()
+	}
+	return w.page
+}
+
+// delayMs returns the remaining delay of the task in milliseconds.
+func (t *schedTask) delayMs() int {
+	if d := time.Until(t.due); d > 0 {
+		return int(d / time.Millisecond)
+	}
+	return 0
+}
+
+// takeTask removes and returns the function of the task identified by
+// the specified task ID string. nil is returned if no such task exists.
+func (w *windowImpl) takeTask(taskID string) func(e Event) {
+	id, err := strconv.Atoi(taskID)
+	if err != nil {
+		return nil
+	}
+	t := w.tasks[id]
+	if t == nil {
+		return nil
+	}
+	delete(w.tasks, id)
+	return t.f
+}
+
+// broadcastValue is the component value of the state change events
+// sent by windows refreshed by a broadcast.
+const broadcastValue = "b"
+
+// addBroadcast registers a component to be re-rendered when the window is
+// refreshed by a broadcast of another window.
+func (w *windowImpl) addBroadcast(c Comp) {
+	if w.broadcast == nil {
+		w.broadcast = make(map[ID]Comp)
+	}
+	w.broadcast[c.ID()] = c
+}
+
+// takeBroadcast marks the components registered by addBroadcast() dirty
+// in the specified event, and clears them.
+func (w *windowImpl) takeBroadcast(e Event) {
+	for _, c := range w.broadcast {
+		e.MarkDirty(c)
+	}
+	w.broadcast = nil
+}
+
+// windowOf returns the window the specified component is added to
+// (the component itself if it is a window), or nil if it's not added to a window.
+func windowOf(c Comp) *windowImpl {
+	for ; c != nil; c = c.Parent() {
+		if w, ok := c.(*windowImpl); ok {
+			return w
+		}
+	}
+	return nil
+}
+
+var (
+	strJsScheduleOp = []byte("schedule(") // "schedule("
+)
+
+// renderSchedule renders the Javascript code which schedules a task:
+//
+//	schedule(winId,taskId,delayMs);
+func (w *windowImpl) renderSchedule(wr Writer, taskID int, t *schedTask) {
+	wr.Write(strJsScheduleOp)
+	wr.Writevs(w.id, strComma, taskID, strComma, t.delayMs())
+	wr.Write(strJsFuncCl)
+}
+
+func (w *windowImpl) Listed() bool {
+	return !w.unlisted
+}
+
+func (w *windowImpl) SetListed(listed bool) {
+	w.unlisted = !listed
+}
+
+func (w *windowImpl) Dispose() {
+	var comps []Comp
+	Walk(w, func(c Comp) bool {
+		comps = append(comps, c)
+		return true
+	})
+	if w.popup.content == nil {
+		comps = append(comps, w.popup) // Only visited if a popup is shown
+	}
+	for _, c := range comps {
+		c.dispose()
+	}
+}
+
+func (w *windowImpl) dispose() {
+	w.panelImpl.dispose()
+	w.fallback = nil
+	w.tasks = nil
+	w.broadcast = nil
+	w.InvalidatePageCache()
+}
+
+func (w *windowImpl) DisposeOnRemove() bool {
+	return !w.noDispose
+}
+
+func (w *windowImpl) SetDisposeOnRemove(dispose bool) {
+	w.noDispose = !dispose
+}
+
+func (w *windowImpl) FallbackEHandler() EventHandler {
+	return w.fallback
+}
+
+func (w *windowImpl) SetFallbackEHandler(h EventHandler) {
+	w.fallback = h
+}
+
 func (w *windowImpl) Name() string {
 	return w.name
 }
 
+// Path returns the name of the window, the root of the paths of its components.
+func (w *windowImpl) Path() string {
+	return w.name
+}
+
+func (w *windowImpl) ByID(id ID) Comp {
+	if c := w.panelImpl.ByID(id); c != nil {
+		return c
+	}
+	return w.popup.ByID(id)
+}
+
+func (w *windowImpl) childComps() []Comp {
+	if w.popup.content == nil {
+		return w.comps // The popup layer is only visited if a popup is shown
+	}
+	return append(w.comps[:len(w.comps):len(w.comps)], w.popup)
+}
+
+func (w *windowImpl) childPath(id ID) string {
+	if id == w.popup.id {
+		return "popup"
+	}
+	return w.panelImpl.childPath(id)
+}
+
 func (w *windowImpl) SetName(name string) {
 	w.name = name
 }
@@ -124,6 +536,49 @@ func (w *windowImpl) SetFocusedCompID(id ID) {
 	w.focusedCompID = id
 }
 
+func (w *windowImpl) ByName(name string) Comp {
+	if name == "" {
+		return nil
+	}
+
+	var found Comp
+	for _, c := range w.comps {
+		Walk(c, func(c2 Comp) bool {
+			if c2.Name() == name {
+				found = c2
+			}
+			return found == nil
+		})
+		if found != nil {
+			break
+		}
+	}
+	return found
+}
+
+func (w *windowImpl) ByClass(class string) []Comp {
+	return w.FindAll(func(c Comp) bool {
+		return c.Style().HasClass(class)
+	})
+}
+
+func (w *windowImpl) FindAll(f func(c Comp) bool) []Comp {
+	var found []Comp
+	for _, c := range w.comps {
+		Walk(c, func(c2 Comp) bool {
+			if f(c2) {
+				found = append(found, c2)
+			}
+			return true
+		})
+	}
+	return found
+}
+
+func (w *windowImpl) FocusedCompID() ID {
+	return w.focusedCompID
+}
+
 func (w *windowImpl) Theme() string {
 	return w.theme
 }
@@ -138,9 +593,14 @@ func (w *windowImpl) Render(wr Writer) {
 	// will not be reflected.
 	// This also avoids the effect of registering the event sender functions multiple times.
 
+	if reportMode(wr) {
+		w.panelImpl.Render(wr)
+		return
+	}
+
 	// First render window event handlers as window functions.
 	found := false
-	for etype := range w.handlers {
+	for _, etype := range sortedETypes(w.handlers) {
 		if etype.Category() != ECatWindow {
 			continue
 		}
@@ -151,17 +611,73 @@ func (w *windowImpl) Render(wr Writer) {
 		}
 		// To render       : add<etypeFunc>(function(){se(null,etype,id);});
 		// Example (onload): addonload(function(){se(null,13,4327);});
-		wr.Writevs("add", etypeFuncs[etype], "(function(){se(null,", int(etype), ",", int(w.id), ");});")
+		wr.Writevs("add", etypeFuncs[etype], "(function(){se(null,", int(etype), ",", w.id, ");});")
 	}
 	if found {
 		wr.Write(strScriptCl)
 	}
 
+	// Scheduled tasks (duplicates are ignored at the client side)
+	if len(w.tasks) > 0 {
+		wr.Write(strScriptOp)
+		ids := make([]int, 0, len(w.tasks))
+		for id := range w.tasks {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			w.renderSchedule(wr, id, w.tasks[id])
+		}
+		wr.Write(strScriptCl)
+	}
+
 	// And now call panelImpl's Render()
 	w.panelImpl.Render(wr)
 }
 
+func (w *windowImpl) RenderToString(s ServerConfig, stableIDs bool) string {
+	buf := &bytes.Buffer{}
+	wi := writerImpl{Writer: buf, sw: buf, paths: s.RenderPaths(), scratch: new([scratchSize]byte)}
+	if stableIDs {
+		wi.ids = map[ID]ID{}
+	}
+	w.renderDoc(wi, s)
+	return buf.String()
+}
+
 func (w *windowImpl) RenderWin(wr Writer, s Server) {
+	w.renderDoc(wr, s)
+}
+
+func (w *windowImpl) RenderReport(wr Writer) {
+	wi, ok := wr.(writerImpl)
+	if !ok {
+		wi = NewWriter(wr).(writerImpl)
+	}
+	wi.report = true
+
+	theme := w.theme
+	if theme == "" {
+		theme = ThemeDefault
+	}
+	css := staticCSS[resNameStaticCSS(theme)]
+	if css == nil {
+		css = staticCSS[resNameStaticCSS(ThemeDefault)]
+	}
+
+	wi.Writes(`<html><head><meta http-equiv="content-type" content="text/html; charset=UTF-8"><title>`)
+	wi.Writees(w.text)
+	wi.Writes(`</title><style>`)
+	wi.Write(css)
+	wi.Writes("</style></head><body>")
+
+	w.Render(wi)
+
+	wi.Writes("</body></html>")
+}
+
+// renderDoc renders the window as a complete HTML document.
+func (w *windowImpl) renderDoc(wr Writer, s ServerConfig) {
 	// We could optimize this (store byte slices of static strings)
 	// but windows are rendered "so rarely"...
 	wr.Writes(`<html><head><meta http-equiv="content-type" content="text/html; charset=UTF-8"><title>`)
@@ -179,18 +695,34 @@ func (w *windowImpl) RenderWin(wr Writer, s Server) {
 	wr.Writes("</head><body>")
 
 	w.Render(wr)
+	w.popup.Render(wr)
 
 	wr.Writes("</body></html>")
 }
 
 // renderDynJs renders the dynamic JavaScript codes of Gowut.
-func (w *windowImpl) renderDynJs(wr Writer, s Server) {
+func (w *windowImpl) renderDynJs(wr Writer, s ServerConfig) {
 	wr.Write(strScriptOp)
 	wr.Writess("var _pathApp='", s.AppPath(), "';")
 	wr.Writess("var _pathSessCheck=_pathApp+'", pathSessCheck, "';")
+	wr.Writess("var _winName='", w.name, "';")
 	wr.Writess("var _pathWin='", s.AppPath(), w.name, "/';")
 	wr.Writess("var _pathEvent=_pathWin+'", pathEvent, "';")
 	wr.Writess("var _pathRenderComp=_pathWin+'", pathRenderComp, "';")
-	wr.Writess("var _focCompId='", w.focusedCompID.String(), "';")
+	wr.Writess("var _pathDownload=_pathWin+'", pathDownload, "';")
+	wr.Writes("var _focCompId='")
+	if w.focusedCompID != 0 {
+		wr.Writev(w.focusedCompID)
+	} else {
+		wr.Writev(0)
+	}
+	wr.Writes("';")
+	if wi, ok := wr.(writerImpl); ok && wi.ids != nil {
+		// Stable output: the nonce is random, and events can't be sent anyway
+		wr.Writes("var _winNonce='';")
+	} else {
+		wr.Writess("var _winNonce='", w.nonce, "';")
+	}
+	wr.Writess("var _offlineText=decodeURIComponent('", url.PathEscape(s.OfflineText()), "');")
 	wr.Write(strScriptCl)
 }
@@ -0,0 +1,249 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRenderToStringStable tests that rendering identical windows
+// with stable IDs produces identical documents.
+func TestRenderToStringStable(t *testing.T) {
+	build := func() Window {
+		win := NewWindow("main", "Main")
+		win.Style().SetWidth("100%").SetColor("red").SetPadding("2px")
+		b := NewButton("OK")
+		b.SetAttr("title", "Tip")
+		b.SetData("rec", "1")
+		b.AddEHandlerFunc(func(e Event) {}, ETypeClick, ETypeMouseOver, ETypeKeyUp)
+		win.Add(b)
+		win.Add(NewCheckBox("Check"))
+		win.Add(NewSwitchButton())
+		return win
+	}
+
+	s := NewServer("app", "")
+	doc1 := build().RenderToString(s, true)
+	NewLabel("") // Allocate a component ID in between
+	doc2 := build().RenderToString(s, true)
+	if doc1 != doc2 {
+		t.Errorf("Documents differ:\n%s\n%s", doc1, doc2)
+	}
+	if !strings.Contains(doc1, `id="1"`) {
+		t.Errorf("Window ID is not mapped: %s", doc1)
+	}
+}
+
+// TestRenderToStringStableFilterBox tests that the target of a filter box
+// is referred to by its stable ID.
+func TestRenderToStringStableFilterBox(t *testing.T) {
+	build := func() Window {
+		win := NewWindow("main", "Main")
+		lb := NewListBox([]string{"one", "two"})
+		win.Add(NewFilterBox(lb))
+		win.Add(lb)
+		return win
+	}
+
+	s := NewServer("app", "")
+	doc1 := build().RenderToString(s, true)
+	NewLabel("") // Allocate a component ID in between
+	doc2 := build().RenderToString(s, true)
+	if doc1 != doc2 {
+		t.Errorf("Documents differ:\n%s\n%s", doc1, doc2)
+	}
+	if !strings.Contains(doc1, `data-gwu-flt="3"`) || !strings.Contains(doc1, `id="3" class="gwu-ListBox"`) {
+		t.Errorf("Filter target is not mapped: %s", doc1)
+	}
+}
+
+// TestCompPath tests component paths.
+func TestCompPath(t *testing.T) {
+	win := NewWindow("main", "Main")
+	p := NewHorizontalPanel()
+	b := NewButton("OK")
+	p.Add(NewLabel(""))
+	p.Add(b)
+	win.Add(p)
+	tab := NewTable()
+	l := NewLabel("")
+	tab.Add(l, 1, 2)
+	win.Add(tab)
+
+	cases := []struct {
+		c    Comp
+		path string
+	}{
+		{win, "main"},
+		{p, "main/panel[0]"},
+		{b, "main/panel[0]/button[1]"},
+		{l, "main/table[1]/label[1,2]"},
+		{NewLabel(""), ""},
+	}
+	for _, c := range cases {
+		if path := c.c.Path(); path != c.path {
+			t.Errorf("Got: %q, want: %q", path, c.path)
+		}
+	}
+
+	if p.Parent().(Window) != win {
+		t.Error("Parent of window child is not the window")
+	}
+
+	s := NewServer("app", "")
+	s.SetRenderPaths(true)
+	if doc := win.RenderToString(s, true); !strings.Contains(doc, `data-gwu-path="main/panel[0]/button[1]"`) {
+		t.Errorf("Path attribute not rendered: %s", doc)
+	}
+}
+
+// TestByName tests looking up components by name.
+func TestByName(t *testing.T) {
+	win := NewWindow("main", "Main")
+	p := NewPanel()
+	b := NewButton("OK")
+	b.SetName("ok")
+	p.Add(b)
+	tp := NewTabPanel()
+	l := NewLabel("")
+	l.SetName("tab")
+	tp.Add(l, NewLabel(""))
+	win.Add(p)
+	win.Add(tp)
+
+	if c := win.ByName("ok"); c == nil || !c.Equals(b) {
+		t.Errorf("Got: %v, want: %v", c, b)
+	}
+	if c := win.ByName("tab"); c == nil || !c.Equals(l) {
+		t.Errorf("Got: %v, want: %v", c, l)
+	}
+	if c := win.ByName("none"); c != nil {
+		t.Errorf("Got: %v, want: nil", c)
+	}
+	if html := win.RenderToString(NewServer("app", ""), true); !strings.Contains(html, `data-gwu-name="ok"`) {
+		t.Errorf("Name attribute not rendered: %s", html)
+	}
+}
+
+// TestFindAll tests Window.ByClass() and Window.FindAll().
+func TestFindAll(t *testing.T) {
+	win := NewWindow("main", "Main")
+	p := NewPanel()
+	tb1, tb2 := NewTextBox(""), NewTextBox("")
+	p.Add(tb1)
+	p.Add(NewLabel(""))
+	win.Add(p)
+	win.Add(tb2)
+
+	if comps := win.ByClass("gwu-TextBox"); len(comps) != 2 || comps[0] != tb1 || comps[1] != tb2 {
+		t.Errorf("Unexpected text boxes: %v", comps)
+	}
+	comps := win.FindAll(func(c Comp) bool {
+		_, isLabel := c.(Label)
+		return isLabel
+	})
+	if len(comps) != 1 {
+		t.Errorf("Got: %d labels, want: 1", len(comps))
+	}
+}
+
+// TestDispose tests that windows removed from sessions are disposed.
+func TestDispose(t *testing.T) {
+	newWin := func() (Window, Panel, Button) {
+		win := NewWindow("w", "")
+		p := NewPanel()
+		b := NewButton("b")
+		b.AddEHandlerFunc(func(e Event) {}, ETypeClick)
+		p.Add(b)
+		win.Add(p)
+		return win, p, b
+	}
+
+	sess := newSessionImpl(true)
+	win, p, b := newWin()
+	sess.AddWin(win)
+	if !sess.RemoveWin(win) {
+		t.Fatalf("Window not removed")
+	}
+	if b.Parent() != nil || p.Parent() != nil || p.CompsCount() != 0 || b.HandlersCount(ETypeClick) != 0 {
+		t.Errorf("Window not disposed")
+	}
+
+	win, p, b = newWin()
+	win.SetDisposeOnRemove(false)
+	sess.AddWin(win)
+	sess.RemoveWin(win)
+	if b.Parent() != p || p.CompsCount() != 1 || b.HandlersCount(ETypeClick) != 1 {
+		t.Errorf("Window disposed")
+	}
+}
+
+// TestRenderReport tests Window.RenderReport().
+func TestRenderReport(t *testing.T) {
+	win := NewWindow("dash", "Dashboard")
+	b := NewButton("Refresh")
+	b.AddEHandlerFunc(func(e Event) {}, ETypeClick)
+	win.Add(b)
+	exp := NewExpander()
+	exp.SetHeader(NewLabel("Details"))
+	exp.SetContent(NewLabel("expander content"))
+	exp.SetExpanded(false)
+	win.Add(exp)
+	tp := NewTabPanel()
+	tp.AddString("First", NewLabel("first content"))
+	tp.AddString("Second", NewLabel("second content"))
+	win.Add(tp)
+	win.Add(NewTimer(time.Second))
+	win.AddEHandlerFunc(func(e Event) {}, ETypeWinLoad)
+
+	buf := &bytes.Buffer{}
+	win.RenderReport(NewWriter(buf))
+	out := buf.String()
+	for _, s := range []string{"<script", "se(", "onclick"} {
+		if strings.Contains(out, s) {
+			t.Errorf("Report contains %q: %s", s, out)
+		}
+	}
+	for _, s := range []string{"<style>", "expander content", "first content", "Second", "second content"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Report does not contain %q: %s", s, out)
+		}
+	}
+}
+
+// TestRenderEmailHTML tests RenderEmailHTML().
+func TestRenderEmailHTML(t *testing.T) {
+	p := NewPanel()
+	l := NewLabel("Failed: 3")
+	l.Style().AddClass("gwu-Toast-Error").SetColor("white")
+	p.Add(l)
+	p.Add(NewImage("Chart", "charts/today.png"))
+	p.Add(NewLink("Details", "https://example.org/details"))
+
+	out := RenderEmailHTML(p, "https://example.com/app/")
+	for _, s := range []string{
+		`style="background:#c00;color:white;"`,
+		`src="https://example.com/app/charts/today.png"`,
+		`href="https://example.org/details"`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Email HTML does not contain %q: %s", s, out)
+		}
+	}
+}
@@ -22,12 +22,18 @@ import (
 	"html"
 	"io"
 	"log"
+	"sort"
 	"strconv"
 )
 
 // Number of cached ints.
 const cachedInts = 64
 
+// Size of the scratch buffer of writers, used to format ints and to write
+// strings to writers not implementing WriteString() without allocations.
+// It must be large enough for any int64 value, including the sign.
+const scratchSize = 256
+
 // Byte slice vars (constants) of frequently used strings.
 // Render methods use these to avoid array allocations
 // when converting strings to byte slices in order to write them.
@@ -43,16 +49,20 @@ var (
 	strParenCl  = []byte(")")  // ")" (closing parenthesis)
 	strJsFuncCl = []byte(");") // ");" (closing parenthesis and a semicolon)
 
-	strSpanOp   = []byte("<span")     // "<span"
-	strSpanCl   = []byte("</span>")   // "</span>"
-	strTableOp  = []byte("<table")    // "<table"
-	strTableCl  = []byte("</table>")  // "</table>"
-	strTD       = []byte("<td>")      // "<td>"
-	strTR       = []byte("<tr>")      // "<tr>"
-	strTDOp     = []byte("<td")       // "<td"
-	strTROp     = []byte("<tr")       // "<tr"
-	strScriptOp = []byte("<script>")  // "<script>"
-	strScriptCl = []byte("</script>") // "</script>"
+	strSpanOp     = []byte("<span")       // "<span"
+	strSpanCl     = []byte("</span>")     // "</span>"
+	strTableOp    = []byte("<table")      // "<table"
+	strTableCl    = []byte("</table>")    // "</table>"
+	strTD         = []byte("<td>")        // "<td>"
+	strTR         = []byte("<tr>")        // "<tr>"
+	strTDOp       = []byte("<td")         // "<td"
+	strTROp       = []byte("<tr")         // "<tr"
+	strColGroupOp = []byte("<colgroup>")  // "<colgroup>"
+	strColGroupCl = []byte("</colgroup>") // "</colgroup>"
+	strCol        = []byte("<col>")       // "<col>"
+	strColOp      = []byte("<col")        // "<col"
+	strScriptOp   = []byte("<script>")    // "<script>"
+	strScriptCl   = []byte("</script>")   // "</script>"
 
 	strStyle = []byte(` style="`) // ` style="`
 	strClass = []byte(` class="`) // ` class="`
@@ -105,11 +115,23 @@ type stringWriter interface {
 type writerImpl struct {
 	io.Writer              // Writer implementation
 	sw        stringWriter // stringWriter if the writer implements it
+
+	// Stable component ID mapping, nil if component IDs are written as-is.
+	// IDs are mapped to 1, 2, 3... in the order they are first written.
+	ids map[ID]ID
+
+	paths bool // Tells if component paths are to be rendered (see Server.SetRenderPaths())
+
+	sess Session // Session the output is rendered for, nil if unknown
+
+	report bool // Tells if an interaction-free report is rendered (see Window.RenderReport())
+
+	scratch *[scratchSize]byte // Scratch buffer to format ints and copy strings to without allocations
 }
 
 // NewWriter returns a new Writer, wrapping the specified io.Writer.
 func NewWriter(w io.Writer) Writer {
-	wi := writerImpl{Writer: w}
+	wi := writerImpl{Writer: w, scratch: new([scratchSize]byte)}
 	// Check if writer has WriteString once:
 	if sw, ok := w.(stringWriter); ok {
 		wi.sw = sw
@@ -122,12 +144,11 @@ func (w writerImpl) Writev(v interface{}) (n int, err error) {
 	case string:
 		return w.Writes(v2)
 	case int:
-		if v2 < cachedInts && v2 >= 0 {
-			return w.Write(strInts[v2])
-		}
-		return w.Writes(strconv.Itoa(v2))
+		return w.writeInt(v2)
 	case []byte:
 		return w.Write(v2)
+	case ID:
+		return w.writeInt(int(w.mapID(v2)))
 	case fmt.Stringer:
 		return w.Writes(v2.String())
 	case bool:
@@ -138,6 +159,102 @@ func (w writerImpl) Writev(v interface{}) (n int, err error) {
 	return 0, fmt.Errorf("Not supported type: %T", v)
 }
 
+// writeInt writes an int. Ints which are not cached are formatted
+// into the scratch buffer of the writer, so no allocation is needed.
+func (w writerImpl) writeInt(i int) (n int, err error) {
+	if i < cachedInts && i >= 0 {
+		return w.Write(strInts[i])
+	}
+	if w.scratch == nil {
+		return w.Writes(strconv.Itoa(i))
+	}
+	return w.Write(strconv.AppendInt(w.scratch[:0], int64(i), 10))
+}
+
+// writeID writes a component ID like Writev() does, but without
+// converting it to interface{} (which allocates for large values).
+func writeID(w Writer, id ID) {
+	if wi, ok := w.(writerImpl); ok {
+		wi.writeInt(int(wi.mapID(id)))
+		return
+	}
+	w.Writev(id)
+}
+
+// mapID returns the stable ID of a component ID if the writer has
+// a stable ID mapping, else the ID itself.
+func (w writerImpl) mapID(id ID) ID {
+	if w.ids == nil {
+		return id
+	}
+	if mapped, ok := w.ids[id]; ok {
+		return mapped
+	}
+	mapped := ID(len(w.ids) + 1)
+	w.ids[id] = mapped
+	return mapped
+}
+
+// writerSession returns the session the output of the writer is rendered for,
+// nil if unknown (e.g. the writer was not created by the server).
+func writerSession(w Writer) Session {
+	if wi, ok := w.(writerImpl); ok {
+		return wi.sess
+	}
+	return nil
+}
+
+// reportMode tells if the writer renders an interaction-free report
+// (see Window.RenderReport()).
+func reportMode(w Writer) bool {
+	wi, ok := w.(writerImpl)
+	return ok && wi.report
+}
+
+// writeIDAttr writes the id attribute of a component,
+// applying the stable ID mapping of the writer if it has one.
+func writeIDAttr(w Writer, value string) {
+	if wi, ok := w.(writerImpl); ok && wi.ids != nil {
+		if id, err := AtoID(value); err == nil {
+			value = wi.mapID(id).String()
+		}
+	}
+	w.WriteAttr("id", value)
+}
+
+// stableOutput tells if the writer renders deterministic output
+// (with stable ID mapping or in report mode).
+func stableOutput(w Writer) bool {
+	wi, ok := w.(writerImpl)
+	return ok && (wi.ids != nil || wi.report)
+}
+
+// rangeAttrs calls f with the entries of the attributes map m.
+// Entries are sorted by name only if the writer renders stable output,
+// so regular rendering does not allocate and sort the names.
+func rangeAttrs(w Writer, m map[string]string, f func(name, value string)) {
+	if !stableOutput(w) {
+		for name, value := range m {
+			f(name, value)
+		}
+		return
+	}
+	for _, name := range sortedKeys(m) {
+		f(name, m[name])
+	}
+}
+
+// sortedKeys returns the keys of a map in sorted order,
+// used to render attributes in a deterministic order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func (w writerImpl) Writevs(v ...interface{}) (n int, err error) {
 	for _, v2 := range v {
 		var m int
@@ -154,7 +271,22 @@ func (w writerImpl) Writes(s string) (n int, err error) {
 	if w.sw != nil {
 		return w.sw.WriteString(s)
 	}
-	return w.Write([]byte(s))
+	if w.scratch == nil {
+		return w.Write([]byte(s))
+	}
+
+	// Copy the string to the scratch buffer in chunks, so no allocation is needed
+	for len(s) > 0 {
+		c := copy(w.scratch[:], s)
+		var m int
+		m, err = w.Write(w.scratch[:c])
+		n += m
+		if err != nil {
+			return
+		}
+		s = s[c:]
+	}
+	return
 }
 
 func (w writerImpl) Writess(ss ...string) (n int, err error) {
@@ -186,7 +318,7 @@ func (w writerImpl) WriteAttr(name, value string) (n int, err error) {
 	}
 
 	var m int
-	m, err = w.Write([]byte(name))
+	m, err = w.Writes(name)
 	n += m
 	if err != nil {
 		return
@@ -198,7 +330,7 @@ func (w writerImpl) WriteAttr(name, value string) (n int, err error) {
 		return
 	}
 
-	m, err = w.Write([]byte(value))
+	m, err = w.Writes(value)
 	n += m
 	if err != nil {
 		return
@@ -0,0 +1,94 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestWriterInts(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	w.Writevs(0, ",", 63, ",", 64, ",", -1, ",", 1234567890, ",", ID(987654))
+	if got, want := buf.String(), "0,63,64,-1,1234567890,987654"; got != want {
+		t.Errorf("Got: %q, want: %q", got, want)
+	}
+
+	buf.Grow(1 << 16)
+	id := ID(123456789)
+	if allocs := testing.AllocsPerRun(100, func() { writeID(w, id) }); allocs != 0 {
+		t.Errorf("Got %v allocations writing an ID, want: 0", allocs)
+	}
+}
+
+// plainWriter is an io.Writer which does not implement WriteString().
+type plainWriter struct {
+	buf bytes.Buffer
+}
+
+func (pw *plainWriter) Write(p []byte) (int, error) {
+	return pw.buf.Write(p)
+}
+
+func TestWriterStrings(t *testing.T) {
+	pw := &plainWriter{}
+	pw.buf.Grow(1 << 16)
+	w := NewWriter(pw)
+	long := strings.Repeat("0123456789", 100) // Longer than the scratch buffer
+
+	if allocs := testing.AllocsPerRun(10, func() {
+		w.Writes(long)
+		w.WriteAttr("name", "value")
+	}); allocs != 0 {
+		t.Errorf("Got %v allocations writing strings, want: 0", allocs)
+	}
+
+	pw.buf.Reset()
+	w.Writes(long)
+	w.WriteAttr("name", "value")
+	if got, want := pw.buf.String(), long+` name="value"`; got != want {
+		t.Errorf("Got: %q, want: %q", got, want)
+	}
+}
+
+func BenchmarkWriterStrings(b *testing.B) {
+	w := NewWriter(&plainWriter{})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.WriteAttr("class", "gwu-Button")
+		w.Writes("<span>")
+	}
+}
+
+func BenchmarkWriterInts(b *testing.B) {
+	w := NewWriter(ioutil.Discard)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.Writev(i & 63)
+		writeID(w, ID(i+1000))
+	}
+}
+
+func BenchmarkWriterEscape(b *testing.B) {
+	w := NewWriter(ioutil.Discard)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.Writees("Tom & Jerry <friends>")
+	}
+}